@@ -0,0 +1,43 @@
+package bench
+
+import (
+	"testing"
+)
+
+func BenchmarkCorpora(b *testing.B) {
+	list, err := corpora()
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, c := range list {
+		c := c
+		b.Run(c.name, func(b *testing.B) {
+			if !c.fetched() {
+				b.Skipf("corpus %s is not fetched into %s, run fetch.sh in the bench directory", c.name, c.dir)
+			}
+			pkgs, err := c.load()
+			if err != nil {
+				b.Fatal(err)
+			}
+			r := newRunner()
+			module := modulePackages(pkgs)
+			// the first run exports the facts, so every package is measured with the facts of its dependencies
+			for _, pkg := range module {
+				if err := r.run(pkg); err != nil {
+					b.Fatal(err)
+				}
+			}
+			for _, pkg := range module {
+				pkg := pkg
+				b.Run(pkg.PkgPath, func(b *testing.B) {
+					b.ReportAllocs()
+					for i := 0; i < b.N; i++ {
+						if err := r.run(pkg); err != nil {
+							b.Fatal(err)
+						}
+					}
+				})
+			}
+		})
+	}
+}
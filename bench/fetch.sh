@@ -0,0 +1,19 @@
+#!/bin/sh
+# Copies the modules of corpora.txt into testdata/corpora and vendors their dependencies,
+# so the benchmarks load them offline. Run it from the bench directory.
+set -e
+
+mkdir -p testdata/corpora
+grep -v '^#' corpora.txt | while read -r name module; do
+	[ -n "$name" ] || continue
+	dir=testdata/corpora/$name
+	if [ -d "$dir" ]; then
+		continue
+	fi
+	src=$(go mod download -json "$module" | sed -n 's/^[[:space:]]*"Dir": "\(.*\)",$/\1/p')
+	rm -rf "$dir.tmp"
+	cp -R "$src" "$dir.tmp"
+	chmod -R u+w "$dir.tmp"
+	(cd "$dir.tmp" && go mod vendor)
+	mv "$dir.tmp" "$dir"
+done
@@ -0,0 +1,171 @@
+// Package bench measures the analyzer on real-world code: the corpora are copies of open-source modules
+// with their dependencies vendored, see fetch.sh, and the module of the analyzer itself.
+// The benchmarks report the wall time and the allocations of the analyzer per package:
+//
+//	go test ./bench -run - -bench . -benchmem
+package bench
+
+import (
+	"fmt"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/iimos/go-check-err-chains/errchain"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/packages"
+)
+
+// A corpus is a module the analyzer is measured on.
+type corpus struct {
+	name string
+	dir  string
+}
+
+// corpora returns the module of the analyzer and the modules listed in corpora.txt, which fetch.sh copies
+// into testdata/corpora. A listed module may be missing if fetch.sh hasn't been run, see fetched.
+func corpora() ([]corpus, error) {
+	data, err := os.ReadFile("corpora.txt")
+	if err != nil {
+		return nil, fmt.Errorf("corpora: %w", err)
+	}
+	list := []corpus{{name: "self", dir: ".."}}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("corpora: corpora.txt: want a name and a module version per line, got %q", line)
+		}
+		list = append(list, corpus{name: fields[0], dir: filepath.Join("testdata", "corpora", fields[0])})
+	}
+	return list, nil
+}
+
+// fetched tells whether the module of a corpus is in place. fetch.sh moves a module into its directory
+// once it is copied and vendored, so an existing directory is complete.
+func (c corpus) fetched() bool {
+	info, err := os.Stat(c.dir)
+	return err == nil && info.IsDir()
+}
+
+// load loads the packages of a corpus with their syntax and types. The dependencies of a corpus are vendored,
+// so the packages are loaded offline.
+func (c corpus) load() ([]*packages.Package, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedTypes |
+			packages.NeedTypesSizes | packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedModule,
+		Dir: c.dir,
+	}
+	if _, err := os.Stat(filepath.Join(c.dir, "vendor")); err == nil {
+		cfg.Env = append(os.Environ(), "GOFLAGS=-mod=vendor", "GOPROXY=off")
+	}
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, fmt.Errorf("load %s: %w", c.name, err)
+	}
+	if packages.PrintErrors(pkgs) > 0 {
+		return nil, fmt.Errorf("load %s: packages contain errors", c.name)
+	}
+	return pkgs, nil
+}
+
+// A runner runs the analyzer on the packages of a corpus in process, keeping the facts the way
+// the checker does, so the packages see the facts of their dependencies.
+type runner struct {
+	objectFacts  map[factKey]analysis.Fact
+	packageFacts map[factKey]analysis.Fact
+}
+
+// A factKey identifies a fact of an object or a package by its type.
+type factKey struct {
+	owner interface{} // types.Object or *types.Package
+	typ   reflect.Type
+}
+
+func newRunner() *runner {
+	return &runner{objectFacts: make(map[factKey]analysis.Fact), packageFacts: make(map[factKey]analysis.Fact)}
+}
+
+// modulePackages returns the packages of the main module of a corpus, the dependencies first.
+func modulePackages(pkgs []*packages.Package) []*packages.Package {
+	var list []*packages.Package
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if pkg.Module != nil && pkg.Module.Main && pkg.Types != nil {
+			list = append(list, pkg)
+		}
+	})
+	return list
+}
+
+// run runs the analyzer with its requirements on a package.
+func (r *runner) run(pkg *packages.Package) error {
+	results := make(map[*analysis.Analyzer]interface{})
+	for _, a := range []*analysis.Analyzer{inspect.Analyzer, errchain.Analyzer} {
+		result, err := a.Run(r.pass(a, pkg, results))
+		if err != nil {
+			return fmt.Errorf("run %s on %s: %w", a.Name, pkg.PkgPath, err)
+		}
+		results[a] = result
+	}
+	return nil
+}
+
+// pass returns a pass of an analyzer on a package with the results of the analyzers it requires.
+// The diagnostics are dropped: the benchmarks measure building them, not printing.
+func (r *runner) pass(a *analysis.Analyzer, pkg *packages.Package, results map[*analysis.Analyzer]interface{}) *analysis.Pass {
+	return &analysis.Pass{
+		Analyzer:     a,
+		Fset:         pkg.Fset,
+		Files:        pkg.Syntax,
+		OtherFiles:   pkg.OtherFiles,
+		IgnoredFiles: pkg.IgnoredFiles,
+		Pkg:          pkg.Types,
+		TypesInfo:    pkg.TypesInfo,
+		TypesSizes:   pkg.TypesSizes,
+		ResultOf:     results,
+		Report:       func(analysis.Diagnostic) {},
+		ImportObjectFact: func(obj types.Object, fact analysis.Fact) bool {
+			return importFact(r.objectFacts, obj, fact)
+		},
+		ImportPackageFact: func(pkg *types.Package, fact analysis.Fact) bool {
+			return importFact(r.packageFacts, pkg, fact)
+		},
+		ExportObjectFact: func(obj types.Object, fact analysis.Fact) {
+			r.objectFacts[factKey{owner: obj, typ: reflect.TypeOf(fact)}] = fact
+		},
+		ExportPackageFact: func(fact analysis.Fact) {
+			r.packageFacts[factKey{owner: pkg.Types, typ: reflect.TypeOf(fact)}] = fact
+		},
+		AllPackageFacts: func() []analysis.PackageFact {
+			facts := make([]analysis.PackageFact, 0, len(r.packageFacts))
+			for key, fact := range r.packageFacts {
+				facts = append(facts, analysis.PackageFact{Package: key.owner.(*types.Package), Fact: fact})
+			}
+			sort.Slice(facts, func(i, j int) bool { return facts[i].Package.Path() < facts[j].Package.Path() })
+			return facts
+		},
+		AllObjectFacts: func() []analysis.ObjectFact {
+			facts := make([]analysis.ObjectFact, 0, len(r.objectFacts))
+			for key, fact := range r.objectFacts {
+				facts = append(facts, analysis.ObjectFact{Object: key.owner.(types.Object), Fact: fact})
+			}
+			return facts
+		},
+	}
+}
+
+// importFact copies a fact of an owner into the fact given by a pointer.
+func importFact(facts map[factKey]analysis.Fact, owner interface{}, fact analysis.Fact) bool {
+	stored, ok := facts[factKey{owner: owner, typ: reflect.TypeOf(fact)}]
+	if !ok {
+		return false
+	}
+	reflect.ValueOf(fact).Elem().Set(reflect.ValueOf(stored).Elem())
+	return true
+}
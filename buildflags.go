@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// buildFlags are the go command flags passed through to the go command loading the packages,
+// so that the checker analyzes the same package variants as go build does, e.g. files guarded by
+// //go:build integration with -tags integration.
+var buildFlags = []string{"tags", "gcflags", "asmflags", "ldflags", "mod", "modfile"}
+
+func init() {
+	// The flags are handled by extractBuildFlags before the checker starts; they are registered only to appear in the usage.
+	// The checker registers a no-op -tags itself for compatibility with go vet.
+	for _, name := range buildFlags[1:] {
+		flag.String(name, "", "go build flag passed to the go command loading the packages, see 'go help build'")
+	}
+}
+
+// extractBuildFlags removes the build flags from the command line arguments and returns them in the GOFLAGS form.
+// GOFLAGS is split by spaces, so a value containing spaces can't be passed through.
+func extractBuildFlags(args []string) (goflags, rest []string, err error) {
+	rest = args
+	for _, name := range buildFlags {
+		var value string
+		var ok bool
+		if value, rest, ok = extractFlag(rest, name); !ok {
+			continue
+		}
+		if name == "tags" {
+			// accept the legacy space-separated list as well
+			value = strings.Join(strings.Fields(strings.ReplaceAll(value, ",", " ")), ",")
+		}
+		if strings.ContainsAny(value, " \t\n") {
+			return nil, nil, fmt.Errorf("-%s=%q: values with spaces can't be passed to the go command", name, value)
+		}
+		goflags = append(goflags, "-"+name+"="+value)
+	}
+	return goflags, rest, nil
+}
+
+// appendGoflags adds flags to the GOFLAGS variable of the process, which is inherited by the go command
+// run by the package loader and by child processes of the driver. The flags follow the existing ones to override them.
+func appendGoflags(flags []string) error {
+	if len(flags) == 0 {
+		return nil
+	}
+	goflags := append(strings.Fields(os.Getenv("GOFLAGS")), flags...)
+	return os.Setenv("GOFLAGS", strings.Join(goflags, " "))
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestExtractBuildFlags(t *testing.T) {
+	for _, tt := range []struct {
+		args    []string
+		goflags []string
+		rest    []string
+	}{
+		{[]string{"./..."}, nil, []string{"./..."}},
+		{[]string{"-tags=a,b", "./..."}, []string{"-tags=a,b"}, []string{"./..."}},
+		{[]string{"-tags", "a", "./..."}, []string{"-tags=a"}, []string{"./..."}},
+		// the legacy space-separated list
+		{[]string{"-tags", "a b", "./..."}, []string{"-tags=a,b"}, []string{"./..."}},
+		{[]string{"--mod=vendor", "-json", "-ldflags=-s", "./..."}, []string{"-ldflags=-s", "-mod=vendor"}, []string{"-json", "./..."}},
+		{[]string{"-json", "--", "-tags=a"}, nil, []string{"-json", "--", "-tags=a"}},
+	} {
+		goflags, rest, err := extractBuildFlags(tt.args)
+		if err != nil {
+			t.Errorf("extractBuildFlags(%q): %v", tt.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(goflags, tt.goflags) || !reflect.DeepEqual(rest, tt.rest) {
+			t.Errorf("extractBuildFlags(%q) = %q, %q, want %q, %q", tt.args, goflags, rest, tt.goflags, tt.rest)
+		}
+	}
+}
+
+func TestExtractBuildFlagsWithSpaces(t *testing.T) {
+	args := []string{"-gcflags", "-N -l", "./..."}
+	if goflags, _, err := extractBuildFlags(args); err == nil {
+		t.Errorf("extractBuildFlags(%q) = %q, want an error", args, goflags)
+	}
+}
+
+func TestAppendGoflags(t *testing.T) {
+	for _, tt := range []struct {
+		goflags string
+		flags   []string
+		want    string
+	}{
+		{"", nil, ""},
+		{"-mod=mod", nil, "-mod=mod"},
+		{"", []string{"-tags=a"}, "-tags=a"},
+		// the flags follow the existing ones to override them
+		{"-mod=mod  -tags=b", []string{"-tags=a,b", "-mod=vendor"}, "-mod=mod -tags=b -tags=a,b -mod=vendor"},
+	} {
+		t.Setenv("GOFLAGS", tt.goflags)
+		if err := appendGoflags(tt.flags); err != nil {
+			t.Fatal(err)
+		}
+		if got := os.Getenv("GOFLAGS"); got != tt.want {
+			t.Errorf("appendGoflags(%q) with GOFLAGS=%q: GOFLAGS = %q, want %q", tt.flags, tt.goflags, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+// Command errlint bundles errchain with complementary error analyzers into a single binary:
+//
+//   - errchain checks that error messages point to the place where they had happened;
+//   - wrapcheck checks that errors returned from other packages are wrapped;
+//   - err113 checks that errors are compared with errors.Is;
+//   - wraptail checks that the wrapped error is at the end of the message;
+//   - multiwrap checks that several errors are wrapped only if the module requires Go 1.20 or later.
+//
+// Flags of an analyzer are prefixed with its name, e.g. -errchain.pkg-component=optional.
+// The ERRCHAIN_* environment variables configure errchain the same way they do for the errchain command,
+// so both binaries share the configuration.
+package main
+
+import (
+	"os"
+
+	"github.com/iimos/go-check-err-chains/errchain"
+	"github.com/iimos/go-check-err-chains/internal/envflags"
+	"github.com/iimos/go-check-err-chains/passes/err113"
+	"github.com/iimos/go-check-err-chains/passes/multiwrap"
+	"github.com/iimos/go-check-err-chains/passes/wrapcheck"
+	"github.com/iimos/go-check-err-chains/passes/wraptail"
+	"golang.org/x/tools/go/analysis/multichecker"
+)
+
+func main() {
+	os.Args = append(append(os.Args[:1:1], envflags.Args(os.Environ(), errchainFlag)...), os.Args[1:]...)
+	multichecker.Main(errchain.Analyzer, wrapcheck.Analyzer, err113.Analyzer, wraptail.Analyzer,
+		multiwrap.Analyzer)
+}
+
+// errchainFlag returns the name of the errchain flag set by an ERRCHAIN_* variable, e.g. -errchain.pkg-component
+// for ERRCHAIN_PKG_COMPONENT, or false if errchain has no such flag.
+func errchainFlag(name string) (string, bool) {
+	return errchain.Analyzer.Name + "." + name, errchain.Analyzer.Flags.Lookup(name) != nil
+}
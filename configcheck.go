@@ -0,0 +1,190 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/types"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/iimos/go-check-err-chains/errchain"
+	"golang.org/x/tools/go/packages"
+)
+
+// runConfigCheck validates the configuration without checking any code: the flags, including the ones set
+// by ERRCHAIN_ variables, and the sections of an overrides file. Unknown flags and bad values fail here
+// rather than a later run, and the error constructors set by -constructors are looked up in the module
+// and its dependencies to check their signatures against the descriptions.
+func runConfigCheck(args []string) int {
+	flags := analyzerFlagSet("configcheck")
+	flags.SetOutput(os.Stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: errchain configcheck [flags] [overrides-file]\n\n")
+		fmt.Fprintf(os.Stderr, "Validates the flags and an overrides file, see -overrides.\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	if exitcode, ok := parseFlags(flags, append(envArgs(os.Environ(), isAnalyzerFlag), args...)); !ok {
+		return exitcode
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return exitConfig
+	}
+
+	problems, err := checkConstructors()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "errchain configcheck: %v\n", err)
+		return exitError
+	}
+	if filename := flags.Arg(0); filename != "" {
+		sections, err := readOverrides(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "errchain configcheck: %v\n", err)
+			return exitConfig
+		}
+		for _, s := range sections {
+			where := fmt.Sprintf("%s:%d: ", filename, s.line)
+			// the flags of a section apply on top of the command line ones, as in runSections
+			sectionFlags := analyzerFlagSet(s.pattern)
+			addCheckerFlags(sectionFlags)
+			if err := sectionFlags.Parse(s.flags); err != nil {
+				problems = append(problems, where+err.Error())
+				continue
+			}
+			if !isFlagSet(sectionFlags, "constructors") {
+				continue
+			}
+			list, err := checkConstructors()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "errchain configcheck: %v\n", err)
+				return exitError
+			}
+			for _, problem := range list {
+				problems = append(problems, where+problem)
+			}
+		}
+	}
+
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "errchain configcheck: %s\n", problem)
+	}
+	if len(problems) > 0 {
+		return exitConfig
+	}
+	return exitOK
+}
+
+// analyzerFlagSet returns a silent flag set of the analyzer flags.
+func analyzerFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	errchain.Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		flags.Var(f.Value, f.Name, f.Usage)
+	})
+	return flags
+}
+
+// isFlagSet tells whether a flag is set in a parsed flag set.
+func isFlagSet(flags *flag.FlagSet, name string) (set bool) {
+	flags.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
+}
+
+// checkConstructors looks up the error constructors set by -constructors in the packages of the module and its
+// dependencies and checks their signatures, see errchain.CheckConstructor. The names must be full, e.g.
+// "github.com/pkg/errors.Wrapf": the suffix "errors.Wrapf" matches calls as well, but can't be looked up.
+func checkConstructors() (problems []string, err error) {
+	names := errchain.Constructors()
+	if len(names) == 0 {
+		return nil, nil
+	}
+	var paths []string
+	for _, name := range names {
+		for _, c := range constructorCandidates(name) {
+			paths = append(paths, c[0])
+		}
+	}
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedName | packages.NeedTypes}, dedup(paths)...)
+	if err != nil {
+		return nil, fmt.Errorf("checkConstructors: %w", err)
+	}
+	byPath := make(map[string]*types.Package)
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) == 0 && pkg.Types != nil {
+			byPath[pkg.PkgPath] = pkg.Types
+		}
+	}
+
+	for _, name := range names {
+		var fn *types.Func
+		found := false
+		for _, c := range constructorCandidates(name) {
+			if pkg, ok := byPath[c[0]]; ok {
+				found = true
+				if fn = lookupFunc(pkg, c[1]); fn != nil {
+					break
+				}
+			}
+		}
+		switch {
+		case !found:
+			problems = append(problems, fmt.Sprintf("constructor %s: no such package in the module and its dependencies", name))
+		case fn == nil:
+			problems = append(problems, fmt.Sprintf("constructor %s: no such function or method", name))
+		default:
+			if err := errchain.CheckConstructor(fn); err != nil {
+				problems = append(problems, err.Error())
+			}
+		}
+	}
+	return problems, nil
+}
+
+// constructorCandidates splits a constructor name into import paths and members they may declare: the dots
+// of the last path element separate either the package from a function or a type and a method,
+// e.g. gopkg.in/errs.v1.Wrap is Wrap of gopkg.in/errs.v1 or the method v1.Wrap of gopkg.in/errs.
+func constructorCandidates(name string) [][2]string {
+	var candidates [][2]string
+	slash := strings.LastIndex(name, "/")
+	for i := slash + 1; i < len(name); i++ {
+		if name[i] != '.' {
+			continue
+		}
+		if member := name[i+1:]; member != "" && strings.Count(member, ".") <= 1 {
+			candidates = append(candidates, [2]string{name[:i], member})
+		}
+	}
+	return candidates
+}
+
+// lookupFunc returns a function or, for "Type.Method", a method declared by a package or nil if there is none.
+func lookupFunc(pkg *types.Package, member string) *types.Func {
+	typeName, method, isMethod := strings.Cut(member, ".")
+	if !isMethod {
+		fn, _ := pkg.Scope().Lookup(member).(*types.Func)
+		return fn
+	}
+	tn, ok := pkg.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return nil
+	}
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(tn.Type()), true, pkg, method)
+	fn, _ := obj.(*types.Func)
+	return fn
+}
+
+// dedup returns the sorted distinct strings of a list.
+func dedup(list []string) []string {
+	sort.Strings(list)
+	out := list[:0]
+	for i, s := range list {
+		if i == 0 || s != list[i-1] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
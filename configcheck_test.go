@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigCheck(t *testing.T) {
+	for _, tt := range []struct {
+		args      []string
+		overrides string
+		want      int
+	}{
+		{nil, "", exitOK},
+		{[]string{"-enable=canonical", "-pkg-component=optional"}, "", exitOK},
+		{nil, "# relaxed rules\ninternal/** -pkg-component=optional -disable=canonical\n", exitOK},
+		{[]string{"-enable=no-such-rule"}, "", exitConfig},
+		{[]string{"-no-such-flag"}, "", exitConfig},
+		{nil, "internal/** -disable=no-such-rule\n", exitConfig},
+		{nil, "internal/** -no-such-flag=true\n", exitConfig},
+		{nil, "internal/** pkg-component=optional\n", exitConfig},
+	} {
+		args := append([]string{"configcheck"}, tt.args...)
+		if tt.overrides != "" {
+			filename := filepath.Join(t.TempDir(), "overrides")
+			if err := os.WriteFile(filename, []byte(tt.overrides), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			args = append(args, filename)
+		}
+		if out, exitcode := runMain(t, args...); exitcode != tt.want {
+			t.Errorf("errchain %q with overrides %q: exit code = %d, want %d, output:\n%s",
+				tt.args, tt.overrides, exitcode, tt.want, out)
+		}
+	}
+}
+
+func TestConfigCheckUnreadableFile(t *testing.T) {
+	for _, filename := range []string{filepath.Join(t.TempDir(), "missing"), t.TempDir()} {
+		if out, exitcode := runMain(t, "configcheck", filename); exitcode != exitConfig {
+			t.Errorf("errchain configcheck %s: exit code = %d, want %d, output:\n%s", filename, exitcode, exitConfig, out)
+		}
+	}
+}
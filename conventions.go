@@ -0,0 +1,453 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// runConventions scans the code and reports the error prefix styles in use: how many messages start with
+// the package, the function or the receiver and method, which separators follow the prefixes and how many
+// functions hold their location in an op constant. It suggests the flags matching the code, so a team can
+// choose the configuration before enforcing it. The scan is syntactic and doesn't load the packages.
+func runConventions(args []string) int {
+	flags := flag.NewFlagSet("conventions", flag.ContinueOnError)
+	tests := flags.Bool("tests", false, "scan the test files as well")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: errchain conventions [-tests] [package]...\n\n")
+		fmt.Fprintf(os.Stderr, "Reports the error prefix styles in use and suggests the flags matching them.\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	if exitcode, ok := parseFlags(flags, args); !ok {
+		return exitcode
+	}
+
+	patterns := flags.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	files, err := goFiles(patterns)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "errchain conventions: %v\n", err)
+		return exitError
+	}
+
+	var stats conventionStats
+	for _, filename := range files {
+		if !*tests && strings.HasSuffix(filename, "_test.go") {
+			continue
+		}
+		if err := stats.scanFile(filename); err != nil {
+			fmt.Fprintf(os.Stderr, "errchain conventions: %v\n", err)
+			return exitError
+		}
+	}
+	if err := stats.print(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "errchain conventions: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// Prefix styles of error messages, in the order of the report.
+const (
+	styleNone       = "no prefix"
+	stylePkg        = "pkg"
+	styleFunc       = "pkg.Func"
+	styleRecv       = "pkg.Recv"
+	styleMethod     = "pkg.Recv.Method"
+	stylePtrMethod  = "pkg.(*Recv).Method"
+	styleOtherShape = "other"
+)
+
+var prefixStyles = []string{styleNone, stylePkg, styleFunc, styleRecv, styleMethod, stylePtrMethod, styleOtherShape}
+
+// errorConstructors are the functions creating errors from a message, by import path.
+var errorConstructors = map[string][]string{
+	"errors":                      {"New"},
+	"fmt":                         {"Errorf"},
+	"golang.org/x/xerrors":        {"New", "Errorf"},
+	"github.com/pkg/errors":       {"New", "Errorf"},
+	"github.com/go-faster/errors": {"New", "Errorf"},
+}
+
+type conventionStats struct {
+	messages   int
+	styles     map[string]int
+	separators map[string]int
+	foreignPkg int // prefixed messages starting with another name than the package's
+	methodMsgs int // prefixed messages of methods
+	methodRecv int // prefixed messages of methods naming the receiver
+	funcs      int // functions creating errors
+	opFuncs    int // functions creating errors and declaring const op
+	opMessages int // messages starting with the op constant
+	generated  int // skipped generated files
+}
+
+// scanFile adds the error messages of a file to the stats.
+func (s *conventionStats) scanFile(filename string) error {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.SkipObjectResolution|parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	if isGeneratedFile(file) {
+		s.generated++
+		return nil
+	}
+	ctors := fileConstructors(file)
+	if len(ctors) == 0 {
+		return nil
+	}
+	if s.styles == nil {
+		s.styles = make(map[string]int)
+		s.separators = make(map[string]int)
+	}
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+		recv := ""
+		if fn.Recv != nil && len(fn.Recv.List) == 1 {
+			recv = recvTypeName(fn.Recv.List[0].Type)
+		}
+		op, hasOp := declaredOp(fn.Body)
+
+		found := false
+		ast.Inspect(fn.Body, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 || !ctors[calleeName(call.Fun)] {
+				return true
+			}
+			found = true
+			text, usesOp := messageHead(call, op, hasOp)
+			s.messages++
+			if usesOp {
+				s.opMessages++
+			}
+			s.addMessage(text, file.Name.Name, recv)
+			return true
+		})
+		if found {
+			s.funcs++
+			if hasOp {
+				s.opFuncs++
+			}
+		}
+	}
+	return nil
+}
+
+// addMessage classifies the leading text of a message of a function, or of a method of the receiver type recv.
+func (s *conventionStats) addMessage(text, pkg, recv string) {
+	loc, sep := splitPrefix(text)
+	if loc == "" || sep == "" {
+		s.styles[styleNone]++
+		return
+	}
+	parts := strings.Split(loc, ".")
+	style := styleOtherShape
+	switch {
+	case len(parts) == 1 && parts[0] == pkg:
+		style = stylePkg
+	case len(parts) == 1:
+		// a word followed by a separator, e.g. "failed: ", is a part of the message rather than a prefix
+		s.styles[styleNone]++
+		return
+	case len(parts) == 2 && recv != "" && parts[1] == recv:
+		style = styleRecv
+	case len(parts) == 2 && token.IsIdentifier(parts[1]):
+		style = styleFunc
+	case len(parts) == 3 && token.IsIdentifier(parts[1]):
+		style = styleMethod
+	case len(parts) == 3 && strings.HasPrefix(parts[1], "(*") && strings.HasSuffix(parts[1], ")"):
+		style = stylePtrMethod
+	}
+	s.styles[style]++
+	s.separators[sep]++
+	if parts[0] != pkg {
+		s.foreignPkg++
+	}
+	if recv != "" {
+		s.methodMsgs++
+		if style == styleRecv || style == styleMethod || style == stylePtrMethod {
+			s.methodRecv++
+		}
+	}
+}
+
+// print writes the report and the suggested flags.
+func (s *conventionStats) print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "STYLE\tMESSAGES\tSHARE\n")
+	for _, style := range prefixStyles {
+		fmt.Fprintf(tw, "%s\t%d\t%d%%\n", style, s.styles[style], percent(s.styles[style], s.messages))
+	}
+	fmt.Fprintf(tw, "total\t%d\t\n", s.messages)
+	fmt.Fprintln(tw)
+
+	prefixed := s.messages - s.styles[styleNone]
+	fmt.Fprintf(tw, "SEPARATOR\tMESSAGES\tSHARE\n")
+	for _, sep := range sortedByCount(s.separators) {
+		fmt.Fprintf(tw, "%q\t%d\t%d%%\n", sep, s.separators[sep], percent(s.separators[sep], prefixed))
+	}
+	fmt.Fprintln(tw)
+
+	fmt.Fprintf(tw, "functions declaring const op\t%d of %d\t%d%%\n", s.opFuncs, s.funcs, percent(s.opFuncs, s.funcs))
+	fmt.Fprintf(tw, "messages starting with op\t%d of %d\t%d%%\n", s.opMessages, s.messages, percent(s.opMessages, s.messages))
+	fmt.Fprintf(tw, "prefixes not starting with the package name\t%d of %d\t%d%%\n", s.foreignPkg, prefixed, percent(s.foreignPkg, prefixed))
+	if s.generated > 0 {
+		fmt.Fprintf(tw, "generated files skipped\t%d\t\n", s.generated)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if suggested := s.suggestFlags(); len(suggested) > 0 {
+		fmt.Fprintf(w, "\nsuggested flags: %s\n", strings.Join(suggested, " "))
+	}
+	return nil
+}
+
+// suggestFlags returns the flags matching the styles used by most of the prefixed messages, leaving out
+// the ones equal to the defaults.
+func (s *conventionStats) suggestFlags() []string {
+	prefixed := s.messages - s.styles[styleNone]
+	if prefixed == 0 {
+		return nil
+	}
+	var suggested []string
+	withFunc := s.styles[styleFunc] + s.styles[styleMethod] + s.styles[stylePtrMethod]
+	if percent(withFunc, prefixed) >= 80 {
+		suggested = append(suggested, "-func-component=required")
+	}
+	if s.methodMsgs > 0 && percent(s.methodRecv, s.methodMsgs) >= 80 {
+		suggested = append(suggested, "-recv-component=required")
+	}
+	if percent(s.foreignPkg, prefixed) > 20 {
+		suggested = append(suggested, "-pkg-component=optional")
+	}
+	if seps := sortedByCount(s.separators); len(seps) > 0 && seps[0] != ": " {
+		suggested = append(suggested, "-separator="+strconv.Quote(seps[0]))
+	}
+	return suggested
+}
+
+// fileConstructors returns the names of the error constructors as they are called in a file, e.g. "errors.New",
+// following the names the packages are imported with.
+func fileConstructors(file *ast.File) map[string]bool {
+	ctors := make(map[string]bool)
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		funcs, ok := errorConstructors[path]
+		if !ok {
+			continue
+		}
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		for _, fn := range funcs {
+			ctors[name+"."+fn] = true
+		}
+	}
+	return ctors
+}
+
+// calleeName returns the name of a called package member, e.g. "errors.New", or "" for other calls.
+func calleeName(fun ast.Expr) string {
+	sel, ok := fun.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+	return x.Name + "." + sel.Sel.Name
+}
+
+// messageHead returns the leading text of the message of a constructor call, resolving the op constant:
+// errors.New(op + ": msg") and fmt.Errorf("%s: msg", op) both start with the value of op followed by ": msg".
+// The text is empty if the message doesn't start with a literal or op.
+func messageHead(call *ast.CallExpr, op string, hasOp bool) (text string, usesOp bool) {
+	msg := call.Args[0]
+	var rest ast.Expr
+	for {
+		bin, ok := msg.(*ast.BinaryExpr)
+		if !ok || bin.Op != token.ADD {
+			break
+		}
+		msg, rest = bin.X, bin.Y
+	}
+	if isOpIdent(msg) {
+		return op + stringLit(rest), hasOp
+	}
+	text = stringLit(msg)
+	for _, verb := range []string{"%s", "%v"} {
+		if len(call.Args) > 1 && strings.HasPrefix(text, verb) && isOpIdent(call.Args[1]) {
+			return op + text[len(verb):], hasOp
+		}
+	}
+	return text, false
+}
+
+// isOpIdent tells whether an expression is the op identifier.
+func isOpIdent(expr ast.Expr) bool {
+	id, ok := expr.(*ast.Ident)
+	return ok && id.Name == "op"
+}
+
+// stringLit returns the value of a string literal or "" for other expressions.
+func stringLit(expr ast.Expr) string {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return ""
+	}
+	s, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// declaredOp returns the value of a "const op" declared in a function body.
+func declaredOp(body *ast.BlockStmt) (string, bool) {
+	for _, stmt := range body.List {
+		decl, ok := stmt.(*ast.DeclStmt)
+		if !ok {
+			continue
+		}
+		genDecl, ok := decl.Decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			vs := spec.(*ast.ValueSpec)
+			for i, name := range vs.Names {
+				if name.Name == "op" && i < len(vs.Values) {
+					return stringLit(vs.Values[i]), true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// recvTypeName returns the name of a receiver type, without the pointer and the type parameters.
+func recvTypeName(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// splitPrefix splits the leading text of a message into a location, e.g. "pkg.(*Type).Method", and the separator
+// following it, e.g. ": " or " - ". The separator has a punctuation character and optional spaces around it,
+// since a space alone doesn't separate a prefix. Both are empty if the text doesn't start with a location.
+func splitPrefix(text string) (loc, sep string) {
+	i := strings.IndexFunc(text, func(r rune) bool {
+		return !(r == '.' || r == '_' || r == '(' || r == ')' || r == '*' ||
+			'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+	})
+	if i <= 0 {
+		return "", ""
+	}
+	loc, rest := text[:i], text[i:]
+	trimmed := strings.TrimLeft(rest, " ")
+	j := strings.IndexFunc(trimmed, func(r rune) bool { return !strings.ContainsRune(":-|>/;~—–", r) })
+	if j < 0 {
+		j = len(trimmed)
+	}
+	if j == 0 {
+		return "", ""
+	}
+	end := len(rest) - len(trimmed) + j
+	if strings.HasPrefix(rest[end:], " ") {
+		end++
+	}
+	if loc = strings.TrimRight(loc, "."); !isPrefixLocation(loc) {
+		return "", ""
+	}
+	return loc, rest[:end]
+}
+
+// isPrefixLocation is like isLocation but accepts the pointer form of a type, e.g. pkg.(*Type).Method.
+func isPrefixLocation(loc string) bool {
+	for _, part := range strings.Split(loc, ".") {
+		if strings.HasPrefix(part, "(*") && strings.HasSuffix(part, ")") {
+			part = part[2 : len(part)-1]
+		}
+		if !token.IsIdentifier(part) {
+			return false
+		}
+	}
+	return true
+}
+
+// isGeneratedFile tells whether a file has the comment marking generated code before the package clause.
+func isGeneratedFile(file *ast.File) bool {
+	for _, group := range file.Comments {
+		if group.Pos() > file.Package {
+			break
+		}
+		for _, comment := range group.List {
+			if strings.HasPrefix(comment.Text, "// Code generated ") && strings.HasSuffix(comment.Text, " DO NOT EDIT.") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sortedByCount returns the keys of counts, the most frequent first.
+func sortedByCount(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// percent returns n as a rounded-down percentage of total, 0 if the total is 0.
+func percent(n, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return n * 100 / total
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitPrefix(t *testing.T) {
+	for _, tt := range []struct {
+		text     string
+		loc, sep string
+	}{
+		{"store.Get: not found", "store.Get", ": "},
+		{"store.(*DB).Get - not found", "store.(*DB).Get", " - "},
+		{"store.Get:not found", "store.Get", ":"},
+		{"store -> closed", "store", " -> "},
+		{"store: ", "store", ": "},
+		{"store.Get", "", ""},
+		{"not found", "", ""},
+		{": not found", "", ""},
+		{"store..Get: x", "", ""},
+		{"store.(DB.Get: x", "", ""},
+	} {
+		loc, sep := splitPrefix(tt.text)
+		if loc != tt.loc || sep != tt.sep {
+			t.Errorf("splitPrefix(%q) = %q, %q, want %q, %q", tt.text, loc, sep, tt.loc, tt.sep)
+		}
+	}
+}
+
+func TestConventions(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "store.go")
+	src := `package store
+
+import (
+	"errors"
+	"fmt"
+)
+
+type DB struct{}
+
+func (db *DB) Get(key string) error {
+	const op = "store.DB.Get"
+	if key == "" {
+		return errors.New(op + " - empty key")
+	}
+	return fmt.Errorf("%s - key %q not found", op, key)
+}
+
+func (db *DB) Put(key string) error {
+	if key == "" {
+		return errors.New("store.(*DB).Put - empty key")
+	}
+	return errors.New("failed: read only")
+}
+
+func Open(dsn string) error {
+	return fmt.Errorf("store.Open - bad dsn %q", dsn)
+}
+
+func Close() error {
+	return errors.New("store - closed")
+}
+
+func helper() string {
+	return "store.helper - not an error"
+}
+`
+	if err := os.WriteFile(filename, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var s conventionStats
+	if err := s.scanFile(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	wantStyles := map[string]int{styleMethod: 2, stylePtrMethod: 1, styleFunc: 1, stylePkg: 1, styleNone: 1}
+	if !reflect.DeepEqual(s.styles, wantStyles) {
+		t.Errorf("styles = %v, want %v", s.styles, wantStyles)
+	}
+	if want := map[string]int{" - ": 5}; !reflect.DeepEqual(s.separators, want) {
+		t.Errorf("separators = %v, want %v", s.separators, want)
+	}
+	if s.messages != 6 || s.funcs != 4 || s.opFuncs != 1 || s.opMessages != 2 {
+		t.Errorf("messages, funcs, op funcs, op messages = %d, %d, %d, %d, want 6, 4, 1, 2",
+			s.messages, s.funcs, s.opFuncs, s.opMessages)
+	}
+	if s.methodMsgs != 3 || s.methodRecv != 3 || s.foreignPkg != 0 {
+		t.Errorf("method messages, naming the receiver, foreign = %d, %d, %d, want 3, 3, 0",
+			s.methodMsgs, s.methodRecv, s.foreignPkg)
+	}
+	want := []string{"-func-component=required", "-recv-component=required", `-separator=" - "`}
+	if got := s.suggestFlags(); !reflect.DeepEqual(got, want) {
+		t.Errorf("suggestFlags() = %q, want %q", got, want)
+	}
+}
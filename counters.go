@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	// The flag is handled by runDriver; it is registered only to appear in the usage.
+	flag.String("counters", "", "add the numbers of diagnostics by rule and of suppressions of the run to the counters "+
+		"kept in a local file, to see across runs which rules report issues before enabling them")
+}
+
+// skippedKind is the category of the diagnostics of the -why-skipped mode, which the counters count as suppressions.
+const skippedKind = "skipped"
+
+// Counter names. Rule hits are counted by the category of the diagnostics, suppressions by the rule turned off
+// by -disable and by the reason of skipping a part of the code, e.g. "suppressed/skip/generated file".
+const (
+	counterRuns       = "runs"
+	counterRulePrefix = "rule/"
+	counterDisabled   = "suppressed/disable/"
+	counterSkipped    = "suppressed/skip/"
+)
+
+// countRun returns the counters of a run: the diagnostics of the set by category and the suppressions,
+// the rules disabled by the command line arguments and the diagnostics of the skipped category.
+func countRun(set *diagnosticSet, args []string) map[string]int64 {
+	counts := map[string]int64{counterRuns: 1}
+	for _, results := range set.diags {
+		for _, diags := range results {
+			for _, d := range diags {
+				switch d.Category {
+				case skippedKind:
+					reason, _, _ := strings.Cut(d.Message, ": ")
+					counts[counterSkipped+reason]++
+				case "":
+					counts[counterRulePrefix+otherKind]++
+				default:
+					counts[counterRulePrefix+d.Category]++
+				}
+			}
+		}
+	}
+	if disable, _, ok := extractFlag(args, "disable"); ok {
+		for _, code := range strings.Split(disable, ",") {
+			if code = strings.TrimSpace(code); code != "" {
+				counts[counterDisabled+code]++
+			}
+		}
+	}
+	return counts
+}
+
+// withoutCategory removes the diagnostics of a category from the set.
+func (set *diagnosticSet) withoutCategory(category string) {
+	for id, results := range set.diags {
+		for name, diags := range results {
+			kept := diags[:0]
+			for _, d := range diags {
+				if d.Category != category {
+					kept = append(kept, d)
+				}
+			}
+			if len(kept) == 0 {
+				delete(results, name)
+			} else {
+				results[name] = kept
+			}
+		}
+		if len(results) == 0 {
+			delete(set.diags, id)
+		}
+	}
+}
+
+// addCounters adds the counters of a run to the ones kept in a file, creating it if it doesn't exist.
+// The file is a list of "name value" lines sorted by name; it is replaced by renaming a temporary file,
+// so an interrupted run doesn't lose the counters of the previous ones.
+func addCounters(filename string, counts map[string]int64) error {
+	total, err := readCounters(filename)
+	if err != nil {
+		return fmt.Errorf("addCounters: %w", err)
+	}
+	for name, n := range counts {
+		total[name] += n
+	}
+	names := make([]string, 0, len(total))
+	for name := range total {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	f, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*")
+	if err != nil {
+		return fmt.Errorf("addCounters: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	fmt.Fprintln(w, "# errchain counters: name value")
+	for _, name := range names {
+		fmt.Fprintf(w, "%s %d\n", name, total[name])
+	}
+	err = w.Flush()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), filename)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return fmt.Errorf("addCounters: %w", err)
+	}
+	return nil
+}
+
+// readCounters reads a counters file written by addCounters. A missing file has no counters.
+// Names may contain spaces, the value is the last field of a line.
+func readCounters(filename string) (map[string]int64, error) {
+	counts := make(map[string]int64)
+	f, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return counts, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("readCounters: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.LastIndex(line, " ")
+		if i < 0 {
+			return nil, fmt.Errorf("readCounters: %s:%d: malformed line %q", filename, n, line)
+		}
+		value, err := strconv.ParseInt(line[i+1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("readCounters: %s:%d: malformed value: %w", filename, n, err)
+		}
+		counts[strings.TrimSpace(line[:i])] += value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("readCounters: %w", err)
+	}
+	return counts, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCountRun(t *testing.T) {
+	set := testSet(t, map[string][]jsonDiagnostic{
+		"example.com/a": {
+			{Category: "prefix", Posn: "a.go:1:1", Message: "a"},
+			{Category: "prefix", Posn: "a.go:2:1", Message: "b"},
+			{Posn: "a.go:3:1", Message: "c"},
+			{Category: skippedKind, Posn: "a_gen.go:1:1", Message: "generated file: a_gen.go"},
+		},
+		"example.com/b": {{Category: skippedKind, Posn: "b_gen.go:1:1", Message: "generated file: b_gen.go"}},
+	})
+	got := countRun(set, []string{"-disable", "wrap-verb, style,", "./..."})
+	want := map[string]int64{
+		counterRuns:                       1,
+		counterRulePrefix + "prefix":      2,
+		counterRulePrefix + otherKind:     1,
+		counterSkipped + "generated file": 2,
+		counterDisabled + "wrap-verb":     1,
+		counterDisabled + "style":         1,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("countRun() = %v, want %v", got, want)
+	}
+
+	set.withoutCategory(skippedKind)
+	if _, ok := set.diags["example.com/b"]; ok || len(set.sorted()) != 3 {
+		t.Errorf("withoutCategory left %+v", set.sorted())
+	}
+}
+
+func TestAddCounters(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "counters")
+	if err := addCounters(filename, map[string]int64{counterRuns: 1, "rule/prefix": 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := addCounters(filename, map[string]int64{counterRuns: 1, "suppressed/skip/generated file": 3}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# errchain counters: name value
+rule/prefix 2
+runs 2
+suppressed/skip/generated file 3
+`
+	if string(data) != want {
+		t.Errorf("counters file:\n%s\nwant:\n%s", data, want)
+	}
+	// no temporary file is left behind
+	if matches, _ := filepath.Glob(filename + ".*"); len(matches) != 0 {
+		t.Errorf("temporary files left: %q", matches)
+	}
+}
+
+func TestReadCountersErrors(t *testing.T) {
+	for src, want := range map[string]string{
+		"runs\n":          `:1: malformed line "runs"`,
+		"# c\nruns one\n": ":2: malformed value",
+	} {
+		filename := filepath.Join(t.TempDir(), "counters")
+		if err := os.WriteFile(filename, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readCounters(filename); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("readCounters(%q) error = %v, want %s", src, err, want)
+		}
+	}
+}
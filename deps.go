@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/iimos/go-check-err-chains/errchain"
+)
+
+// A moduleReport is the summary of the packages of a dependency module.
+type moduleReport struct {
+	path       string
+	packages   int
+	withIssues int
+	issues     int
+}
+
+// runDeps checks the packages of the modules the given packages depend on, e.g. the published libraries of the
+// organization consumed by a monorepo, to see where the own code has to add wrapping. The source of the modules
+// is loaded with -mod=mod against a copy of go.mod and go.sum, see tempModFile, downloading it to the module cache
+// if needed: neither the module cache nor the files of the main module are modified.
+// The check is informational: a summary per module is printed and the exit code doesn't depend on the issues.
+func runDeps(args []string) int {
+	flags := flag.NewFlagSet("deps", flag.ContinueOnError)
+	var modules string
+	flags.StringVar(&modules, "modules", "", "comma-separated glob patterns of module paths to check, e.g. example.com/*; "+
+		"all the dependency modules are checked by default")
+	verbose := flags.Bool("v", false, "print the issues as well")
+	errchain.Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		flags.Var(f.Value, f.Name, f.Usage)
+	})
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: errchain deps [-modules patterns] [-v] [flags] [package]...\n\n")
+		fmt.Fprintf(os.Stderr, "Reports how the dependency modules of the packages follow the prefix convention.\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	if exitcode, ok := parseFlags(flags, append(envArgs(os.Environ(), isAnalyzerFlag), args...)); !ok {
+		return exitcode
+	}
+
+	// the flags of the analyzer are passed to the checker as they are set
+	var checkerArgs []string
+	flags.Visit(func(f *flag.Flag) {
+		if errchain.Analyzer.Flags.Lookup(f.Name) != nil {
+			checkerArgs = append(checkerArgs, "-"+f.Name+"="+f.Value.String())
+		}
+	})
+	patterns := flags.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	goflags, cleanup, err := tempModFile()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "errchain deps: %v\n", err)
+		return exitError
+	}
+	defer cleanup()
+	env := []string{"GOFLAGS=" + strings.TrimSpace(os.Getenv("GOFLAGS")+" "+goflags)}
+	byModule, err := depPackages(env, patterns, splitPatterns(modules))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "errchain deps: %v\n", err)
+		return exitError
+	}
+	if len(byModule) == 0 {
+		fmt.Fprintln(os.Stderr, "errchain deps: no dependency modules to check")
+		return exitOK
+	}
+
+	var packages []string
+	for _, pkgs := range byModule {
+		packages = append(packages, pkgs...)
+	}
+	sort.Strings(packages)
+	tree, err := runChecker(env, append(checkerArgs, packages...))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "errchain deps: %v\n", err)
+		return exitError
+	}
+	set := newDiagnosticSet()
+	set.add("", tree)
+	if *verbose {
+		set.print(false)
+	} else {
+		for _, e := range set.errors {
+			fmt.Fprintln(os.Stderr, e)
+		}
+	}
+
+	if err := printModules(os.Stdout, summarizeModules(set, byModule)); err != nil {
+		fmt.Fprintf(os.Stderr, "errchain deps: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// tempModFile copies the go.mod and go.sum of the main module to a temporary directory and returns the GOFLAGS
+// loading the packages with -mod=mod against the copy: the go command may add missing requirements and checksums
+// to it, while the files of the main module stay as they are. The cleanup function removes the copy.
+// Outside a module there is nothing to copy and no flags are needed.
+func tempModFile() (goflags string, cleanup func(), err error) {
+	out, err := exec.Command("go", "env", "GOMOD").Output()
+	if err != nil {
+		return "", nil, fmt.Errorf("tempModFile: go env GOMOD: %w", err)
+	}
+	gomod := strings.TrimSpace(string(out))
+	if gomod == "" || gomod == os.DevNull {
+		return "", func() {}, nil
+	}
+	dir, err := os.MkdirTemp("", "errchain-deps")
+	if err != nil {
+		return "", nil, fmt.Errorf("tempModFile: %w", err)
+	}
+	cleanup = func() { _ = os.RemoveAll(dir) }
+	modfile := filepath.Join(dir, "go.mod")
+	// the go command reads and updates the go.sum next to the -modfile
+	for src, dst := range map[string]string{gomod: modfile, strings.TrimSuffix(gomod, ".mod") + ".sum": filepath.Join(dir, "go.sum")} {
+		data, err := os.ReadFile(src)
+		if errors.Is(err, fs.ErrNotExist) && dst != modfile {
+			continue
+		}
+		if err == nil {
+			err = os.WriteFile(dst, data, 0o644)
+		}
+		if err != nil {
+			cleanup()
+			return "", nil, fmt.Errorf("tempModFile: %w", err)
+		}
+	}
+	return "-mod=mod -modfile=" + modfile, cleanup, nil
+}
+
+// depPackages returns the import paths of the dependencies of the packages by module path, leaving out
+// the standard library, the main modules and the modules not matching the patterns if there are any.
+func depPackages(env, patterns, modules []string) (map[string][]string, error) {
+	var stdout bytes.Buffer
+	const format = "{{with .Module}}{{if not .Main}}{{.Path}} {{$.ImportPath}}{{end}}{{end}}"
+	cmd := exec.Command("go", append([]string{"list", "-deps", "-f", format}, patterns...)...)
+	cmd.Env = append(childEnviron(), env...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("depPackages: %w", err)
+	}
+
+	byModule := make(map[string][]string)
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		module, pkg, ok := strings.Cut(line, " ")
+		if ok && matchModule(modules, module) {
+			byModule[module] = append(byModule[module], pkg)
+		}
+	}
+	return byModule, nil
+}
+
+// matchModule tells whether a module path matches one of the glob patterns or there are no patterns.
+func matchModule(patterns []string, module string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, module); ok {
+			return true
+		}
+	}
+	return len(patterns) == 0
+}
+
+// splitPatterns splits a comma-separated list dropping empty elements.
+func splitPatterns(s string) []string {
+	var list []string
+	for _, elem := range strings.Split(s, ",") {
+		if elem = strings.TrimSpace(elem); elem != "" {
+			list = append(list, elem)
+		}
+	}
+	return list
+}
+
+// summarizeModules counts the issues of the set in the packages of every module.
+func summarizeModules(set *diagnosticSet, byModule map[string][]string) []moduleReport {
+	issues := make(map[string]int)
+	for id, results := range set.diags {
+		for _, diags := range results {
+			issues[packagePath(id)] += len(diags)
+		}
+	}
+
+	reports := make([]moduleReport, 0, len(byModule))
+	for module, pkgs := range byModule {
+		r := moduleReport{path: module, packages: len(pkgs)}
+		for _, pkg := range pkgs {
+			if issues[pkg] > 0 {
+				r.withIssues++
+				r.issues += issues[pkg]
+			}
+		}
+		reports = append(reports, r)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].path < reports[j].path })
+	return reports
+}
+
+func printModules(w io.Writer, reports []moduleReport) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "MODULE\tPACKAGES\tWITH ISSUES\tISSUES\tCOMPLIANCE")
+	for _, r := range reports {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d%%\n", r.path, r.packages, r.withIssues, r.issues,
+			(r.packages-r.withIssues)*100/r.packages)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDeps(t *testing.T) {
+	out, exitcode := runMainIn(t, "testdata/deps/app", "deps", "./...")
+	// the check is informational
+	if exitcode != exitOK {
+		t.Fatalf("exit code = %d, want %d, output:\n%s", exitcode, exitOK, out)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want a header and a module:\n%s", len(lines), out)
+	}
+	if got, want := strings.Fields(lines[1]), []string{"example.com/lib", "2", "1", "1", "50%"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("module line = %q, want %q", got, want)
+	}
+}
+
+func TestDepsModules(t *testing.T) {
+	out, exitcode := runMainIn(t, "testdata/deps/app", "deps", "-modules=example.com/other*", "./...")
+	if exitcode != exitOK || out != "" {
+		t.Errorf("deps of no modules: exit code = %d, output:\n%s", exitcode, out)
+	}
+	if out, exitcode := runMainIn(t, "testdata/deps/app", "deps", "-no-such-flag"); exitcode != exitConfig {
+		t.Errorf("deps with an unknown flag: exit code = %d, want %d, output:\n%s", exitcode, exitConfig, out)
+	}
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A jsonTree is a mapping from package ID to analyzer name to result as printed by the checker in -json mode.
+// Each result is either a jsonError or a list of jsonDiagnostic.
+type jsonTree map[string]map[string]json.RawMessage
+
+type jsonDiagnostic struct {
+	Category       string            `json:"category,omitempty"`
+	Posn           string            `json:"posn"`
+	Message        string            `json:"message"`
+	SuggestedFixes []json.RawMessage `json:"suggested_fixes,omitempty"`
+	Fingerprint    string            `json:"fingerprint,omitempty"` // set by -fingerprints, see fingerprint
+}
+
+type jsonError struct {
+	Err string `json:"error"`
+}
+
+// runChecker runs the checker in a child process with -json flag and returns its decoded output.
+// The env is appended to the current environment stripped of the variables setting flags.
+func runChecker(env []string, args []string) (jsonTree, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("runChecker: %w", err)
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command(exe, append([]string{"-json"}, args...)...)
+	cmd.Env = append(append(childEnviron(), checkerEnv+"=1"), env...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("runChecker: %w", err)
+	}
+
+	tree := make(jsonTree)
+	if err := json.Unmarshal(stdout.Bytes(), &tree); err != nil {
+		return nil, fmt.Errorf("runChecker: decode output: %w", err)
+	}
+	return tree, nil
+}
+
+// driverOptions are the flags handled by the driver rather than by the checker.
+type driverOptions struct {
+	format     string // -format
+	matrix     string // -matrix: the comma-separated GOOS/GOARCH targets the packages are checked for
+	metricsOut string // -metrics-out: the file the statistics of the run are written to
+	counters   string // -counters: the file the rule hits and suppressions are added to
+	overrides  string // -overrides: the file of flags per package pattern
+
+	fingerprints   bool // -fingerprints
+	staged         bool // -staged
+	moduleRelative bool // -module-paths
+}
+
+// needed tells whether the options require the driver: a format the checker can't write or
+// a flag of the driver is set.
+func (o driverOptions) needed() bool {
+	return o.format == "html" || o.format == "json" || o.format == "lens" || o.matrix != "" || o.metricsOut != "" ||
+		o.counters != "" || o.overrides != "" || o.fingerprints || o.staged || o.moduleRelative
+}
+
+// runDriver runs the checker in child processes, once or per -matrix target, and writes the merged diagnostics
+// in opts.format along with the statistics of the run if opts.metricsOut is set. The packages matching a section
+// of the overrides file are checked with its flags. If opts.counters is set, the rule hits and suppressions
+// of the run are added to the counters kept in that file. If opts.staged is set, the packages of the Go files staged
+// for commit are checked instead of the package patterns and only the diagnostics in these files are kept.
+// If opts.moduleRelative is set, the positions of the machine-readable outputs are relative to the module roots.
+func runDriver(opts driverOptions, args []string) (exitcode int) {
+	start := time.Now()
+	var files []string
+	if opts.staged {
+		var err error
+		if files, err = stagedFiles(); err != nil {
+			fmt.Fprintf(os.Stderr, "errchain: %v\n", err)
+			return exitError
+		}
+		if len(files) == 0 {
+			return exitOK
+		}
+		dirs, err := stagedPatterns(files)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "errchain: %v\n", err)
+			return exitError
+		}
+		flagArgs, _ := splitArgs(args)
+		flagArgs = flagArgs[:len(flagArgs):len(flagArgs)]
+		if flag, ok := reportFilesFlag(files); ok && opts.counters == "" {
+			// the counters keep the hits of whole packages, so their diagnostics are built anyway
+			flagArgs = append(flagArgs, flag)
+		}
+		args = append(flagArgs, dirs...)
+	}
+	var sections []section
+	if opts.overrides != "" {
+		var err error
+		if sections, err = readOverrides(opts.overrides); err != nil {
+			fmt.Fprintf(os.Stderr, "errchain: %v\n", err)
+			return exitConfig
+		}
+	}
+
+	if opts.format == "lens" {
+		// the summaries are diagnostics of the coverage category, see errchain.ParseCoverage
+		args = append([]string{"-coverage"}, args...)
+	}
+	// skipped parts of the code are counted as suppressions, but only shown if asked for
+	countSkipped := opts.counters != "" && !hasFlag(args, "why-skipped")
+	if countSkipped {
+		args = append([]string{"-why-skipped"}, args...)
+	}
+	set := newDiagnosticSet()
+	if opts.matrix == "" {
+		tree, err := check(nil, sections, args)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "errchain: %v\n", err)
+			return checkExitCode(err)
+		}
+		set.add("", tree)
+	} else if code := collectMatrix(set, opts.matrix, sections, args); code != 0 {
+		return code
+	}
+
+	if opts.counters != "" {
+		if err := addCounters(opts.counters, countRun(set, args)); err != nil {
+			fmt.Fprintf(os.Stderr, "errchain: %v\n", err)
+			return exitError
+		}
+		if countSkipped {
+			set.withoutCategory(skippedKind)
+		}
+	}
+
+	if opts.staged {
+		set.onlyFiles(files)
+	}
+
+	src := newSources()
+	if opts.fingerprints {
+		fingerprint(set, src)
+	}
+
+	var packages []string
+	if opts.format == "html" || opts.metricsOut != "" {
+		var err error
+		if packages, err = listPackages(patterns(args)); err != nil {
+			fmt.Fprintf(os.Stderr, "errchain: %v\n", err)
+			return exitError
+		}
+	}
+	var paths *modulePaths
+	if opts.moduleRelative {
+		paths = newModulePaths()
+	}
+	switch opts.format {
+	case "html":
+		exitcode = writeReport(os.Stdout, set, packages, time.Since(start))
+	case "json":
+		splitArgs(args) // sets the flags of the analyzer, e.g. -separator, to parse the messages
+		exitcode = writeJSON(os.Stdout, set, src, paths)
+	case "lens":
+		if paths != nil {
+			paths.rewrite(set)
+		}
+		exitcode = writeLens(os.Stdout, set)
+	default:
+		asJSON := hasFlag(args, "json")
+		if paths != nil && asJSON {
+			paths.rewrite(set)
+		}
+		exitcode = set.print(asJSON)
+	}
+	if opts.metricsOut != "" {
+		if err := writeMetrics(opts.metricsOut, set, packages, time.Since(start)); err != nil {
+			fmt.Fprintf(os.Stderr, "errchain: %v\n", err)
+			return exitError
+		}
+	}
+	return exitcode
+}
+
+// A diagnosticSet collects diagnostics of several checker runs without duplicates.
+type diagnosticSet struct {
+	diags  map[string]map[string][]jsonDiagnostic // package ID -> analyzer -> diagnostics
+	seen   map[string]bool
+	errors []string
+}
+
+func newDiagnosticSet() *diagnosticSet {
+	return &diagnosticSet{
+		diags: make(map[string]map[string][]jsonDiagnostic),
+		seen:  make(map[string]bool),
+	}
+}
+
+// add merges the checker output into the set. The label is used to annotate analysis errors.
+func (set *diagnosticSet) add(label string, tree jsonTree) {
+	for id, results := range tree {
+		for name, raw := range results {
+			var diags []jsonDiagnostic
+			if err := json.Unmarshal(raw, &diags); err != nil {
+				var jerr jsonError
+				_ = json.Unmarshal(raw, &jerr)
+				set.errors = append(set.errors, fmt.Sprintf("%s: %s: %s: %s", label, id, name, jerr.Err))
+				continue
+			}
+			for _, d := range diags {
+				key := name + "\x00" + d.Posn + "\x00" + d.Message
+				if set.seen[key] {
+					continue
+				}
+				set.seen[key] = true
+				if set.diags[id] == nil {
+					set.diags[id] = make(map[string][]jsonDiagnostic)
+				}
+				set.diags[id][name] = append(set.diags[id][name], d)
+			}
+		}
+	}
+}
+
+// sorted returns all the diagnostics of the set ordered by position.
+func (set *diagnosticSet) sorted() []jsonDiagnostic {
+	var all []jsonDiagnostic
+	for _, results := range set.diags {
+		for _, diags := range results {
+			all = append(all, diags...)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		fi, li, ci := splitPosn(all[i].Posn)
+		fj, lj, cj := splitPosn(all[j].Posn)
+		if fi != fj {
+			return fi < fj
+		}
+		if li != lj {
+			return li < lj
+		}
+		if ci != cj {
+			return ci < cj
+		}
+		return all[i].Message < all[j].Message
+	})
+	return all
+}
+
+// print writes the set to stdout either as plain text or as JSON and returns the exit code, see exitIssues.
+func (set *diagnosticSet) print(asJSON bool) (exitcode int) {
+	for _, e := range set.errors {
+		fmt.Fprintln(os.Stderr, e)
+	}
+
+	if asJSON {
+		data, err := json.MarshalIndent(set.diags, "", "\t")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		fmt.Printf("%s\n", data)
+		return set.exitCode(len(set.sorted()))
+	}
+
+	diags := set.sorted()
+	for _, d := range diags {
+		if d.Fingerprint != "" {
+			fmt.Printf("%s: %s [%s]\n", d.Posn, d.Message, d.Fingerprint)
+			continue
+		}
+		fmt.Printf("%s: %s\n", d.Posn, d.Message)
+	}
+	return set.exitCode(len(diags))
+}
+
+// exitCode returns the exit code of a run which found the given number of issues: the analysis errors
+// of the set fail it first, see exitError.
+func (set *diagnosticSet) exitCode(issues int) int {
+	switch {
+	case len(set.errors) > 0:
+		return exitError
+	case issues > 0:
+		return exitIssues
+	}
+	return exitOK
+}
+
+// splitPosn splits a "file:line:col" position into its parts.
+func splitPosn(posn string) (file string, line, col int) {
+	file = posn
+	if i := strings.LastIndex(file, ":"); i >= 0 {
+		if n, err := strconv.Atoi(file[i+1:]); err == nil {
+			col, file = n, file[:i]
+		}
+	}
+	if i := strings.LastIndex(file, ":"); i >= 0 {
+		if n, err := strconv.Atoi(file[i+1:]); err == nil {
+			line, file = n, file[:i]
+		}
+	}
+	return file, line, col
+}
+
+// extractFlag removes a string flag from the command line arguments and returns its value.
+func extractFlag(args []string, name string) (value string, rest []string, ok bool) {
+	rest = make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		trimmed := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		switch {
+		case trimmed == arg:
+			rest = append(rest, arg)
+		case strings.HasPrefix(trimmed, name+"="):
+			value, ok = strings.TrimPrefix(trimmed, name+"="), true
+		case trimmed == name && i+1 < len(args):
+			value, ok = args[i+1], true
+			i++
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return value, rest, ok
+}
+
+// hasFlag tells whether a boolean flag is set in the command line arguments.
+func hasFlag(args []string, name string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			return false
+		}
+		switch strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-") {
+		case name, name + "=true", name + "=1":
+			return strings.HasPrefix(arg, "-")
+		}
+	}
+	return false
+}
+
+// withoutFlag removes a boolean flag from the command line arguments.
+func withoutFlag(args []string, name string) []string {
+	rest := make([]string, 0, len(args))
+	for i, arg := range args {
+		if arg == "--" {
+			return append(rest, args[i:]...)
+		}
+		trimmed := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		if trimmed != arg && (trimmed == name || strings.HasPrefix(trimmed, name+"=")) {
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testMainEnv makes the test binary run main, so the driver tests run it as the command and the driver
+// runs it as the checker, see runChecker.
+const testMainEnv = "GO_CHECK_ERR_CHAINS_TEST_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(testMainEnv) != "" {
+		main()
+	}
+	os.Exit(m.Run())
+}
+
+// runMain runs the test binary as the command with the arguments and returns its output and exit code.
+func runMain(t *testing.T, args ...string) (stdout string, exitcode int) {
+	t.Helper()
+	return runMainIn(t, "", args...)
+}
+
+// runMainIn runs the command as runMain does in a directory.
+func runMainIn(t *testing.T, dir string, args ...string) (stdout string, exitcode int) {
+	t.Helper()
+	var out bytes.Buffer
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(childEnviron(), testMainEnv+"=1")
+	cmd.Stdout = &out
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return out.String(), exitErr.ExitCode()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out.String(), exitOK
+}
+
+func TestDriverJSON(t *testing.T) {
+	out, exitcode := runMain(t, "-format", "json", "./testdata/driver/store")
+	if exitcode != exitIssues {
+		t.Fatalf("exit code = %d, want %d, output:\n%s", exitcode, exitIssues, out)
+	}
+	var diags []flatDiagnostic
+	if err := json.Unmarshal([]byte(out), &diags); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, out)
+	}
+	if len(diags) != 1 {
+		t.Fatalf("got %d diagnostics, want 1:\n%s", len(diags), out)
+	}
+	d := diags[0]
+	const pkg = "github.com/iimos/go-check-err-chains/testdata/driver/store"
+	if !strings.HasSuffix(d.Posn, "store.go:9:9") || d.Rule != "prefix" || d.Package != pkg {
+		t.Errorf("got %+v, want the prefix diagnostic at store.go:9:9", d)
+	}
+	if want := []string{"store: ", "store.Get: "}; !reflect.DeepEqual(d.Expected, want) {
+		t.Errorf("expected prefixes = %q, want %q", d.Expected, want)
+	}
+
+	if _, exitcode := runMain(t, "-format", "json", "-no-fail", "./testdata/driver/store"); exitcode != exitOK {
+		t.Errorf("exit code with -no-fail = %d, want %d", exitcode, exitOK)
+	}
+}
+
+func TestExtractFlag(t *testing.T) {
+	for _, tt := range []struct {
+		args  []string
+		value string
+		rest  []string
+		ok    bool
+	}{
+		{[]string{"./..."}, "", []string{"./..."}, false},
+		{[]string{"-matrix", "targets.txt", "./..."}, "targets.txt", []string{"./..."}, true},
+		{[]string{"--matrix=targets.txt", "-json", "./..."}, "targets.txt", []string{"-json", "./..."}, true},
+		// a flag sharing the prefix is another flag
+		{[]string{"-matrix-x=1", "./..."}, "", []string{"-matrix-x=1", "./..."}, false},
+		// the value is missing
+		{[]string{"./...", "-matrix"}, "", []string{"./...", "-matrix"}, false},
+		// the arguments after -- aren't flags
+		{[]string{"--", "-matrix", "targets.txt"}, "", []string{"--", "-matrix", "targets.txt"}, false},
+		{[]string{"matrix", "targets.txt"}, "", []string{"matrix", "targets.txt"}, false},
+	} {
+		value, rest, ok := extractFlag(tt.args, "matrix")
+		if value != tt.value || !reflect.DeepEqual(rest, tt.rest) || ok != tt.ok {
+			t.Errorf("extractFlag(%q) = %q, %q, %v, want %q, %q, %v", tt.args, value, rest, ok, tt.value, tt.rest, tt.ok)
+		}
+	}
+}
+
+func TestHasFlag(t *testing.T) {
+	for _, tt := range []struct {
+		args []string
+		want bool
+	}{
+		{nil, false},
+		{[]string{"-staged"}, true},
+		{[]string{"--staged=true", "./..."}, true},
+		{[]string{"-staged=1"}, true},
+		{[]string{"-staged=false"}, false},
+		{[]string{"staged"}, false},
+		{[]string{"-staged-only"}, false},
+		{[]string{"--", "-staged"}, false},
+	} {
+		if got := hasFlag(tt.args, "staged"); got != tt.want {
+			t.Errorf("hasFlag(%q) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestWithoutFlag(t *testing.T) {
+	for _, tt := range []struct {
+		args []string
+		want []string
+	}{
+		{nil, []string{}},
+		{[]string{"-staged", "./..."}, []string{"./..."}},
+		{[]string{"-json", "--staged=false", "./..."}, []string{"-json", "./..."}},
+		{[]string{"-staged-only", "staged"}, []string{"-staged-only", "staged"}},
+		{[]string{"-staged", "--", "-staged"}, []string{"--", "-staged"}},
+	} {
+		if got := withoutFlag(tt.args, "staged"); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("withoutFlag(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	for _, tt := range []struct {
+		args     []string
+		flags    []string
+		patterns []string
+	}{
+		{nil, nil, []string{"."}},
+		{[]string{"./..."}, []string{}, []string{"./..."}},
+		{[]string{"-json", "-c=1", "./a", "./b"}, []string{"-json", "-c=1"}, []string{"./a", "./b"}},
+		{[]string{"-json"}, []string{"-json"}, []string{"."}},
+		// an unknown flag leaves all the arguments to the checker, which reports it
+		{[]string{"-unknown", "./..."}, []string{"-unknown", "./..."}, []string{"."}},
+	} {
+		flags, patterns := splitArgs(tt.args)
+		if !reflect.DeepEqual(flags, tt.flags) || !reflect.DeepEqual(patterns, tt.patterns) {
+			t.Errorf("splitArgs(%q) = %q, %q, want %q, %q", tt.args, flags, patterns, tt.flags, tt.patterns)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"strings"
+
+	"github.com/iimos/go-check-err-chains/errchain"
+	"github.com/iimos/go-check-err-chains/internal/envflags"
+)
+
+// envPrefix is a prefix of environment variables setting flags, e.g. ERRCHAIN_PKG_COMPONENT=optional
+// is the same as -pkg-component=optional.
+const envPrefix = envflags.Prefix
+
+// envArgs returns command line flags set by the environment variables naming the flags isFlag knows,
+// see envflags.Args. They must precede the command line arguments so that flags passed explicitly take precedence.
+func envArgs(environ []string, isFlag func(name string) bool) []string {
+	return envflags.Args(environ, func(name string) (string, bool) { return name, isFlag(name) })
+}
+
+// isAnalyzerFlag tells whether a name is a flag of the analyzer, which the subcommands accept.
+func isAnalyzerFlag(name string) bool {
+	return errchain.Analyzer.Flags.Lookup(name) != nil
+}
+
+// isCommandFlag tells whether a name is a flag of the errchain command: a flag of the analyzer, of the driver
+// or of the checker.
+func isCommandFlag(name string) bool {
+	if isAnalyzerFlag(name) || flag.CommandLine.Lookup(name) != nil {
+		return true
+	}
+	for _, names := range [][]string{checkerValueFlags, checkerBoolFlags} {
+		for _, n := range names {
+			if n == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// childEnviron returns the environment for child processes of the driver without the variables setting flags,
+// since the flags are passed to children explicitly.
+func childEnviron() []string {
+	environ := os.Environ()
+	env := make([]string, 0, len(environ))
+	for _, kv := range environ {
+		if !strings.HasPrefix(kv, envPrefix) {
+			env = append(env, kv)
+		}
+	}
+	return env
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEnvArgs(t *testing.T) {
+	for _, tt := range []struct {
+		environ []string
+		want    []string
+	}{
+		{nil, nil},
+		{[]string{"HOME=/root", "GOFLAGS=-mod=mod"}, nil},
+		{[]string{"ERRCHAIN_PKG_COMPONENT=optional"}, []string{"-pkg-component=optional"}},
+		{[]string{"ERRCHAIN_SEPARATOR= - "}, []string{"-separator= - "}},
+		{[]string{"ERRCHAIN_FORMAT=json", "ERRCHAIN_NO_FAIL=true"}, []string{"-format=json", "-no-fail=true"}},
+		// the variables are sorted, so the output doesn't depend on the order of the environment
+		{[]string{"ERRCHAIN_FACTORY=skip", "ERRCHAIN_ERROR_LAST=true"}, []string{"-error-last=true", "-factory=skip"}},
+		// variables of other tools sharing the prefix are ignored
+		{[]string{"ERRCHAIN_TOKEN=secret", "ERRCHAIN_PROPAGATION=false"}, []string{"-propagation=false"}},
+		{[]string{"ERRCHAIN_=x", "ERRCHAIN_FACTORY"}, nil},
+	} {
+		if got := envArgs(tt.environ, isCommandFlag); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("envArgs(%q) = %q, want %q", tt.environ, got, tt.want)
+		}
+	}
+}
+
+func TestEnvArgsOfSubcommands(t *testing.T) {
+	// the subcommands accept the flags of the analyzer only
+	environ := []string{"ERRCHAIN_FORMAT=json", "ERRCHAIN_SEPARATOR= - "}
+	want := []string{"-separator= - "}
+	if got := envArgs(environ, isAnalyzerFlag); !reflect.DeepEqual(got, want) {
+		t.Errorf("envArgs(%q) = %q, want %q", environ, got, want)
+	}
+}
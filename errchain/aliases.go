@@ -0,0 +1,109 @@
+package errchain
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"strconv"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// A typeAlias is a type of the package being analyzed re-exported by another package of the module,
+// e.g. type Client = transport.Client in package sdk. Callers know the methods of the type as methods
+// of the alias, so prefixes may name the aliasing package, see config.aliasPackages.
+type typeAlias struct {
+	pkg  string // the name of the aliasing package, e.g. "sdk"
+	name string // the name of the alias, e.g. "Client"
+}
+
+// moduleAliases finds the exported aliases of the types of the package being analyzed declared by the other
+// packages of its module, keyed by the names of the aliased types. Files are only parsed, so an imported package
+// is told by its directory in the module.
+func moduleAliases(pass *analysis.Pass) map[string][]typeAlias {
+	aliases := make(map[string][]typeAlias)
+	if len(pass.Files) == 0 {
+		return aliases
+	}
+	filename := pass.Fset.Position(pass.Files[0].Package).Filename
+	root, ok := moduleRoot(filename)
+	if !ok || isDependencyModule(root) {
+		return aliases
+	}
+	module, ok := modulePath(root)
+	if !ok {
+		return aliases
+	}
+	own, err := filepath.Rel(root, filepath.Dir(filename))
+	if err != nil {
+		return aliases
+	}
+	own = path.Join(module, filepath.ToSlash(own))
+
+	walkModuleFiles(root, func(filename, dir string) {
+		if path.Join(module, dir) == own {
+			return
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), filename, nil, parser.SkipObjectResolution)
+		if err != nil || file.Name.Name == "main" {
+			return
+		}
+		local := ""
+		for _, spec := range file.Imports {
+			if importPath, _ := strconv.Unquote(spec.Path.Value); importPath == own {
+				local = pass.Pkg.Name()
+				if spec.Name != nil {
+					local = spec.Name.Name
+				}
+			}
+		}
+		if local == "" {
+			return
+		}
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				sel, ok := typeSpec.Type.(*ast.SelectorExpr)
+				if !typeSpec.Assign.IsValid() || !ok || !typeSpec.Name.IsExported() {
+					continue
+				}
+				if x, ok := sel.X.(*ast.Ident); ok && x.Name == local {
+					aliases[sel.Sel.Name] = append(aliases[sel.Sel.Name], typeAlias{pkg: file.Name.Name, name: typeSpec.Name.Name})
+				}
+			}
+		}
+	})
+	return aliases
+}
+
+// aliased reinterprets a location naming an alias of the receiver declared by another package of the module,
+// e.g. "sdk.Client.Do" or "sdk.Do" for (*transport.Client).Do re-exported with type Client = transport.Client,
+// as a location in the package itself. It returns false if the location names no such alias.
+func (loc location) aliased(pkg *types.Package, fn *funcInfo) (location, bool) {
+	state := stateOf(pkg)
+	if state == nil || fn.recv == "" {
+		return loc, false
+	}
+	for _, alias := range state.aliases[fn.recv] {
+		switch {
+		case alias.pkg != loc.pkg:
+			continue
+		case loc.recv == alias.name:
+			loc.recv = fn.prefixRecv()
+		case loc.recv == "" && loc.fn == alias.name:
+			loc.fn = fn.prefixRecv()
+		case loc.recv != "":
+			continue
+		}
+		loc.pkg = pkg.Name()
+		return loc, true
+	}
+	return loc, false
+}
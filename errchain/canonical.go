@@ -0,0 +1,134 @@
+package errchain
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// canonicalDirective right above an error constructor call declares the prefix its message starts with,
+// so code generators can emit error sites checked against the prefixes their templates intend:
+//
+//	//errchain:canonical "store.DB.Get"
+//	return fmt.Errorf("store.DB.Get: %w", err)
+const canonicalDirective = "//errchain:canonical"
+
+var ruleCanonical = registerRule(rule{
+	code:             "canonical",
+	doc:              "messages start with the prefix declared by the errchain:canonical comment above them, which matches the function",
+	enabledByDefault: always,
+	example: func(fn, _ string) (string, string) {
+		loc := strings.TrimSuffix(fn, string(config.separator))
+		return "//errchain:canonical " + strconv.Quote(loc) + "\n" + `errors.New("not found")`,
+			"//errchain:canonical " + strconv.Quote(loc) + "\n" + `errors.New("` + fn + `not found")`
+	},
+})
+
+// handleCanonical checks the canonical directives in the body of a function. The prefix of a directive must match
+// the function as the prefix of a message does, and the message of the call on the next line must start with it.
+// Malformed directives and directives without a call below are reported by the directive rule.
+func handleCanonical(pass *analysis.Pass, fn *funcInfo, rules componentRules) {
+	file := enclosingFile(pass, fn.decl.Pos())
+	if file == nil {
+		return
+	}
+	for _, group := range file.Comments {
+		if group.Pos() < fn.decl.Body.Pos() || group.End() > fn.decl.Body.End() {
+			continue
+		}
+		for _, c := range group.List {
+			if c.Text == canonicalDirective || strings.HasPrefix(c.Text, canonicalDirective+" ") {
+				checkCanonical(pass, fn, rules, c)
+			}
+		}
+	}
+}
+
+// checkCanonical checks a canonical directive of a function, see handleCanonical.
+func checkCanonical(pass *analysis.Pass, fn *funcInfo, rules componentRules, c *ast.Comment) {
+	sep := string(config.separator)
+	// Anything after the quoted prefix is a comment: //errchain:canonical "pkg.Fn" // from the errgen template
+	quoted, err := strconv.QuotedPrefix(strings.TrimSpace(strings.TrimPrefix(c.Text, canonicalDirective)))
+	var prefix string
+	if err == nil {
+		prefix, err = strconv.Unquote(quoted)
+		prefix = strings.TrimSuffix(prefix, sep)
+	}
+	if err != nil || prefix == "" {
+		if config.enabled(ruleDirective) {
+			reportf(pass, c.Pos(), ruleDirective, "Malformed %s directive: the prefix must be a quoted string, e.g. %s %q",
+				canonicalDirective[2:], canonicalDirective, "pkg.Type.Method")
+		}
+		return
+	}
+	call, ctor := canonicalCall(pass, fn.decl.Body, pass.Fset.Position(c.Pos()).Line+1)
+	if call == nil {
+		if config.enabled(ruleDirective) {
+			reportf(pass, c.Pos(), ruleDirective, "Misplaced %s directive: there is no error constructor call on the next line",
+				canonicalDirective[2:])
+		}
+		return
+	}
+	if !config.enabled(ruleCanonical) {
+		return
+	}
+
+	if perr := matchMessage(pass, fn, rules, prefix+sep); perr != nil {
+		reportf(pass, c.Pos(), ruleCanonical, "Canonical prefix %q does not match the function: %s",
+			prefix, prefixProblem(pass, fn, rules, call.Pos(), perr))
+		return
+	}
+	check, ok := checkMessage(pass, fn, rules, call, ctor)
+	if !ok {
+		reportf(pass, call.Pos(), ruleCanonical, "Message is not static, the canonical prefix %q can't be verified", prefix)
+		return
+	}
+	if strings.HasPrefix(check.message, prefix+sep) || !reportable(pass, call.Pos()) {
+		return
+	}
+	diag := analysis.Diagnostic{
+		Pos:      call.Pos(),
+		Category: ruleCanonical,
+		Message:  "Message does not start with the canonical prefix " + strconv.Quote(prefix),
+	}
+	// the prefix the message has, if any, is replaced, otherwise the canonical one is prepended
+	end := 0
+	if _, err := parsePrefix(check.message); err == nil {
+		end = strings.Index(check.message, sep) + len(sep)
+	}
+	if lit := messageLiteral(call, ctor); lit != nil && strings.HasPrefix(lit.Value[1:], check.message[:end]) {
+		diag.SuggestedFixes = []analysis.SuggestedFix{{
+			Message: "Start the message with " + strconv.Quote(prefix+sep),
+			TextEdits: []analysis.TextEdit{{
+				Pos:     lit.Pos() + 1,
+				End:     lit.Pos() + 1 + token.Pos(end),
+				NewText: []byte(prefix + sep),
+			}},
+		}}
+	}
+	pass.Report(diag)
+}
+
+// canonicalCall returns the outermost error constructor call of a body starting on a line, e.g. the call
+// of a return statement below a canonical directive, or nil if there is none.
+func canonicalCall(pass *analysis.Pass, body *ast.BlockStmt, line int) (*ast.CallExpr, constructor) {
+	var found *ast.CallExpr
+	var ctor constructor
+	ast.Inspect(body, func(node ast.Node) bool {
+		if found != nil {
+			return false
+		}
+		call, ok := node.(*ast.CallExpr)
+		if !ok || pass.Fset.Position(call.Pos()).Line != line {
+			return true
+		}
+		if c, ok := constructorOf(pass, call); ok && !c.panics && c.messageArg(call) != nil {
+			found, ctor = call, c
+		}
+		return true
+	})
+	return found, ctor
+}
@@ -0,0 +1,115 @@
+package errchain
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A casingMode tells how receiver and function names of error prefixes are compared with the declared ones.
+// It implements flag.Value.
+type casingMode string
+
+const (
+	casingExact    casingMode = "exact"    // pkg.JSONEncoder.Encode only
+	casingAcronyms casingMode = "acronyms" // pkg.jsonEncoder.Encode and pkg.JsonEncoder.Encode as well
+	casingFold     casingMode = "fold"     // any casing, e.g. pkg.jsonencoder.encode
+)
+
+func (m *casingMode) String() string {
+	return string(*m)
+}
+
+func (m *casingMode) Set(s string) error {
+	switch mode := casingMode(s); mode {
+	case casingExact, casingAcronyms, casingFold:
+		*m = mode
+		return nil
+	}
+	return fmt.Errorf("unknown casing mode %q, must be %q, %q or %q", s, casingExact, casingAcronyms, casingFold)
+}
+
+// equal tells whether a name used in an error prefix refers to a declared name.
+func (m casingMode) equal(name, declared string) bool {
+	switch m {
+	case casingAcronyms:
+		return normalizeAcronyms(name) == normalizeAcronyms(declared)
+	case casingFold:
+		return strings.EqualFold(name, declared)
+	}
+	return name == declared
+}
+
+// commonAcronyms are the initialisms Go style spells in a consistent case, as listed by golint.
+var commonAcronyms = []string{
+	"ACL", "API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GUID", "HTML", "HTTP", "HTTPS", "ID", "IP", "JSON",
+	"LHS", "QPS", "RAM", "RHS", "RPC", "SLA", "SMTP", "SQL", "SSH", "TCP", "TLS", "TTL", "UDP", "UI", "UID",
+	"UUID", "URI", "URL", "UTF8", "VM", "XML", "XMPP", "XSRF", "XSS",
+}
+
+// isAcronym tells whether a word is one of commonAcronyms or config.acronyms in any case.
+func isAcronym(word string) bool {
+	word = strings.ToUpper(word)
+	return containsString(commonAcronyms, word) || containsString(config.acronyms, word)
+}
+
+// normalizeAcronyms lower-cases the acronyms and the first letter of a name, so "JSONEncoder", "JsonEncoder"
+// and "jsonEncoder" as well as "UserID" and "userId" have the same normal form.
+func normalizeAcronyms(name string) string {
+	var b strings.Builder
+	for i, word := range splitWords(name) {
+		switch {
+		case isAcronym(word):
+			word = strings.ToLower(word)
+		case i == 0:
+			r, size := utf8.DecodeRuneInString(word)
+			word = string(unicode.ToLower(r)) + word[size:]
+		}
+		b.WriteString(word)
+	}
+	return b.String()
+}
+
+// splitWords splits a mixed caps name into words: "HTTPServerID" into "HTTP", "Server" and "ID",
+// "jsonEncoder" into "json" and "Encoder".
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+		// a word starts with an upper case letter following a lower case one, e.g. json|Encoder,
+		// or with the last upper case letter of a run followed by a lower case one, e.g. HTTP|Server
+		if unicode.IsUpper(cur) && (!unicode.IsUpper(prev) || unicode.IsLower(next)) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
+
+// An acronymList is a comma-separated list of acronyms added to commonAcronyms. It implements flag.Value.
+type acronymList []string
+
+func (l *acronymList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *acronymList) Set(s string) error {
+	list := splitList(s)
+	for i, acronym := range list {
+		for _, r := range acronym {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				return fmt.Errorf("bad acronym %q, must contain only letters and digits", acronym)
+			}
+		}
+		list[i] = strings.ToUpper(acronym)
+	}
+	*l = list
+	return nil
+}
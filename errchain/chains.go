@@ -0,0 +1,243 @@
+package errchain
+
+import (
+	"go/ast"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"github.com/iimos/go-check-err-chains/internal/errflow"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+var ruleLongPrefix = registerRule(rule{
+	code:             "long-prefix",
+	doc:              "error prefixes and the prefixes of error chains aren't longer than -max-prefix-components and -max-chain-length",
+	enabledByDefault: func(c *configuration) bool { return c.maxPrefixComponents > 0 || c.maxChainLength > 0 },
+	flags:            []string{"max-prefix-components", "max-chain-length"},
+})
+
+// PrefixChain is a fact about a function whose errors start with a chain of prefixes, e.g. "api.Get: store.load: ",
+// telling the length of the longest one. A caller wrapping such an error prepends its own prefix to the chain.
+// The facts are exported only if the long-prefix rule is enabled.
+type PrefixChain struct {
+	Length int
+}
+
+// AFact implements analysis.Fact.
+func (*PrefixChain) AFact() {}
+
+func (f *PrefixChain) String() string {
+	return "PrefixChain(" + strconv.Itoa(f.Length) + ")"
+}
+
+// A chainIndex computes the lengths of the prefix chains of the errors of the functions of the package.
+type chainIndex struct {
+	pass     *analysis.Pass
+	decls    map[*types.Func]*ast.FuncDecl
+	lengths  map[*ast.FuncDecl]int
+	visiting map[*ast.FuncDecl]bool
+}
+
+// handleChains exports PrefixChain facts for the functions of the package and reports error constructor calls
+// with prefixes of more than config.maxPrefixComponents components or starting chains longer than
+// config.maxChainLength characters, counting the prefixes of the wrapped errors.
+func handleChains(pass *analysis.Pass) {
+	if !config.enabled(ruleLongPrefix) {
+		return
+	}
+	chains := &chainIndex{
+		pass:     pass,
+		decls:    make(map[*types.Func]*ast.FuncDecl),
+		lengths:  make(map[*ast.FuncDecl]int),
+		visiting: make(map[*ast.FuncDecl]bool),
+	}
+	var decls []*ast.FuncDecl
+	for _, file := range pass.Files {
+		if isTest(pass, file) {
+			continue
+		}
+		for _, decl := range file.Decls {
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Body != nil {
+				decls = append(decls, funcDecl)
+				if obj, ok := pass.TypesInfo.Defs[funcDecl.Name].(*types.Func); ok {
+					chains.decls[obj] = funcDecl
+				}
+			}
+		}
+	}
+
+	// facts are exported in the order of declarations so that the output is deterministic
+	for _, decl := range decls {
+		if n := chains.funcLength(decl); n > 0 {
+			pass.ExportObjectFact(pass.TypesInfo.Defs[decl.Name], &PrefixChain{Length: n})
+		}
+	}
+	for _, file := range pass.Files {
+		if isTest(pass, file) || isSkippedGenerated(pass, file) || isSkippedMock(pass, file) {
+			continue
+		}
+		for _, decl := range file.Decls {
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Body != nil {
+				chains.check(funcDecl)
+			}
+		}
+	}
+}
+
+// check reports the error constructor calls of a function with too long prefixes or chains.
+func (c *chainIndex) check(decl *ast.FuncDecl) {
+	ast.Inspect(decl.Body, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		ctor, ok := constructorOf(c.pass, call)
+		if !ok || ctor.messageArg(call) == nil {
+			return true
+		}
+		format, ok := stableString(c.pass, ctor.messageArg(call))
+		if !ok {
+			return true
+		}
+		components, length := prefixPortion(format)
+		if max := config.maxPrefixComponents; max > 0 && components > max {
+			reportf(c.pass, call.Pos(), ruleLongPrefix,
+				"Error prefix has %d components, at most %d are allowed by -max-prefix-components", components, max)
+			return true
+		}
+		if max := config.maxChainLength; max > 0 {
+			if length += c.wrappedLength(decl.Body, call, ctor, format); length > max {
+				reportf(c.pass, call.Pos(), ruleLongPrefix,
+					"Error chain starts with %d characters of prefixes, at most %d are allowed by -max-chain-length", length, max)
+			}
+		}
+		return true
+	})
+}
+
+// funcLength returns the length of the longest prefix chain of the errors a function returns.
+// A recursive call counts as no chain.
+func (c *chainIndex) funcLength(decl *ast.FuncDecl) int {
+	if n, ok := c.lengths[decl]; ok {
+		return n
+	}
+	errIndex, last := errorResultIndex(decl)
+	if errIndex < 0 || c.visiting[decl] {
+		return 0
+	}
+	c.visiting[decl] = true
+	defer delete(c.visiting, decl)
+
+	n := 0
+	ast.Inspect(decl.Body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			var result ast.Expr
+			switch {
+			case len(node.Results) == 1 && last > 0:
+				// return f()
+				result = node.Results[0]
+			case errIndex < len(node.Results):
+				result = node.Results[errIndex]
+			default:
+				return true
+			}
+			for _, expr := range errorComponents(c.pass, result) {
+				if length := c.exprLength(decl.Body, expr); length > n {
+					n = length
+				}
+			}
+		}
+		return true
+	})
+	c.lengths[decl] = n
+	return n
+}
+
+// exprLength returns the length of the prefix chain of an error expression: a call or a variable
+// last assigned from a call in the body.
+func (c *chainIndex) exprLength(body *ast.BlockStmt, expr ast.Expr) int {
+	switch expr := astutil.Unparen(expr).(type) {
+	case *ast.CallExpr:
+		return c.callLength(body, expr)
+	case *ast.Ident:
+		if call := errflow.LastAssignedCall(c.pass.TypesInfo, body, expr); call != nil {
+			return c.callLength(body, call)
+		}
+	}
+	return 0
+}
+
+// callLength returns the length of the prefix chain of the error a call returns: the prefixes of the message
+// of an error constructor followed by the chain of the error it wraps, or the chain of a function known
+// by its declaration in the package or by its PrefixChain fact.
+func (c *chainIndex) callLength(body *ast.BlockStmt, call *ast.CallExpr) int {
+	if ctor, ok := constructorOf(c.pass, call); ok {
+		if ctor.messageArg(call) == nil {
+			return 0
+		}
+		format, ok := stableString(c.pass, ctor.messageArg(call))
+		if !ok {
+			return 0
+		}
+		_, length := prefixPortion(format)
+		return length + c.wrappedLength(body, call, ctor, format)
+	}
+	callee, ok := typeutil.Callee(c.pass.TypesInfo, call).(*types.Func)
+	if !ok {
+		return 0
+	}
+	callee = callee.Origin()
+	if decl, ok := c.decls[callee]; ok {
+		return c.funcLength(decl)
+	}
+	var fact PrefixChain
+	if c.pass.ImportObjectFact(callee, &fact) {
+		return fact.Length
+	}
+	return 0
+}
+
+// wrappedLength returns the length of the prefix chain of the error an error constructor call wraps, if any.
+func (c *chainIndex) wrappedLength(body *ast.BlockStmt, call *ast.CallExpr, ctor constructor, format string) int {
+	if !ctor.wraps(call, format) {
+		return 0
+	}
+	if ctor.wrapped >= 0 && ctor.wrapped < len(call.Args) {
+		return c.exprLength(body, call.Args[ctor.wrapped])
+	}
+	errorType := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+	for _, arg := range ctor.formatArgs(call) {
+		if t := c.pass.TypesInfo.TypeOf(arg); t != nil && types.Implements(t, errorType) {
+			return c.exprLength(body, arg)
+		}
+	}
+	return 0
+}
+
+// prefixPortion returns the number of components of the prefixes a message starts with and their length
+// with the separators, e.g. 5 and 23 for "store/pg.Get: pg.load: not found".
+func prefixPortion(message string) (components, length int) {
+	sep := string(config.separator)
+	for rest := message; ; {
+		loc, err := parsePrefix(rest)
+		if err != nil || loc.pkg == "" {
+			return components, length
+		}
+		components += strings.Count(loc.pkg, "/") + 1
+		if loc.recv != "" {
+			components++
+		}
+		if loc.fn != "" {
+			components++
+		}
+		i := strings.Index(rest, sep) + len(sep)
+		length += i
+		rest = rest[i:]
+	}
+}
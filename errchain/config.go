@@ -0,0 +1,553 @@
+package errchain
+
+import (
+	"fmt"
+	"go/types"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	Analyzer.Flags.Var(&config.pkgComponent, "pkg-component",
+		"whether error prefixes must contain the package name: required or optional")
+	Analyzer.Flags.Var(&config.minComponents, "min-prefix-components",
+		"comma-separated pattern=N pairs setting the minimum number of prefix components in the packages matching "+
+			"the import path pattern: 1 (pkg), 2 (pkg.Func) or 3 (pkg.Recv.Method), e.g. */store=3,tinyutil=1; "+
+			"the first matching pattern wins over -pkg-component, -recv-component and -func-component")
+	Analyzer.Flags.Var(&config.recvComponent, "recv-component",
+		"whether error prefixes of methods must contain the receiver name: required or optional")
+	Analyzer.Flags.Var(&config.funcComponent, "func-component",
+		"whether error prefixes must contain the function or method name: required or optional")
+	Analyzer.Flags.BoolVar(&config.anyErrorPosition, "any-error-position", false,
+		"check functions returning an error not as the last result, e.g. (error, bool)")
+	Analyzer.Flags.BoolVar(&config.errorLast, "error-last", false,
+		"report exported functions returning an error not as the last result")
+	Analyzer.Flags.Var(&config.factoryPolicy, "factory",
+		"policy for error factories like ErrTooBig(limit int) error: skip, pkg (require package prefix) "+
+			"or func (require function prefix)")
+	Analyzer.Flags.BoolVar(&config.packageLevel, "package-level", true,
+		"check error messages in init functions and package-level variable declarations")
+	Analyzer.Flags.Var(&config.pkgMatch, "pkg-match",
+		"how the package in error prefixes is matched: path (the package name or the last element of the import path "+
+			"without a major version suffix) or name (the declared package name only)")
+	Analyzer.Flags.Var(&config.registrars, "registrars",
+		"comma-separated registration functions like example.com/plugins.Register or plugins.Registry.Add; "+
+			"function literals passed to them are checked with the package prefix wherever the call is")
+	Analyzer.Flags.Var(&config.includeGenerated, "include-generated",
+		"comma-separated glob patterns of generated files to check anyway, e.g. *_service.go or internal/api/*.go")
+	Analyzer.Flags.BoolVar(&config.aliasPackages, "alias-packages", false,
+		"accept the names of the packages of the module re-exporting a type with an alias, e.g. sdk for "+
+			"type Client = transport.Client, in prefixes of its methods like sdk.Client.Do")
+	Analyzer.Flags.BoolVar(&config.skipVendor, "skip-vendor", true,
+		"skip packages in vendor directories")
+	Analyzer.Flags.BoolVar(&config.includeThirdParty, "include-third-party", false,
+		"check packages in third_party and external directories holding copies of external code")
+	Analyzer.Flags.BoolVar(&config.skipMocks, "skip-mocks", true,
+		"skip mock packages and files of mockery, gomock or counterfeiter matching -mock-packages or -mock-files, "+
+			"whatever their headers are")
+	Analyzer.Flags.Var(&config.mockPackages, "mock-packages",
+		"comma-separated glob patterns of names or import paths of mock packages for -skip-mocks")
+	Analyzer.Flags.Var(&config.mockFiles, "mock-files",
+		"comma-separated glob patterns of mock files for -skip-mocks")
+	Analyzer.Flags.Var(&config.reportFiles, "report-files",
+		"comma-separated glob patterns of the files to report diagnostics in, e.g. the files of a diff; "+
+			"the other files are checked as well, but their diagnostics aren't built")
+	Analyzer.Flags.BoolVar(&config.verbose, "verbose", false,
+		"report informational diagnostics, e.g. about skipped generated files")
+	Analyzer.Flags.BoolVar(&config.whySkipped, "why-skipped", false,
+		"report everything the analyzer skipped and why with diagnostics of the \"skipped\" category")
+	Analyzer.Flags.BoolVar(&config.coverage, "coverage", false,
+		"report how many error paths of every function are prefixed with diagnostics of the \"coverage\" category")
+	Analyzer.Flags.Var(&config.selfLocating, "self-locating",
+		"comma-separated error constructors which add the location themselves, e.g. via runtime.Caller: "+
+			"example.com/errloc.New; their errors are considered prefixed")
+	Analyzer.Flags.Var(&config.constructors, "constructors",
+		"comma-separated error constructors of libraries with colon-separated attributes: message=N (the index of "+
+			"the message argument, 0 by default), args=N (the first argument formatted by the message), wrapped=N "+
+			"(the wrapped error), suffix-wrap (the last argument is wrapped by a format ending with \": %s\" as in xerrors), "+
+			"panics (a Must-style helper panicking with the message, checked in every function, e.g. must.OK(err, \"msg\")) "+
+			"and self-locating; an index may be a parameter or type parameter name of a generic helper, "+
+			"e.g. github.com/pkg/errors.Wrapf:message=1:args=2:wrapped=0,example.com/errs.Wrap[T]:message=op:wrapped=err")
+	Analyzer.Flags.Var(&config.userConstructors, "user-constructors",
+		"comma-separated constructors of user-facing errors with the attributes of -constructors, e.g. "+
+			"example.com/usererr.New,example.com/usererr.Newf:args=1; their messages are shown to end users, "+
+			"so they must not start with location prefixes or format other errors")
+	Analyzer.Flags.Var(&config.dialect, "dialect",
+		"error prefix convention: location (pkg.Func: ), file (user.go:42: , the file name is validated) or any of them")
+	Analyzer.Flags.Var(&config.qualified, "qualified",
+		"comma-separated import path patterns of packages with ambiguous names like util or */common "+
+			"whose error prefixes must contain parent path segments, e.g. storage/util: ")
+	Analyzer.Flags.Var(&config.wrapperPackages, "wrapper-packages",
+		"comma-separated import path patterns of packages wrapping errors of their callees, e.g. example.com/retry; "+
+			"every error wrapped with %w or by a constructor with a wrapped argument in them must start with the package prefix")
+	Analyzer.Flags.IntVar(&config.minSegments, "min-segments", 2,
+		"minimum number of import path segments in error prefixes of the packages set by -qualified")
+	Analyzer.Flags.IntVar(&config.maxPrefixComponents, "max-prefix-components", 0,
+		"report messages starting with prefixes of more components in total, e.g. 5 for \"store/pg.Get: pg.load: \"; "+
+			"0 disables the check")
+	Analyzer.Flags.IntVar(&config.maxChainLength, "max-chain-length", 0,
+		"report messages whose prefixes along with the prefixes of the errors they wrap, known from facts, "+
+			"are longer in characters; 0 disables the check")
+	Analyzer.Flags.IntVar(&config.styleConsistency, "style-consistency", 0,
+		"report prefixed messages of a package not following the style of at least the given percent of them, "+
+			"e.g. pkg: among messages starting with pkg.Recv.Method: ; 0 disables the check")
+	Analyzer.Flags.BoolVar(&config.dominantStyle, "dominant-style", false,
+		"make the most used prefix style of a package mandatory whatever its share, see -style-consistency")
+	Analyzer.Flags.Var(&config.casing, "casing",
+		"how receiver and function names in error prefixes are compared with the declared ones: exact, "+
+			"acronyms (the case of acronyms and of the first letter is ignored, e.g. jsonEncoder for JSONEncoder) or fold")
+	Analyzer.Flags.Var(&config.acronyms, "acronyms",
+		"comma-separated acronyms added to the common ones like ID or JSON for -casing=acronyms, e.g. GRPC,K8S")
+	Analyzer.Flags.Var(&config.separator, "separator",
+		"separator between an error prefix and the rest of the message, e.g. \" - \" or \" | \"")
+	Analyzer.Flags.Var(&config.multiline, "multiline",
+		"how messages written as raw strings spanning several lines, e.g. templates of user-facing errors, are checked: "+
+			"first-line (only the first line may hold the prefix) or skip")
+	Analyzer.Flags.IntVar(&config.maxMultilineLength, "max-multiline-length", 0,
+		"skip multiline raw string messages longer in bytes; 0 disables the limit")
+	Analyzer.Flags.BoolVar(&config.propagation, "propagation", true,
+		"report errors of unexported functions returned as is by exported ones unless they are verified to be prefixed")
+	Analyzer.Flags.BoolVar(&config.passThrough, "pass-through", false,
+		"report errors wrapped without any text of their own, e.g. fmt.Errorf(\"%w\", err), which are accepted by default")
+	Analyzer.Flags.BoolVar(&config.requireContext, "require-context", false,
+		"report errors.New messages of functions taking arguments and suggest fmt.Errorf including a value")
+	Analyzer.Flags.IntVar(&config.minContextWords, "min-context-words", 0,
+		"report messages wrapping an error with fewer words after the prefix, e.g. 1 for fmt.Errorf(\"pkg.Fn: %w\", err) "+
+			"rather than fmt.Errorf(\"pkg.Fn: load config: %w\", err); verbs aren't words, 0 disables the check")
+	Analyzer.Flags.BoolVar(&config.pointerForm, "pointer-form", false,
+		"report prefixes of methods with pointer receivers naming the receiver in the value form, e.g. pkg.Conn.Close "+
+			"rather than pkg.(*Conn).Close, and suggest the pointer form")
+	Analyzer.Flags.BoolVar(&config.interprocedural, "interprocedural", false,
+		"check unexported helpers called only by exported functions if their errors are returned as is; "+
+			"the messages may start with the prefix of a caller, e.g. pkg.Get: in doGet")
+	Analyzer.Flags.Var(&config.attribution, "attribution",
+		"which checked functions errors of unexported helpers are attributed to: direct (the ones calling a helper, "+
+			"see -interprocedural) or callgraph (the exported entry points reaching a helper through chains of "+
+			"unexported functions, e.g. Get for fetch in Get -> load -> fetch)")
+	Analyzer.Flags.BoolVar(&config.duplicateMessages, "duplicate-messages", false,
+		"report error message literals constructed at two or more sites of a package, which make error chains ambiguous")
+	Analyzer.Flags.BoolVar(&config.httpResponses, "http-responses", false,
+		"check the texts of http.Error and of fmt.Fprintf error responses written by handlers of exported functions "+
+			"returning http.HandlerFunc, which end up in the logs and error chains of the clients")
+	Analyzer.Flags.Var(&config.enable, "enable",
+		"comma-separated codes of rules to enable regardless of their flags, or all; see -list-rules")
+	Analyzer.Flags.Var(&config.disable, "disable",
+		"comma-separated codes of rules to disable, or all; see -list-rules")
+	Analyzer.Flags.Var(&config.callbackPolicy, "callbacks",
+		"policy for function literals passed as call arguments: parent (check them as a part of the enclosing function) "+
+			"or pkg (require the package prefix only)")
+}
+
+// config holds the analyzer settings. It is populated from the analyzer flags.
+var config = &configuration{
+	pkgComponent:   componentRequired,
+	recvComponent:  componentOptional,
+	funcComponent:  componentOptional,
+	factoryPolicy:  factoryPkg,
+	packageLevel:   true,
+	propagation:    true,
+	callbackPolicy: callbackParent,
+	attribution:    attributionDirect,
+	pkgMatch:       pkgMatchPath,
+	dialect:        dialectLocation,
+	separator:      ": ",
+	multiline:      multilineFirstLine,
+	casing:         casingExact,
+	minSegments:    2,
+	skipVendor:     true,
+	skipMocks:      true,
+	mockPackages:   globList{"*mocks", "*fakes", "mock_*"},
+	mockFiles:      globList{"mock_*.go", "fake_*.go"},
+}
+
+type configuration struct {
+	pkgComponent  componentMode
+	recvComponent componentMode
+	funcComponent componentMode
+	minComponents componentDepths
+
+	anyErrorPosition bool
+	errorLast        bool
+
+	factoryPolicy       factoryPolicy
+	packageLevel        bool
+	propagation         bool
+	interprocedural     bool
+	attribution         attributionMode
+	requireContext      bool
+	minContextWords     int
+	pointerForm         bool
+	passThrough         bool
+	duplicateMessages   bool
+	httpResponses       bool
+	callbackPolicy      callbackPolicy
+	pkgMatch            pkgMatchMode
+	aliasPackages       bool
+	dialect             prefixDialect
+	separator           separator
+	multiline           multilineMode
+	maxMultilineLength  int
+	casing              casingMode
+	acronyms            acronymList
+	qualified           globList
+	minSegments         int
+	maxPrefixComponents int
+	maxChainLength      int
+	styleConsistency    int
+	dominantStyle       bool
+	registrars          funcList
+	selfLocating        funcList
+	constructors        constructorTable
+	userConstructors    constructorTable
+	wrapperPackages     globList
+
+	includeGenerated  globList
+	skipVendor        bool
+	includeThirdParty bool
+	skipMocks         bool
+	mockPackages      globList
+	mockFiles         globList
+	reportFiles       globList
+	verbose           bool
+	whySkipped        bool
+	coverage          bool
+
+	enable  ruleList
+	disable ruleList
+}
+
+// componentRules returns the component rules set by the flags for a package.
+func (c *configuration) componentRules(pkg *types.Package) componentRules {
+	switch n, _ := c.minComponents.lookup(pkg.Path()); n {
+	case 1:
+		return componentRules{pkg: componentRequired, recv: componentOptional, fn: componentOptional}
+	case 2:
+		return componentRules{pkg: componentRequired, recv: componentOptional, fn: componentRequired}
+	case 3:
+		// functions without a receiver only need pkg.Func
+		return componentRules{pkg: componentRequired, recv: componentRequired, fn: componentRequired}
+	}
+	return componentRules{pkg: c.pkgComponent, recv: c.recvComponent, fn: c.funcComponent}
+}
+
+// componentRules tell which location components an error prefix must contain.
+type componentRules struct {
+	pkg  componentMode
+	recv componentMode
+	fn   componentMode
+}
+
+// A componentMode tells whether a location component (package, receiver or function) must be present
+// in an error prefix. It implements flag.Value.
+type componentMode string
+
+const (
+	componentRequired componentMode = "required"
+	componentOptional componentMode = "optional"
+)
+
+func (m *componentMode) String() string {
+	return string(*m)
+}
+
+func (m *componentMode) Set(s string) error {
+	switch mode := componentMode(s); mode {
+	case componentRequired, componentOptional:
+		*m = mode
+		return nil
+	}
+	return fmt.Errorf("unknown component mode %q, must be %q or %q", s, componentRequired, componentOptional)
+}
+
+func (m componentMode) required() bool {
+	return m == componentRequired
+}
+
+// A factoryPolicy tells how error messages of error factories are checked. It implements flag.Value.
+type factoryPolicy string
+
+const (
+	factorySkip factoryPolicy = "skip"
+	factoryPkg  factoryPolicy = "pkg"
+	factoryFunc factoryPolicy = "func"
+)
+
+func (p *factoryPolicy) String() string {
+	return string(*p)
+}
+
+func (p *factoryPolicy) Set(s string) error {
+	switch policy := factoryPolicy(s); policy {
+	case factorySkip, factoryPkg, factoryFunc:
+		*p = policy
+		return nil
+	}
+	return fmt.Errorf("unknown factory policy %q, must be %q, %q or %q", s, factorySkip, factoryPkg, factoryFunc)
+}
+
+// An attributionMode tells which checked functions errors of unexported helpers are attributed to,
+// see delegateHelpers. It implements flag.Value.
+type attributionMode string
+
+const (
+	attributionDirect    attributionMode = "direct"
+	attributionCallgraph attributionMode = "callgraph"
+)
+
+func (m *attributionMode) String() string {
+	return string(*m)
+}
+
+func (m *attributionMode) Set(s string) error {
+	switch mode := attributionMode(s); mode {
+	case attributionDirect, attributionCallgraph:
+		*m = mode
+		return nil
+	}
+	return fmt.Errorf("unknown attribution mode %q, must be %q or %q", s, attributionDirect, attributionCallgraph)
+}
+
+// A callbackPolicy tells how error messages of function literals passed as call arguments are checked,
+// e.g. handlers passed to a registration function. It implements flag.Value.
+type callbackPolicy string
+
+const (
+	callbackParent callbackPolicy = "parent"
+	callbackPkg    callbackPolicy = "pkg"
+)
+
+func (p *callbackPolicy) String() string {
+	return string(*p)
+}
+
+func (p *callbackPolicy) Set(s string) error {
+	switch policy := callbackPolicy(s); policy {
+	case callbackParent, callbackPkg:
+		*p = policy
+		return nil
+	}
+	return fmt.Errorf("unknown callback policy %q, must be %q or %q", s, callbackParent, callbackPkg)
+}
+
+// A pkgMatchMode tells how the package component of an error prefix is matched against the package.
+// It implements flag.Value.
+type pkgMatchMode string
+
+const (
+	pkgMatchPath pkgMatchMode = "path"
+	pkgMatchName pkgMatchMode = "name"
+)
+
+func (m *pkgMatchMode) String() string {
+	return string(*m)
+}
+
+func (m *pkgMatchMode) Set(s string) error {
+	switch mode := pkgMatchMode(s); mode {
+	case pkgMatchPath, pkgMatchName:
+		*m = mode
+		return nil
+	}
+	return fmt.Errorf("unknown package match mode %q, must be %q or %q", s, pkgMatchPath, pkgMatchName)
+}
+
+// A prefixDialect is a convention error prefixes follow. It implements flag.Value.
+type prefixDialect string
+
+const (
+	dialectLocation prefixDialect = "location" // pkg.Struct.Method:
+	dialectFile     prefixDialect = "file"     // store/user.go:42:
+	dialectAny      prefixDialect = "any"
+)
+
+func (d *prefixDialect) String() string {
+	return string(*d)
+}
+
+func (d *prefixDialect) Set(s string) error {
+	switch dialect := prefixDialect(s); dialect {
+	case dialectLocation, dialectFile, dialectAny:
+		*d = dialect
+		return nil
+	}
+	return fmt.Errorf("unknown prefix dialect %q, must be %q, %q or %q", s, dialectLocation, dialectFile, dialectAny)
+}
+
+// A multilineMode tells how messages written as raw strings spanning several lines are checked, see checkFormat.
+// It implements flag.Value.
+type multilineMode string
+
+const (
+	multilineFirstLine multilineMode = "first-line" // the prefix is looked for in the first line only
+	multilineSkip      multilineMode = "skip"
+)
+
+func (m *multilineMode) String() string {
+	return string(*m)
+}
+
+func (m *multilineMode) Set(s string) error {
+	switch mode := multilineMode(s); mode {
+	case multilineFirstLine, multilineSkip:
+		*m = mode
+		return nil
+	}
+	return fmt.Errorf("unknown multiline mode %q, must be %q or %q", s, multilineFirstLine, multilineSkip)
+}
+
+// A separator is a token between an error prefix and the rest of the message, ": " by default.
+// It implements flag.Value.
+type separator string
+
+func (s *separator) String() string {
+	return string(*s)
+}
+
+func (s *separator) Set(v string) error {
+	if strings.TrimSpace(v) == "" {
+		return fmt.Errorf("invalid separator %q, must contain a non-space character", v)
+	}
+	if strings.ContainsAny(v, ".%\n") {
+		return fmt.Errorf("invalid separator %q, must not contain dots, percent signs or line breaks", v)
+	}
+	*s = separator(v)
+	return nil
+}
+
+// A funcList is a comma-separated list of functions and methods written as "import/path.Func" or
+// "import/path.Type.Method". The import path may be shortened to its trailing elements. It implements flag.Value.
+type funcList []string
+
+func (l *funcList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *funcList) Set(s string) error {
+	*l = splitList(s)
+	return nil
+}
+
+// contains tells whether the list contains a function with a given full name.
+func (l funcList) contains(fullName string) bool {
+	for _, name := range l {
+		if fullName == name || strings.HasSuffix(fullName, "/"+name) {
+			return true
+		}
+	}
+	return false
+}
+
+// A componentDepth is the minimum number of prefix components in the packages matching a pattern.
+type componentDepth struct {
+	pattern string
+	n       int
+}
+
+// componentDepths is a comma-separated list of pattern=N pairs, e.g. "*/store=3,tinyutil=1".
+// The patterns are matched as in globList. It implements flag.Value.
+type componentDepths []componentDepth
+
+func (d *componentDepths) String() string {
+	pairs := make([]string, 0, len(*d))
+	for _, depth := range *d {
+		pairs = append(pairs, depth.pattern+"="+strconv.Itoa(depth.n))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (d *componentDepths) Set(s string) error {
+	var depths componentDepths
+	for _, pair := range splitList(s) {
+		pattern, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("bad pair %q, must be pattern=N", pair)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("bad pattern %q: %w", pattern, err)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > 3 {
+			return fmt.Errorf("bad number of components %q in %q, must be 1, 2 or 3", value, pair)
+		}
+		depths = append(depths, componentDepth{pattern: pattern, n: n})
+	}
+	*d = depths
+	return nil
+}
+
+// lookup returns the number of components set for an import path by the first matching pattern.
+func (d componentDepths) lookup(pkgPath string) (int, bool) {
+	for _, depth := range d {
+		if matchTrailing(depth.pattern, pkgPath) {
+			return depth.n, true
+		}
+	}
+	return 0, false
+}
+
+// A globList is a comma-separated list of glob patterns matched against file or import paths.
+// It implements flag.Value.
+type globList []string
+
+func (l *globList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *globList) Set(s string) error {
+	list := splitList(s)
+	for _, pattern := range list {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("bad pattern %q: %w", pattern, err)
+		}
+	}
+	*l = list
+	return nil
+}
+
+// match tells whether a path matches any of the patterns. A pattern without a slash is matched against
+// the last element of the path, otherwise against its trailing elements.
+func (l globList) match(filename string) bool {
+	filename = filepath.ToSlash(filename)
+	for _, pattern := range l {
+		if matchTrailing(pattern, filename) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFile is like match for file paths: patterns may use backslashes as separators, so they can't escape
+// special characters, and the case is ignored on case-insensitive file systems.
+func (l globList) matchFile(filename string) bool {
+	filename = foldFileName(slashPath(filename))
+	for _, pattern := range l {
+		if matchTrailing(foldFileName(slashPath(pattern)), filename) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchTrailing matches a slash-separated path against a pattern, see globList.match.
+func matchTrailing(pattern, filename string) bool {
+	elems := strings.Count(pattern, "/") + 1
+	parts := strings.Split(filename, "/")
+	if len(parts) > elems {
+		parts = parts[len(parts)-elems:]
+	}
+	ok, _ := path.Match(pattern, strings.Join(parts, "/"))
+	return ok
+}
+
+// splitList splits a comma-separated list dropping empty elements.
+func splitList(s string) []string {
+	var list []string
+	for _, elem := range strings.Split(s, ",") {
+		if elem = strings.TrimSpace(elem); elem != "" {
+			list = append(list, elem)
+		}
+	}
+	return list
+}
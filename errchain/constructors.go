@@ -0,0 +1,382 @@
+package errchain
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// A constructor describes how calls of an error constructor carry the message. Every call site handling
+// is driven by these descriptions, so a library constructor is supported by adding it with -constructors.
+type constructor struct {
+	name         string // "import/path.Func" or "import/path.Type.Method" as in funcList
+	message      int    // index of the message or format argument
+	args         int    // index of the first argument formatted by the message, -1 if it isn't a format
+	wrapped      int    // index of the wrapped error argument, -1 if there is none
+	suffixWrap   bool   // the last argument is wrapped by a format ending with ": %s", ": %v" or ": %w" as in xerrors
+	selfLocating bool   // the constructor adds the location itself, e.g. via runtime.Caller
+	panics       bool   // the helper panics with the message rather than returns an error, e.g. must.OK(err, "msg")
+
+	// refs are the argument indexes given by parameter names, e.g. "message": "op", resolved against the signature
+	// of the callee, see resolve. A type parameter name refers to the parameter of that type, e.g. "wrapped": "E"
+	// in func Wrap[E error](op string, err E).
+	refs map[string]string
+}
+
+// builtinConstructors are the constructors of the standard library and of golang.org/x/xerrors, which older
+// code still depends on. Their names are matched exactly, so "errors.New" doesn't match "github.com/pkg/errors.New".
+var builtinConstructors = constructorTable{
+	{name: "errors.New", message: 0, args: -1, wrapped: -1},
+	{name: "fmt.Errorf", message: 0, args: 1, wrapped: -1},
+	{name: "golang.org/x/xerrors.New", message: 0, args: -1, wrapped: -1},
+	{name: "golang.org/x/xerrors.Errorf", message: 0, args: 1, wrapped: -1, suffixWrap: true},
+}
+
+// String returns the description in the syntax of -constructors.
+func (c constructor) String() string {
+	s := c.name
+	if c.selfLocating {
+		return s + ":self-locating"
+	}
+	for _, attr := range []struct {
+		key   string
+		index int
+		set   bool
+	}{{"message", c.message, c.message != 0}, {"args", c.args, c.args >= 0}, {"wrapped", c.wrapped, c.wrapped >= 0}} {
+		if ref, ok := c.refs[attr.key]; ok {
+			s += ":" + attr.key + "=" + ref
+		} else if attr.set {
+			s += ":" + attr.key + "=" + strconv.Itoa(attr.index)
+		}
+	}
+	if c.suffixWrap {
+		s += ":suffix-wrap"
+	}
+	if c.panics {
+		s += ":panics"
+	}
+	return s
+}
+
+// isFormat tells whether the message is a format of the fmt package.
+func (c constructor) isFormat() bool {
+	return c.args >= 0
+}
+
+// messageArg returns the message argument of a call or nil if the call has none.
+func (c constructor) messageArg(call *ast.CallExpr) ast.Expr {
+	if c.selfLocating || c.message >= len(call.Args) {
+		return nil
+	}
+	return call.Args[c.message]
+}
+
+// formatArgs returns the arguments of a call formatted by the message.
+func (c constructor) formatArgs(call *ast.CallExpr) []ast.Expr {
+	if !c.isFormat() || c.args >= len(call.Args) {
+		return nil
+	}
+	return call.Args[c.args:]
+}
+
+// wraps tells whether a call with a message format wraps an error: as the wrapped argument, with %w
+// or, for xerrors-like constructors, as the last argument formatted at the end after ": ".
+func (c constructor) wraps(call *ast.CallExpr, format string) bool {
+	if c.wrapped >= 0 && c.wrapped < len(call.Args) {
+		return true
+	}
+	if c.suffixWrap && len(c.formatArgs(call)) > 0 {
+		for _, suffix := range []string{": %s", ": %v", ": %w"} {
+			if strings.HasSuffix(format, suffix) {
+				return true
+			}
+		}
+	}
+	return c.isFormat() && strings.Contains(format, "%w")
+}
+
+// A constructorTable is a comma-separated list of constructor descriptions, each a function name followed by
+// colon-separated attributes: message=N, args=N and wrapped=N set the argument indexes, suffix-wrap marks
+// constructors wrapping the last argument as xerrors.Errorf does, self-locating marks constructors adding
+// the location themselves and panics marks Must-style helpers panicking with the message, e.g.
+// must.OK(err, "msg"), whose messages are checked in every function, see handlePanics.
+// The message is the first argument by default. An index may be given by the name
+// of a parameter or, for generic helpers, of a type parameter, and type parameter lists of names are ignored, e.g.
+//
+//	github.com/pkg/errors.Wrapf:message=1:args=2:wrapped=0,example.com/errloc.New:self-locating
+//	example.com/must.OK:message=1:wrapped=0:panics
+//	example.com/errs.Wrap[T]:message=op:wrapped=err
+//
+// It implements flag.Value.
+type constructorTable []constructor
+
+func (t *constructorTable) String() string {
+	list := make([]string, 0, len(*t))
+	for _, c := range *t {
+		list = append(list, c.String())
+	}
+	return strings.Join(list, ",")
+}
+
+func (t *constructorTable) Set(s string) error {
+	var table constructorTable
+	for _, entry := range splitList(s) {
+		c, err := parseConstructor(entry)
+		if err != nil {
+			return err
+		}
+		table = append(table, c)
+	}
+	*t = table
+	return nil
+}
+
+// parseConstructor parses a constructor description, see constructorTable.
+func parseConstructor(entry string) (constructor, error) {
+	fields := strings.Split(entry, ":")
+	c := constructor{name: stripTypeParams(strings.TrimSpace(fields[0])), message: 0, args: -1, wrapped: -1}
+	if !strings.Contains(c.name, ".") {
+		return c, fmt.Errorf("bad constructor %q, must be path.Func or path.Type.Method", entry)
+	}
+	for _, attr := range fields[1:] {
+		key, value, hasValue := strings.Cut(strings.TrimSpace(attr), "=")
+		switch {
+		case key == "self-locating" && !hasValue:
+			c.selfLocating = true
+			continue
+		case key == "suffix-wrap" && !hasValue:
+			c.suffixWrap = true
+			continue
+		case key == "panics" && !hasValue:
+			c.panics = true
+			continue
+		}
+		if hasValue && token.IsIdentifier(value) && (key == "message" || key == "args" || key == "wrapped") {
+			if c.refs == nil {
+				c.refs = make(map[string]string)
+			}
+			c.refs[key] = value
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if !hasValue || err != nil || n < 0 {
+			return c, fmt.Errorf("bad attribute %q of constructor %s, must be message=N, args=N, wrapped=N, suffix-wrap, self-locating or panics",
+				attr, c.name)
+		}
+		switch key {
+		case "message":
+			c.message = n
+		case "args":
+			c.args = n
+		case "wrapped":
+			c.wrapped = n
+		default:
+			return c, fmt.Errorf("unknown attribute %q of constructor %s, must be message, args, wrapped, suffix-wrap, self-locating or panics",
+				key, c.name)
+		}
+	}
+	if c.suffixWrap && !c.isFormat() {
+		if _, ok := c.refs["args"]; !ok {
+			return c, fmt.Errorf("bad constructor %s: suffix-wrap needs formatted arguments", entry)
+		}
+	}
+	if len(c.refs) > 0 {
+		// the indexes are validated once resolved
+		return c, nil
+	}
+	return c, c.validate()
+}
+
+// validate checks the argument indexes of a constructor.
+func (c constructor) validate() error {
+	if c.isFormat() && c.args <= c.message {
+		return fmt.Errorf("bad constructor %s: formatted arguments must follow the message", c)
+	}
+	if c.wrapped == c.message || (c.isFormat() && c.wrapped >= c.args) {
+		return fmt.Errorf("bad constructor %s: the wrapped error can't be the message or a formatted argument", c)
+	}
+	return nil
+}
+
+// resolve returns the constructor with the argument indexes given by names resolved against the signature
+// of the callee. An instantiated generic callee is resolved against its generic signature, where the parameters
+// keep their type parameters. It fails if a name matches no parameter.
+func (c constructor) resolve(callee *types.Func) (constructor, error) {
+	if len(c.refs) == 0 {
+		return c, nil
+	}
+	params := callee.Origin().Type().(*types.Signature).Params()
+	for key, ref := range c.refs {
+		i := paramIndex(params, ref)
+		if i < 0 {
+			return c, fmt.Errorf("bad constructor %s: no parameter %s", c, ref)
+		}
+		switch key {
+		case "message":
+			c.message = i
+		case "args":
+			c.args = i
+		case "wrapped":
+			c.wrapped = i
+		}
+	}
+	c.refs = nil
+	return c, c.validate()
+}
+
+// paramIndex returns the index of the parameter with a given name or, failing that, of the first parameter
+// of a type parameter with that name, or -1 if there is none.
+func paramIndex(params *types.Tuple, name string) int {
+	for i := 0; i < params.Len(); i++ {
+		if params.At(i).Name() == name {
+			return i
+		}
+	}
+	for i := 0; i < params.Len(); i++ {
+		if tparam, ok := params.At(i).Type().(*types.TypeParam); ok && tparam.Obj().Name() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// stripTypeParams removes type parameter lists from a function name, e.g. "example.com/errs.Box[T].Wrap"
+// becomes "example.com/errs.Box.Wrap", since instantiated callees are matched by the names of their origins.
+func stripTypeParams(name string) string {
+	for {
+		start := strings.IndexByte(name, '[')
+		end := strings.IndexByte(name, ']')
+		if start < 0 || end < start {
+			return name
+		}
+		name = name[:start] + name[end+1:]
+	}
+}
+
+// lookup returns the description of a constructor with a given full name, see funcList.contains.
+func (t constructorTable) lookup(fullName string) (constructor, bool) {
+	for _, c := range t {
+		if fullName == c.name || strings.HasSuffix(fullName, "/"+c.name) {
+			return c, true
+		}
+	}
+	return constructor{}, false
+}
+
+// constructorOf returns the description of the error constructor a call calls: the ones set by -constructors
+// go first, then the ones of the standard library and the ones set by -self-locating.
+func constructorOf(pass *analysis.Pass, call *ast.CallExpr) (constructor, bool) {
+	callee, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok {
+		return constructor{}, false
+	}
+	name := funcFullName(callee)
+	if c, ok := config.constructors.lookup(name); ok {
+		c, err := c.resolve(callee)
+		return c, err == nil
+	}
+	for _, c := range builtinConstructors {
+		if c.name == name {
+			return c, true
+		}
+	}
+	if config.selfLocating.contains(name) {
+		return constructor{name: name, selfLocating: true, args: -1, wrapped: -1}, true
+	}
+	return constructor{}, false
+}
+
+// isSelfLocatingCall tells whether an expression is a call of an error constructor adding the location
+// to the message itself, see constructor.selfLocating.
+func isSelfLocatingCall(pass *analysis.Pass, expr ast.Expr) bool {
+	call, ok := astutil.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	c, ok := constructorOf(pass, call)
+	return ok && c.selfLocating
+}
+
+// Constructors returns the names of the error constructors set by -constructors, e.g. "github.com/pkg/errors.Wrapf",
+// for tools checking the configuration. Type parameter lists are removed from the names.
+func Constructors() []string {
+	names := make([]string, 0, len(config.constructors))
+	for _, c := range config.constructors {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// CheckConstructor checks that the signature of a function set by -constructors matches its description:
+// the argument names resolve, the message is a string, the formatted arguments are the variadic ones,
+// the wrapped argument is an error and the function returns an error unless it panics.
+func CheckConstructor(fn *types.Func) error {
+	fn = fn.Origin()
+	c, ok := config.constructors.lookup(funcFullName(fn))
+	if !ok {
+		return fmt.Errorf("%s isn't set by -constructors", funcFullName(fn))
+	}
+	c, err := c.resolve(fn)
+	if err != nil {
+		return err
+	}
+	if c.selfLocating {
+		return nil
+	}
+	sig := fn.Type().(*types.Signature)
+	params := sig.Params()
+	errorType := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+	returnsError := false
+	for i := 0; i < sig.Results().Len(); i++ {
+		returnsError = returnsError || types.Implements(sig.Results().At(i).Type(), errorType)
+	}
+	switch {
+	case !returnsError && !c.panics:
+		return fmt.Errorf("bad constructor %s: %s returns no error", c, fn.Name())
+	case c.message >= params.Len():
+		return fmt.Errorf("bad constructor %s: %s has no argument %d for the message", c, fn.Name(), c.message)
+	case !isStringParam(params.At(c.message).Type()):
+		return fmt.Errorf("bad constructor %s: the message argument %s of %s isn't a string", c, params.At(c.message).Name(), fn.Name())
+	case c.isFormat() && (!sig.Variadic() || c.args != params.Len()-1):
+		return fmt.Errorf("bad constructor %s: the formatted arguments must be the variadic parameter of %s", c, fn.Name())
+	case c.wrapped >= params.Len():
+		return fmt.Errorf("bad constructor %s: %s has no argument %d for the wrapped error", c, fn.Name(), c.wrapped)
+	case c.wrapped >= 0 && !types.Implements(params.At(c.wrapped).Type(), errorType):
+		return fmt.Errorf("bad constructor %s: the wrapped argument %s of %s isn't an error", c, params.At(c.wrapped).Name(), fn.Name())
+	}
+	return nil
+}
+
+// isStringParam tells whether a parameter type is a string, e.g. string, a named string type or a type parameter
+// constrained by ~string.
+func isStringParam(t types.Type) bool {
+	if tparam, ok := t.(*types.TypeParam); ok {
+		constraint, ok := tparam.Constraint().Underlying().(*types.Interface)
+		if !ok {
+			return false
+		}
+		for i := 0; i < constraint.NumEmbeddeds(); i++ {
+			if isStringParam(constraint.EmbeddedType(i)) {
+				return true
+			}
+		}
+		return false
+	}
+	if union, ok := t.(*types.Union); ok {
+		for i := 0; i < union.Len(); i++ {
+			if !isStringParam(union.Term(i).Type()) {
+				return false
+			}
+		}
+		return union.Len() > 0
+	}
+	basic, ok := t.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsString != 0
+}
@@ -0,0 +1,204 @@
+package errchain
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var ruleContext = registerRule(rule{
+	code:             "context",
+	doc:              `errors.New messages of functions taking arguments include a value, e.g. fmt.Errorf("pkg.Get: %q: not found", key)`,
+	enabledByDefault: func(c *configuration) bool { return c.requireContext },
+	flags:            []string{"require-context"},
+	example: func(fn, _ string) (string, string) {
+		return `errors.New("` + fn + `not found")`, `fmt.Errorf("` + fn + `%q: not found", key)`
+	},
+})
+
+// contextTODO is the comment left next to the argument a context fix adds, since the first parameter
+// is only a guess at the value worth reporting.
+const contextTODO = "/* TODO: check the context value */"
+
+// handleContext reports errors.New calls of a function taking arguments: the message tells where the error
+// happened, but not with what. Only messages with a valid prefix are reported, the others are fixed
+// by the prefix rule first. The fix turns errors.New("pkg.Get: not found") into
+// fmt.Errorf("pkg.Get: not found: %v", key /* TODO */) taking the first parameter worth reporting
+// as a placeholder, see contextParam, and escaping the percent signs of the message.
+func handleContext(pass *analysis.Pass, fn *funcInfo, rules componentRules) {
+	if !config.enabled(ruleContext) {
+		return
+	}
+	param := contextParam(pass, fn.decl)
+	if param == "" {
+		return
+	}
+	file := enclosingFile(pass, fn.decl.Pos())
+	ast.Inspect(fn.decl.Body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			check, ok := checkCall(pass, fn, rules, node)
+			if !ok || check.err != nil || check.ctor.name != "errors.New" || !reportable(pass, node.Pos()) {
+				return true
+			}
+			diag := analysis.Diagnostic{
+				Pos:      node.Pos(),
+				Category: ruleContext,
+				Message:  "Error message has no context: use fmt.Errorf to include a value, e.g. " + param,
+			}
+			if lit := messageLiteral(node, check.ctor); lit != nil && file != nil {
+				value, _ := strconv.Unquote(lit.Value)
+				value = strings.ReplaceAll(value, "%", "%%")
+				edits := []analysis.TextEdit{{
+					Pos: node.Pos(),
+					End: node.End(),
+					NewText: []byte("fmt.Errorf(" + strconv.Quote(value+string(config.separator)+"%v") + ", " +
+						param + " " + contextTODO + ")"),
+				}}
+				diag.SuggestedFixes = []analysis.SuggestedFix{{
+					Message:   "Convert to fmt.Errorf with " + param,
+					TextEdits: append(edits, fmtImportEdits(pass, file, node)...),
+				}}
+			}
+			pass.Report(diag)
+		}
+		return true
+	})
+}
+
+// contextParam returns the name of the first named parameter of a function worth reporting in its errors
+// or an empty string. A context.Context and receiver-like parameters, pointers to structs and interfaces
+// like a *DB or an io.Reader the function works with, are skipped along with functions and channels:
+// they print as addresses or not at all.
+func contextParam(pass *analysis.Pass, decl *ast.FuncDecl) string {
+	for _, field := range decl.Type.Params.List {
+		for _, name := range field.Names {
+			obj := pass.TypesInfo.Defs[name]
+			if name.Name == "_" || obj == nil || !isContextValue(obj.Type()) {
+				continue
+			}
+			return name.Name
+		}
+	}
+	return ""
+}
+
+// isContextValue tells whether a value of a type is worth reporting in an error message, see contextParam.
+func isContextValue(typ types.Type) bool {
+	if named, ok := typ.(*types.Named); ok && named.Obj().Pkg() != nil &&
+		named.Obj().Pkg().Path() == "context" && named.Obj().Name() == "Context" {
+		return false
+	}
+	switch t := typ.Underlying().(type) {
+	case *types.Pointer:
+		_, isStruct := t.Elem().Underlying().(*types.Struct)
+		return !isStruct
+	case *types.Interface, *types.Signature, *types.Chan:
+		return false
+	}
+	return true
+}
+
+// enclosingFile returns the file of the package containing a position or nil.
+func enclosingFile(pass *analysis.Pass, pos token.Pos) *ast.File {
+	for _, file := range pass.Files {
+		if file.Pos() <= pos && pos < file.End() {
+			return file
+		}
+	}
+	return nil
+}
+
+// fmtImportEdits returns the import edits a call of errors.New replaced by fmt.Errorf needs: "fmt" is imported
+// unless it already is, and "errors" is removed if the call was its only use. The edits are computed for a single
+// fix, so fixes of several calls in a file are to be applied one by one.
+func fmtImportEdits(pass *analysis.Pass, file *ast.File, call *ast.CallExpr) []analysis.TextEdit {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	errorsPkg, ok := pass.TypesInfo.Uses[ident].(*types.PkgName)
+	if !ok {
+		return nil
+	}
+	uses := 0
+	ast.Inspect(file, func(node ast.Node) bool {
+		if id, ok := node.(*ast.Ident); ok && pass.TypesInfo.Uses[id] == errorsPkg {
+			uses++
+		}
+		return true
+	})
+	keepErrors := uses > 1
+	hasFmt := importSpec(file, "fmt") != nil
+
+	spec := importSpec(file, "errors")
+	if spec == nil || (hasFmt && keepErrors) {
+		return nil
+	}
+	switch {
+	case hasFmt:
+		// errors isn't used anymore
+		return []analysis.TextEdit{deleteLines(pass, spec)}
+	case !keepErrors:
+		return []analysis.TextEdit{{Pos: spec.Path.Pos(), End: spec.Path.End(), NewText: []byte(`"fmt"`)}}
+	}
+	decl := importDecl(file, spec)
+	if decl == nil || !decl.Lparen.IsValid() {
+		// import "errors"
+		return []analysis.TextEdit{{Pos: spec.End(), End: spec.End(), NewText: []byte("\nimport \"fmt\"")}}
+	}
+	// keep the standard library group sorted
+	for _, s := range decl.Specs {
+		if s := s.(*ast.ImportSpec); importPath(s) > "fmt" {
+			return []analysis.TextEdit{{Pos: s.Pos(), End: s.Pos(), NewText: []byte("\"fmt\"\n\t")}}
+		}
+	}
+	last := decl.Specs[len(decl.Specs)-1]
+	return []analysis.TextEdit{{Pos: last.End(), End: last.End(), NewText: []byte("\n\t\"fmt\"")}}
+}
+
+// importSpec returns the import of a package by the file or nil.
+func importSpec(file *ast.File, path string) *ast.ImportSpec {
+	for _, spec := range file.Imports {
+		if importPath(spec) == path {
+			return spec
+		}
+	}
+	return nil
+}
+
+func importPath(spec *ast.ImportSpec) string {
+	path, _ := strconv.Unquote(spec.Path.Value)
+	return path
+}
+
+// importDecl returns the import declaration containing an import spec.
+func importDecl(file *ast.File, spec *ast.ImportSpec) *ast.GenDecl {
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT && gen.Pos() <= spec.Pos() && spec.End() <= gen.End() {
+			return gen
+		}
+	}
+	return nil
+}
+
+// deleteLines removes the lines of a node.
+func deleteLines(pass *analysis.Pass, node ast.Node) analysis.TextEdit {
+	tf := pass.Fset.File(node.Pos())
+	start := tf.LineStart(tf.Line(node.Pos()))
+	end := node.End()
+	if line := tf.Line(end); line < tf.LineCount() {
+		end = tf.LineStart(line + 1)
+	}
+	return analysis.TextEdit{Pos: start, End: end}
+}
@@ -0,0 +1,96 @@
+package errchain
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// CoverageCategory is the category of diagnostics reported in the -coverage mode, one per function with error
+// paths. They summarize the function rather than point to an issue, see ParseCoverage.
+const CoverageCategory = "coverage"
+
+var ruleCoverage = registerRule(rule{
+	code:             CoverageCategory,
+	doc:              "per-function summaries telling how many error paths of a function are prefixed",
+	enabledByDefault: func(c *configuration) bool { return c.coverage },
+	flags:            []string{"coverage"},
+})
+
+// A funcCoverage is the error paths of a function: its error constructor calls with checked messages
+// mapped to whether they are prefixed.
+type funcCoverage struct {
+	fn    *funcInfo
+	paths map[token.Pos]bool
+}
+
+// recordPath records an error constructor call of a function for its coverage summary. A call reached
+// by several walks, e.g. in a nested function literal, keeps the result of the first one.
+func recordPath(pass *analysis.Pass, fn *funcInfo, call *ast.CallExpr, prefixed bool) {
+	state := stateOf(pass.Pkg)
+	if state == nil || fn == nil || fn.decl == nil || !config.enabled(ruleCoverage) {
+		return
+	}
+	cov, ok := state.coverage[fn.decl]
+	if !ok {
+		cov = &funcCoverage{fn: fn, paths: make(map[token.Pos]bool)}
+		state.coverage[fn.decl] = cov
+	}
+	if _, seen := cov.paths[call.Pos()]; !seen {
+		cov.paths[call.Pos()] = prefixed
+	}
+}
+
+// isPrefixed tells whether a checked message is prefixed properly, whatever other issues it has,
+// e.g. a format verb not matching its argument.
+func (check callCheck) isPrefixed() bool {
+	return check.err == nil || check.err.errType == errFormatVerb || check.err.errType == errFormatArgs
+}
+
+// reportCoverage reports the coverage summaries of the functions of the package in the order of declaration.
+func reportCoverage(pass *analysis.Pass) {
+	state := stateOf(pass.Pkg)
+	if state == nil || len(state.coverage) == 0 {
+		return
+	}
+	list := make([]*funcCoverage, 0, len(state.coverage))
+	for _, cov := range state.coverage {
+		list = append(list, cov)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].fn.decl.Pos() < list[j].fn.decl.Pos() })
+
+	for _, cov := range list {
+		prefixed := 0
+		for _, ok := range cov.paths {
+			if ok {
+				prefixed++
+			}
+		}
+		name := cov.fn.name
+		if cov.fn.recv != "" {
+			name = cov.fn.recv + "." + name
+		}
+		reportf(pass, cov.fn.decl.Name.Pos(), ruleCoverage, coverageFormat, prefixed, len(cov.paths), name)
+	}
+}
+
+const coverageFormat = "%d of %d error paths of %s are prefixed"
+
+// A Coverage is the prefix coverage of a function parsed from a diagnostic of CoverageCategory.
+type Coverage struct {
+	Func     string // the function name, e.g. "Store.Get" for a method
+	Paths    int    // error constructor calls with messages checked statically
+	Prefixed int
+}
+
+// ParseCoverage parses a diagnostic message of CoverageCategory.
+func ParseCoverage(diagnostic string) (Coverage, error) {
+	var c Coverage
+	if _, err := fmt.Sscanf(diagnostic, coverageFormat, &c.Prefixed, &c.Paths, &c.Func); err != nil {
+		return Coverage{}, fmt.Errorf("ParseCoverage: %w", err)
+	}
+	return c, nil
+}
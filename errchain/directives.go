@@ -0,0 +1,148 @@
+package errchain
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// prefixDirective overrides the package component of error prefixes for a package, which is useful when
+// the canonical external name differs from the Go package name. It is usually put into doc.go:
+//
+//	//errchain:prefix billingapi
+//	package billing
+const prefixDirective = "//errchain:prefix"
+
+var ruleDirective = registerRule(rule{
+	code:             "directive",
+	doc:              "prefix directives are well-formed and don't conflict",
+	enabledByDefault: always,
+	example: func(string, string) (string, string) {
+		return "//errchain:prefix billing-api", "//errchain:prefix billingapi"
+	},
+})
+
+// exemptDirective in the doc comment of a type exempts its methods from the checks.
+const exemptDirective = "//errchain:exempt"
+
+// parsePrefixDirective looks for a prefix directive in the files of the package. The ones in the doc comments
+// of types set receiver names, see typeDirectives. Malformed and conflicting directives are reported.
+func parsePrefixDirective(pass *analysis.Pass) (prefix string, ok bool) {
+	var found *ast.Comment
+	for _, file := range pass.Files {
+		docs := typeDocs(file)
+		for _, group := range file.Comments {
+			if docs[group] != nil {
+				continue
+			}
+			for _, c := range group.List {
+				if c.Text != prefixDirective && !strings.HasPrefix(c.Text, prefixDirective+" ") {
+					continue
+				}
+				// Anything after the name is a comment: //errchain:prefix billingapi // external API name
+				var value string
+				if fields := strings.Fields(strings.TrimPrefix(c.Text, prefixDirective)); len(fields) > 0 {
+					value = fields[0]
+				}
+				switch {
+				case !token.IsIdentifier(value):
+					if config.enabled(ruleDirective) {
+						reportf(pass, c.Pos(), ruleDirective, "Malformed %s directive: the package name must be an identifier",
+							prefixDirective[2:])
+					}
+				case found != nil && value != prefix:
+					if config.enabled(ruleDirective) {
+						reportf(pass, c.Pos(), ruleDirective, "Conflicting %s directive: %q is already set at %s",
+							prefixDirective[2:], prefix, pass.Fset.Position(found.Pos()))
+					}
+				default:
+					found, prefix = c, value
+				}
+			}
+		}
+	}
+	return prefix, found != nil
+}
+
+// packagePrefix returns the package component set by a prefix directive of the package.
+func packagePrefix(pkg *types.Package) (string, bool) {
+	state := stateOf(pkg)
+	if state == nil || !state.hasPrefix {
+		return "", false
+	}
+	return state.prefix, true
+}
+
+// typeDocs returns the doc comments of the type declarations of a file mapped to the declared types.
+// The doc comment of a single type declaration without parentheses belongs to the declaration.
+func typeDocs(file *ast.File) map[*ast.CommentGroup]*ast.TypeSpec {
+	docs := make(map[*ast.CommentGroup]*ast.TypeSpec)
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			switch {
+			case typeSpec.Doc != nil:
+				docs[typeSpec.Doc] = typeSpec
+			case genDecl.Doc != nil && !genDecl.Lparen.IsValid():
+				docs[genDecl.Doc] = typeSpec
+			}
+		}
+	}
+	return docs
+}
+
+// typeDirectives applies the directives in the doc comments of types to their methods. A prefix directive sets
+// the receiver name prefixes of the methods use instead of an awkward type name, and an exempt directive
+// exempts the methods from the checks:
+//
+//	//errchain:prefix Factory
+//	type DefaultHTTPSRoundTripperFactoryImpl struct{}
+//
+//	//errchain:exempt
+//	type legacyClient struct{}
+//
+// Malformed and conflicting directives are reported.
+func typeDirectives(pass *analysis.Pass, index *packageIndex) {
+	for _, file := range pass.Files {
+		for group, typeSpec := range typeDocs(file) {
+			t := index.typ(typeSpec.Name.Name)
+			for _, c := range group.List {
+				switch {
+				case c.Text == exemptDirective || strings.HasPrefix(c.Text, exemptDirective+" "):
+					t.exempt = true
+				case c.Text == prefixDirective || strings.HasPrefix(c.Text, prefixDirective+" "):
+					var value string
+					if fields := strings.Fields(strings.TrimPrefix(c.Text, prefixDirective)); len(fields) > 0 {
+						value = fields[0]
+					}
+					switch {
+					case !token.IsIdentifier(value):
+						if config.enabled(ruleDirective) {
+							reportf(pass, c.Pos(), ruleDirective, "Malformed %s directive of type %s: the receiver name must be an identifier",
+								prefixDirective[2:], typeSpec.Name.Name)
+						}
+					case t.alias != "" && t.alias != value:
+						if config.enabled(ruleDirective) {
+							reportf(pass, c.Pos(), ruleDirective, "Conflicting %s directive of type %s: %q is already set",
+								prefixDirective[2:], typeSpec.Name.Name, t.alias)
+						}
+					default:
+						t.alias = value
+					}
+				}
+			}
+		}
+	}
+	for _, fn := range index.funcs {
+		if t, ok := index.types[fn.recv]; ok && fn.recv != "" {
+			fn.recvAlias, fn.exempt = t.alias, t.exempt
+		}
+	}
+}
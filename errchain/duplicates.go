@@ -0,0 +1,110 @@
+package errchain
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var ruleDuplicateMessage = registerRule(rule{
+	code:             "duplicate-message",
+	doc:              "the same error message literal isn't constructed at several sites of a package",
+	enabledByDefault: func(c *configuration) bool { return c.duplicateMessages },
+	flags:            []string{"duplicate-messages"},
+	example: func(fn, _ string) (string, string) {
+		return `errors.New("not found") // in two functions`, `errors.New("` + fn + `not found")`
+	},
+})
+
+// A messageSite is an error constructor call with a literal message.
+type messageSite struct {
+	call   *ast.CallExpr
+	prefix string // canonical prefix of the enclosing function, empty in package scope
+}
+
+// checkDuplicateMessages reports error message literals constructed at two or more sites of a package:
+// an error chain with such a message doesn't tell where the error comes from. Every site is reported
+// with the other ones as related positions. Test files and skipped generated files are ignored.
+func checkDuplicateMessages(pass *analysis.Pass, index *packageIndex) {
+	if !config.enabled(ruleDuplicateMessage) {
+		return
+	}
+	sites := make(map[string][]messageSite)
+	var messages []string
+	collect := func(node ast.Node, prefix string) {
+		ast.Inspect(node, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			ctor, ok := constructorOf(pass, call)
+			if !ok {
+				return true
+			}
+			lit := messageLiteral(call, ctor)
+			if lit == nil {
+				return true
+			}
+			msg, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				return true
+			}
+			if len(sites[msg]) == 0 {
+				messages = append(messages, msg)
+			}
+			sites[msg] = append(sites[msg], messageSite{call: call, prefix: prefix})
+			return true
+		})
+	}
+	for _, file := range pass.Files {
+		if isTest(pass, file) || isSkippedGenerated(pass, file) || isSkippedMock(pass, file) {
+			continue
+		}
+		for _, decl := range file.Decls {
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok && index.funcs[funcDecl] != nil && funcDecl.Name.Name != "init" {
+				collect(decl, canonicalPrefix(pass.Pkg, index.funcs[funcDecl])+string(config.separator))
+			} else {
+				collect(decl, "")
+			}
+		}
+	}
+
+	for _, msg := range messages {
+		group := sites[msg]
+		if len(group) < 2 {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool { return group[i].call.Pos() < group[j].call.Pos() })
+		for _, site := range group {
+			if !reportable(pass, site.call.Pos()) {
+				continue
+			}
+			text := fmt.Sprintf("Error message %q is constructed at %d sites, which makes error chains ambiguous", msg, len(group))
+			if site.prefix != "" && !strings.HasPrefix(msg, site.prefix) {
+				text += fmt.Sprintf("; start it with the function prefix: %q", site.prefix+msg)
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:      site.call.Pos(),
+				Category: ruleDuplicateMessage,
+				Message:  text,
+				Related:  relatedSites(group, site.call.Pos()),
+			})
+		}
+	}
+}
+
+// relatedSites returns the positions of the sites of a group except one.
+func relatedSites(group []messageSite, except token.Pos) []analysis.RelatedInformation {
+	var related []analysis.RelatedInformation
+	for _, site := range group {
+		if site.call.Pos() != except {
+			related = append(related, analysis.RelatedInformation{Pos: site.call.Pos(), Message: "also constructed here"})
+		}
+	}
+	return related
+}
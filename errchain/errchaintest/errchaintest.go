@@ -0,0 +1,70 @@
+// Package errchaintest runs the errchain analyzer on test data with a given configuration, so that teams
+// with their own conventions, e.g. custom error constructors, can keep golden tests of them:
+//
+//	func TestConventions(t *testing.T) {
+//		cfg := errchaintest.Config{"factory": "func", "pkg-component": "optional"}
+//		errchaintest.Run(t, cfg, analysistest.TestData(), "./billing/...")
+//	}
+//
+// The expectations are written as for analysistest: // want "regexp" comments for diagnostics
+// and .golden files for suggested fixes.
+package errchaintest
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/iimos/go-check-err-chains/errchain"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+// Config is a configuration of the analyzer as flag names mapped to their values,
+// e.g. {"separator": " - "}. The flags are listed by "errchain -help".
+type Config map[string]string
+
+// Run configures errchain.Analyzer for the duration of the test and runs analysistest.Run on the packages
+// of a test data directory matching the patterns, "./..." if there are none. The analyzer is configured
+// globally, so tests using it must not run in parallel.
+func Run(t *testing.T, cfg Config, dir string, patterns ...string) []*analysistest.Result {
+	t.Helper()
+	configure(t, cfg)
+	return analysistest.Run(t, dir, errchain.Analyzer, defaultPatterns(patterns)...)
+}
+
+// RunWithSuggestedFixes is like Run, but also checks that the suggested fixes applied to the files
+// give their .golden files.
+func RunWithSuggestedFixes(t *testing.T, cfg Config, dir string, patterns ...string) []*analysistest.Result {
+	t.Helper()
+	configure(t, cfg)
+	return analysistest.RunWithSuggestedFixes(t, dir, errchain.Analyzer, defaultPatterns(patterns)...)
+}
+
+// configure sets the flags of the analyzer and restores them when the test ends.
+func configure(t *testing.T, cfg Config) {
+	t.Helper()
+	names := make([]string, 0, len(cfg))
+	for name := range cfg {
+		names = append(names, name)
+	}
+	// flags may depend on each other, so they are set in the same order every time
+	sort.Strings(names)
+
+	for _, name := range names {
+		f := errchain.Analyzer.Flags.Lookup(name)
+		if f == nil {
+			t.Fatalf("errchaintest: unknown flag %q", name)
+		}
+		prev := f.Value.String()
+		if err := f.Value.Set(cfg[name]); err != nil {
+			t.Fatalf("errchaintest: -%s=%s: %v", name, cfg[name], err)
+		}
+		t.Cleanup(func() { _ = f.Value.Set(prev) })
+	}
+}
+
+func defaultPatterns(patterns []string) []string {
+	if len(patterns) == 0 {
+		return []string{"./..."}
+	}
+	return patterns
+}
@@ -0,0 +1,27 @@
+package errchaintest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/iimos/go-check-err-chains/errchain"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestRun(t *testing.T) {
+	testdata, err := filepath.Abs(filepath.Join("..", "testdata"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("separator", func(t *testing.T) {
+		RunWithSuggestedFixes(t, Config{"separator": " - "}, testdata, "./separator")
+	})
+	if got := errchain.Analyzer.Flags.Lookup("separator").Value.String(); got != ": " {
+		t.Errorf("separator is not restored: %q", got)
+	}
+
+	t.Run("default", func(t *testing.T) {
+		Run(t, nil, analysistest.TestData(), "./retry")
+	})
+}
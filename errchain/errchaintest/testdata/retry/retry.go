@@ -0,0 +1,14 @@
+package retry // want package:`PrefixNamespace\(retry\)`
+
+import "errors"
+
+func Do(attempts int) error { // want Do:"PrefixedErrorFunc"
+	if attempts <= 0 {
+		return errors.New("retry.Do: no attempts")
+	}
+	return nil
+}
+
+func Wait() error {
+	return errors.New("not ready") // want `Consider starting message with one of the following strings: "retry: ", "retry\.Wait: "`
+}
@@ -6,22 +6,108 @@ import (
 	"go/constant"
 	"go/token"
 	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/go/ast/inspector"
 	"honnef.co/go/tools/analysis/code"
 )
 
+// Analyzer is the errchain analyzer. It needs no flags to run and doesn't write to stdout. All the settings
+// are exposed through Analyzer.Flags and shared by every package the process analyzes, so packages checked
+// with different settings need separate processes. Some checks read the other files of the module of a package,
+// e.g. to tell which package a prefix names, which incremental drivers like gopls don't track as dependencies.
 var Analyzer = &analysis.Analyzer{
-	Name:     "errchain",
-	Doc:      "Checks that error chains contain information about place where problem occurred.",
-	Run:      run,
-	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Name:       "errchain",
+	Doc:        "Checks that error chains contain information about place where problem occurred.",
+	Run:        run,
+	Requires:   []*analysis.Analyzer{inspect.Analyzer},
+	ResultType: reflect.TypeOf((*packageIndex)(nil)),
+	FactTypes:  []analysis.Fact{new(PrefixedErrorFunc), new(PrefixNamespace), new(PrefixChain)},
 }
 
+var (
+	rulePrefix = registerRule(rule{
+		code:             "prefix",
+		doc:              `error messages of exported functions start with the location prefix, e.g. "pkg.Func: "`,
+		enabledByDefault: always,
+		flags:            []string{"separator", "dialect", "casing", "pkg-component", "recv-component", "func-component", "min-prefix-components"},
+		example: func(fn, _ string) (string, string) {
+			return `errors.New("not found")`, `errors.New("` + fn + `not found")`
+		},
+	})
+	ruleDynamicPrefix = registerRule(rule{
+		code:             "dynamic-prefix",
+		doc:              `messages don't start with a value known only at runtime, e.g. fmt.Errorf("%q: not found", name)`,
+		enabledByDefault: always,
+		example: func(fn, _ string) (string, string) {
+			return `fmt.Errorf("%q: not found", key)`, `fmt.Errorf("` + fn + `%q: not found", key)`
+		},
+	})
+	rulePackageLevel = registerRule(rule{
+		code:             "package-level",
+		doc:              `error messages in init functions and package-level variables start with "pkg: "`,
+		enabledByDefault: func(c *configuration) bool { return c.packageLevel },
+		flags:            []string{"package-level"},
+		example: func(_, pkg string) (string, string) {
+			return `var ErrNotFound = errors.New("not found")`, `var ErrNotFound = errors.New("` + pkg + `not found")`
+		},
+	})
+	ruleLazyInit = registerRule(rule{
+		code:             "lazy-init",
+		doc:              `error messages of package-level sync.OnceFunc, sync.OnceValue and sync.OnceValues start with "pkg: "`,
+		enabledByDefault: always,
+		example: func(_, pkg string) (string, string) {
+			return `var load = sync.OnceValue(func() error { return errors.New("no config") })`,
+				`var load = sync.OnceValue(func() error { return errors.New("` + pkg + `no config") })`
+		},
+	})
+	ruleTableFuncs = registerRule(rule{
+		code:             "table-funcs",
+		doc:              `error messages of function literals in package-level maps, slices and structs, e.g. tables of validators, start with "pkg: "`,
+		enabledByDefault: always,
+		example: func(_, pkg string) (string, string) {
+			return `var validators = map[string]func(string) error{"email": func(v string) error { return errors.New("bad email") }}`,
+				`var validators = map[string]func(string) error{"email": func(v string) error { return errors.New("` + pkg + `bad email") }}`
+		},
+	})
+	ruleErrorLast = registerRule(rule{
+		code:             "error-last",
+		doc:              "exported functions return an error as the last result",
+		enabledByDefault: func(c *configuration) bool { return c.errorLast },
+		flags:            []string{"error-last"},
+		example: func(string, string) (string, string) {
+			return "func Get(key string) (error, []byte)", "func Get(key string) ([]byte, error)"
+		},
+	})
+	ruleStaleFile = registerRule(rule{
+		code:             "stale-file",
+		doc:              `file names of file:line prefixes like "user.go:42: " match the actual file`,
+		enabledByDefault: func(c *configuration) bool { return c.dialect != dialectLocation },
+		flags:            []string{"dialect"},
+		example: func(string, string) (string, string) {
+			// in user.go
+			return `errors.New("users.go:42: not found")`, `errors.New("user.go:42: not found")`
+		},
+	})
+	rulePassThrough = registerRule(rule{
+		code:             "pass-through",
+		doc:              `errors aren't wrapped without any text of their own, e.g. fmt.Errorf("%w", err)`,
+		enabledByDefault: func(c *configuration) bool { return c.passThrough },
+		flags:            []string{"pass-through"},
+		example: func(fn, _ string) (string, string) {
+			return `fmt.Errorf("%w", err)`, `fmt.Errorf("` + fn + `%w", err)`
+		},
+	})
+)
+
 const diagnosticMessage = "Error message must point to the place where it had happened"
 const helpURL = "https://bit.ly/err-chains"
 
@@ -36,177 +122,878 @@ func run(pass *analysis.Pass) (interface{}, error) {
 	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
 	nodeFilter := []ast.Node{(*ast.File)(nil)}
 
+	index := newPackageIndex(pass.Files)
 	if code.IsMainLike(pass) {
-		return nil, nil
+		// the test main package synthesized by go test isn't user code
+		if len(pass.Files) > 0 && !strings.HasSuffix(pass.Pkg.Path(), ".test") {
+			reportSkipped(pass, pass.Files[0].Package, skipMainPackage, pass.Pkg.Path())
+		}
+		return index, nil
+	}
+	if reason := skippedTree(pass); reason != "" {
+		reportSkipped(pass, pass.Files[0].Package, reason, pass.Pkg.Path())
+		return index, nil
+	}
+	if len(pass.Files) > 0 && isMockPackage(pass) {
+		reportSkipped(pass, pass.Files[0].Package, skipMockPackage, pass.Pkg.Path())
+		return index, nil
 	}
+	if isDataPackage(pass) {
+		return runDataPackage(pass, index)
+	}
+	index.funcValues = funcValues(pass, index)
+	index.registered = registeredFuncs(pass)
+	index.lazy = lazyFuncs(pass)
+	index.tables = tableFuncs(pass)
+	promoteMethods(pass, index)
+	pointerMethods(pass, index)
+	aliasReceivers(pass, index)
+	typeDirectives(pass, index)
+	delegateHelpers(pass, index)
+	defer loadPackageState(pass)()
+	index.prefixed = exportPrefixedErrorFacts(pass, index)
+	index.escaping = escapingHelpers(pass, index)
+	handleChains(pass)
+	checkNamespace(pass)
+	checkDuplicateMessages(pass, index)
 
 	insp.Preorder(nodeFilter, func(node ast.Node) {
 		if file, ok := node.(*ast.File); ok {
-			if isGenerated(file) || isTest(pass, file) {
+			if isTest(pass, file) {
+				reportSkipped(pass, file.Package, skipTestFile, filepath.Base(pass.Fset.Position(file.Package).Filename))
+				return
+			}
+			if isSkippedGenerated(pass, file) || isSkippedMock(pass, file) {
 				return
 			}
 			for _, decl := range file.Decls {
-				if funcDecl, ok := decl.(*ast.FuncDecl); ok {
-					handleFuncDecl(pass, funcDecl)
+				switch decl := decl.(type) {
+				case *ast.FuncDecl:
+					handleFuncDecl(pass, index, decl)
+				case *ast.GenDecl:
+					handleGenDecl(pass, index, decl)
 				}
 			}
+			handleRegisteredFuncs(pass, index, file)
+			handleWrappers(pass, file)
+			handleUserMessages(pass, file)
 		}
 	})
+	reportCoverage(pass)
+	reportStyles(pass)
 
-	return nil, nil
+	return index, nil
 }
 
-func handleFuncDecl(pass *analysis.Pass, funcDecl *ast.FuncDecl) {
+func handleFuncDecl(pass *analysis.Pass, index *packageIndex, funcDecl *ast.FuncDecl) {
 	if funcDecl.Name == nil || funcDecl.Body == nil {
 		return
 	}
+	handlePanics(pass, index, funcDecl)
+
+	if funcDecl.Name.Name == "init" && funcDecl.Recv == nil {
+		if config.enabled(rulePackageLevel) {
+			handlePackageScope(pass, index, &funcInfo{}, funcDecl.Body)
+		}
+		return
+	}
 
-	if !ast.IsExported(funcDecl.Name.Name) || !isReturnsError(funcDecl) {
+	if fn := index.funcs[funcDecl]; fn.exempt {
+		reportSkipped(pass, funcDecl.Name.Pos(), skipExemptType, fn.recv+"."+fn.name)
 		return
 	}
 
+	if !ast.IsExported(funcDecl.Name.Name) && !index.funcValues[funcDecl] {
+		if isReturnsError(funcDecl) && !handleHelper(pass, index, funcDecl) {
+			reportSkipped(pass, funcDecl.Name.Pos(), skipUnexported, funcDecl.Name.Name)
+		}
+		return
+	}
+
+	if config.enabled(ruleErrorLast) {
+		if errIndex, last := errorResultIndex(funcDecl); errIndex >= 0 && errIndex != last {
+			reportf(pass, funcDecl.Type.Results.Pos(), ruleErrorLast, "Error should be the last result")
+		}
+	}
+
+	handleResponses(pass, index.funcs[funcDecl])
+
+	if !isReturnsError(funcDecl) {
+		handleFieldErrors(pass, index, funcDecl)
+		return
+	}
+
+	rules, ok := funcRules(pass.Pkg, funcDecl)
+	if !ok {
+		return
+	}
+
+	handleFuncBody(pass, index, index.funcs[funcDecl], rules, funcDecl.Body)
+	handlePropagation(pass, index, index.funcs[funcDecl])
+	handleContext(pass, index.funcs[funcDecl], rules)
+	handleWrapContext(pass, index.funcs[funcDecl], rules)
+	handlePointerForm(pass, index.funcs[funcDecl], rules)
+	handleOpLiterals(pass, funcDecl)
+	handleDuplicateCauses(pass, funcDecl)
+	handleCanonical(pass, index.funcs[funcDecl], rules)
+}
+
+// handleFieldErrors checks error messages of a method which doesn't return an error itself but stores errors
+// in fields of its receiver, e.g. a builder method setting b.err which is returned later by Build.
+func handleFieldErrors(pass *analysis.Pass, index *packageIndex, funcDecl *ast.FuncDecl) {
+	fn := index.funcs[funcDecl]
+	if fn == nil || fn.recv == "" || len(funcDecl.Recv.List[0].Names) == 0 {
+		return
+	}
+	recv := pass.TypesInfo.Defs[funcDecl.Recv.List[0].Names[0]]
+	if recv == nil {
+		return
+	}
+
+	rules := config.componentRules(pass.Pkg)
+	var findings []*finding
 	ast.Inspect(funcDecl.Body, func(node ast.Node) bool {
-		handleFuncBody(pass, funcDecl, node)
+		assign, ok := node.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != len(assign.Rhs) {
+			return true
+		}
+		for i, lhs := range assign.Lhs {
+			if !isFieldOf(pass, recv, lhs) {
+				continue
+			}
+			// b.err = multierr.Append(b.err, errors.New("msg")) stores the constructed error as well
+			for _, expr := range errorComponents(pass, assign.Rhs[i]) {
+				if f := inspectNode(pass, index, fn, rules, astutil.Unparen(expr)); f != nil {
+					findings = append(findings, f)
+				}
+			}
+		}
 		return true
 	})
+	reportFindings(pass, fn, funcDecl.Body, findings)
 }
 
-// errorPrefixes returns a set of possible prefixes a given function's error message can start with.
-func errorPrefixes(pkg *types.Package, fn *ast.FuncDecl) []string {
-	if fn.Name == nil {
-		return nil
+// isFieldOf tells whether an expression is a field of a variable, possibly a nested one: b.err or b.state.err.
+func isFieldOf(pass *analysis.Pass, obj types.Object, expr ast.Expr) bool {
+	sel, ok := astutil.Unparen(expr).(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	for {
+		switch x := astutil.Unparen(sel.X).(type) {
+		case *ast.Ident:
+			return pass.TypesInfo.Uses[x] == obj
+		case *ast.SelectorExpr:
+			sel = x
+		default:
+			return false
+		}
 	}
+}
 
-	prefixes := make([]string, 0, 3)
-	prefixes = append(prefixes, pkg.Name()+": ")
+// handleRegisteredFuncs checks error messages of function literals passed to registration functions. Such a literal
+// is the real exported behavior of the package, so its messages have to start with the package prefix
+// regardless of the function the registration happens in.
+func handleRegisteredFuncs(pass *analysis.Pass, index *packageIndex, file *ast.File) {
+	if len(index.registered) == 0 {
+		return
+	}
+	ast.Inspect(file, func(node ast.Node) bool {
+		if lit, ok := node.(*ast.FuncLit); ok && index.registered[lit] {
+			// nested registered literals are skipped by handleFuncBody and found by this walk
+			handlePackageScope(pass, index, &funcInfo{}, lit.Body)
+		}
+		return true
+	})
+}
 
-	if fn.Recv == nil || len(fn.Recv.List) == 0 {
-		funcPref := pkg.Name() + "." + fn.Name.Name + ": "
-		return append(prefixes, funcPref)
+// handleGenDecl checks error messages in initial values of package-level variables,
+// including bodies of function literals assigned to them.
+func handleGenDecl(pass *analysis.Pass, index *packageIndex, genDecl *ast.GenDecl) {
+	if genDecl.Tok != token.VAR {
+		return
 	}
+	for _, spec := range genDecl.Specs {
+		valueSpec, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		for i, value := range valueSpec.Values {
+			// A variable name may be used as a function name: "pkg.Handler: ".
+			scope := &funcInfo{}
+			if len(valueSpec.Names) == len(valueSpec.Values) && valueSpec.Names[i].Name != "_" {
+				scope.name = valueSpec.Names[i].Name
+			}
+			if config.enabled(rulePackageLevel) {
+				handlePackageScope(pass, index, scope, value)
+			}
+			if config.enabled(ruleLazyInit) {
+				handleLazyFuncs(pass, index, scope, value)
+			}
+			if config.enabled(ruleTableFuncs) {
+				handleTableFuncs(pass, index, scope, value)
+			}
+		}
+	}
+}
 
-	recvType := fn.Recv.List[0].Type
+// handleLazyFuncs checks error messages of function literals passed to sync.OnceFunc and the like
+// in an initial value of a package-level variable. Such a literal runs on the first call of the variable,
+// so its errors escape through it and have to start with the package name like other package-level messages.
+func handleLazyFuncs(pass *analysis.Pass, index *packageIndex, scope *funcInfo, value ast.Expr) {
+	ast.Inspect(value, func(node ast.Node) bool {
+		if lit, ok := node.(*ast.FuncLit); ok && index.lazy[lit] {
+			// nested lazy literals are skipped by handleFuncBody and found by this walk
+			handlePackageScope(pass, index, scope, lit.Body)
+		}
+		return true
+	})
+}
 
-	switch recv := recvType.(type) {
-	case *ast.StarExpr:
-		if ident, ok := recv.X.(*ast.Ident); ok {
-			pref1 := fmt.Sprintf("%s.%s.%s: ", pkg.Name(), ident.Name, fn.Name.Name)
-			pref2 := fmt.Sprintf("%s.(*%s).%s: ", pkg.Name(), ident.Name, fn.Name.Name)
-			pref3 := fmt.Sprintf("%s.%s: ", pkg.Name(), ident.Name)
-			prefixes = append(prefixes, pref1, pref2, pref3)
-		} else {
-			return nil
+// handleTableFuncs checks error messages of function literals stored in a composite literal which is an initial value
+// of a package-level variable, e.g.
+//
+//	var validators = map[string]func(string) error{"email": func(v string) error { ... }}
+//
+// Such a table is looked up by the exported functions of the package, so the errors of its literals escape
+// through them and have to start with the package name like other package-level messages.
+func handleTableFuncs(pass *analysis.Pass, index *packageIndex, scope *funcInfo, value ast.Expr) {
+	ast.Inspect(value, func(node ast.Node) bool {
+		if lit, ok := node.(*ast.FuncLit); ok && index.tables[lit] {
+			// table literals are skipped by handleFuncBody and found by this walk
+			handlePackageScope(pass, index, scope, lit.Body)
 		}
-	case *ast.Ident:
-		pref1 := fmt.Sprintf("%s.%s.%s: ", pkg.Name(), recv.Name, fn.Name.Name)
-		pref2 := fmt.Sprintf("%s.%s: ", pkg.Name(), recv.Name)
-		prefixes = append(prefixes, pref1, pref2)
-	default:
+		return true
+	})
+}
+
+// handlePackageScope checks error messages constructed outside of functions: in init functions and
+// package-level declarations. Such messages only have to start with the package name.
+func handlePackageScope(pass *analysis.Pass, index *packageIndex, scope *funcInfo, node ast.Node) {
+	rules := componentRules{pkg: componentRequired, recv: componentOptional, fn: componentOptional}
+	handleFuncBody(pass, index, scope, rules, node)
+}
+
+// funcRules returns the component rules error prefixes of a function must satisfy.
+// It returns false if error messages of the function are not checked at all.
+func funcRules(pkg *types.Package, funcDecl *ast.FuncDecl) (componentRules, bool) {
+	rules := config.componentRules(pkg)
+	if isErrorFactory(funcDecl) {
+		switch config.factoryPolicy {
+		case factorySkip:
+			return rules, false
+		case factoryPkg:
+			rules = componentRules{pkg: componentRequired, recv: componentOptional, fn: componentOptional}
+		case factoryFunc:
+			rules = componentRules{pkg: componentRequired, recv: componentOptional, fn: componentRequired}
+		}
+	}
+	return rules, true
+}
+
+// errorPrefixes returns a set of possible prefixes a given function's error message can start with.
+func errorPrefixes(pkg *types.Package, fn *funcInfo, rules componentRules) []string {
+	names := packageNames(pkg)
+	if fn.name == "" {
+		// package scope
+		prefixes := make([]string, 0, len(names))
+		for _, name := range names {
+			prefixes = append(prefixes, name+string(config.separator))
+		}
+		return prefixes
+	}
+	if fn.isMethod && fn.recv == "" {
 		return nil
 	}
+
+	var prefixes []string
+	for _, name := range names {
+		for _, recv := range fn.recvNames() {
+			prefixes = append(prefixes, locationPrefixes(rules, name, recv, fn.pointerForm() && fn.isOwnRecv(recv), fn.name)...)
+		}
+	}
+	if !rules.pkg.required() {
+		for _, recv := range fn.recvNames() {
+			prefixes = append(prefixes, locationPrefixes(rules, "", recv, fn.pointerForm() && fn.isOwnRecv(recv), fn.name)...)
+		}
+	}
+	for _, caller := range fn.callers {
+		prefixes = append(prefixes, errorPrefixes(pkg, caller, rules)...)
+	}
+	return dedupe(prefixes)
+}
+
+// dedupe removes repeated strings keeping the order.
+func dedupe(list []string) []string {
+	seen := make(map[string]bool, len(list))
+	unique := list[:0]
+	for _, s := range list {
+		if !seen[s] {
+			seen[s] = true
+			unique = append(unique, s)
+		}
+	}
+	return unique
+}
+
+// locationPrefixes returns prefixes built from the given location components which satisfy the rules.
+// An empty pkg makes package-less prefixes like "Struct.Method: ".
+func locationPrefixes(rules componentRules, pkg, recv string, isRecvPtr bool, fn string) []string {
+	join := func(parts ...string) string {
+		if parts[0] == "" {
+			parts = parts[1:]
+		}
+		return strings.Join(parts, ".") + string(config.separator)
+	}
+
+	prefixes := make([]string, 0, 4)
+	if pkg != "" && !rules.fn.required() && (recv == "" || !rules.recv.required()) {
+		prefixes = append(prefixes, join(pkg))
+	}
+
+	if recv == "" {
+		return append(prefixes, join(pkg, fn))
+	}
+
+	prefixes = append(prefixes, join(pkg, recv, fn))
+	if isRecvPtr {
+		prefixes = append(prefixes, join(pkg, "(*"+recv+")", fn))
+	}
+	if !rules.fn.required() {
+		prefixes = append(prefixes, join(pkg, recv))
+	}
 	return prefixes
 }
 
-// isReturnsError tells whether an ast.FuncDecl returns an error as a last result.
+// isReturnsError tells whether an ast.FuncDecl returns an error or a collection of errors as a last result,
+// e.g. a validator returning []error or map[string]error. If config.anyErrorPosition is set, the error
+// may be at any position.
 func isReturnsError(funcDecl *ast.FuncDecl) bool {
-	if funcDecl.Type == nil || funcDecl.Type.Results == nil {
+	errIndex, last := resultIndex(funcDecl, isErrorOrCollection)
+	if errIndex < 0 {
 		return false
 	}
+	return errIndex == last || config.anyErrorPosition
+}
+
+// errorResultIndex returns an index of the last error result of an ast.FuncDecl and an index of the last result.
+// The error index is -1 if the function doesn't return an error.
+func errorResultIndex(funcDecl *ast.FuncDecl) (errIndex, last int) {
+	return resultIndex(funcDecl, isErrorIdent)
+}
+
+// resultIndex returns an index of the last result of an ast.FuncDecl with a type matching a predicate
+// and an index of the last result. The index of the match is -1 if there is no such result.
+func resultIndex(funcDecl *ast.FuncDecl, match func(ast.Expr) bool) (matchIndex, last int) {
+	if funcDecl.Type == nil || funcDecl.Type.Results == nil {
+		return -1, -1
+	}
 
-	list := funcDecl.Type.Results.List
-	if len(list) == 0 {
+	index, matchIndex := 0, -1
+	for _, field := range funcDecl.Type.Results.List {
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		index += n
+		if match(field.Type) {
+			matchIndex = index - 1
+		}
+	}
+	return matchIndex, index - 1
+}
+
+func isErrorIdent(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == "error"
+}
+
+// isErrorOrCollection tells whether a type expression is error or a slice, an array or a map of errors.
+func isErrorOrCollection(expr ast.Expr) bool {
+	switch expr := expr.(type) {
+	case *ast.ArrayType:
+		return isErrorIdent(expr.Elt)
+	case *ast.MapType:
+		return isErrorIdent(expr.Value)
+	}
+	return isErrorIdent(expr)
+}
+
+// isErrorFactory tells whether an ast.FuncDecl is an error factory like "func ErrTooBig(limit int) error":
+// its name starts with "Err" or "NewErr" and its only result is an error.
+func isErrorFactory(funcDecl *ast.FuncDecl) bool {
+	if funcDecl.Name == nil || funcDecl.Type.Results == nil {
+		return false
+	}
+	if errIndex, last := errorResultIndex(funcDecl); errIndex != 0 || last != 0 {
 		return false
 	}
 
-	lastType := list[len(list)-1].Type
-	if ident, ok := lastType.(*ast.Ident); ok {
-		if ident.Name == "error" {
+	name := funcDecl.Name.Name
+	for _, prefix := range []string{"Err", "NewErr"} {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		rest := name[len(prefix):]
+		if rest == "" || ast.IsExported(rest) {
 			return true
 		}
 	}
 	return false
 }
 
-func handleFuncBody(pass *analysis.Pass, parentFunc *ast.FuncDecl, node ast.Node) {
+func handleFuncBody(pass *analysis.Pass, index *packageIndex, parentFunc *funcInfo, rules componentRules, body ast.Node) {
+	var findings []*finding
+	callbacks := make(map[*ast.FuncLit]bool)
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.CallExpr:
+			if config.callbackPolicy == callbackPkg {
+				for _, arg := range node.Args {
+					if lit, ok := astutil.Unparen(arg).(*ast.FuncLit); ok {
+						callbacks[lit] = true
+					}
+				}
+			}
+		case *ast.FuncLit:
+			if index.registered[node] {
+				// checked by handleRegisteredFuncs
+				return false
+			}
+			if index.lazy[node] {
+				// checked by handleLazyFuncs
+				return false
+			}
+			if index.tables[node] {
+				// checked by handleTableFuncs
+				return false
+			}
+			if callbacks[node] {
+				// A callback is not a part of the enclosing function, e.g. it's a handler passed to a router.
+				handlePackageScope(pass, index, &funcInfo{}, node.Body)
+				return false
+			}
+		}
+		if f := inspectNode(pass, index, parentFunc, rules, node); f != nil {
+			findings = append(findings, f)
+		}
+		return true
+	})
+
+	reportFindings(pass, parentFunc, body, findings)
+}
+
+// reportFindings reports the findings of a function body along with the fixes suggested for them.
+// A constructor call may be reached by several walks, e.g. a nested function literal, so every call is reported once.
+func reportFindings(pass *analysis.Pass, fn *funcInfo, body ast.Node, findings []*finding) {
+	if state := stateOf(pass.Pkg); state != nil {
+		unique := findings[:0:0]
+		for _, f := range findings {
+			if !state.reported[f.call.Pos()] {
+				state.reported[f.call.Pos()] = true
+				unique = append(unique, f)
+			}
+		}
+		findings = unique
+	}
+	suggestFixes(pass, fn, body, findings)
+	for _, f := range findings {
+		f.diag.Category = f.rule()
+		pass.Report(f.diag)
+	}
+}
+
+// inspectNode checks a node of a function body and returns a finding if it is an error constructor call
+// with a wrong message reported by an enabled rule, see reportable.
+func inspectNode(pass *analysis.Pass, index *packageIndex, parentFunc *funcInfo, rules componentRules, node ast.Node) *finding {
 	call, ok := node.(*ast.CallExpr)
 	if !ok {
-		return
+		return nil
 	}
 
-	if len(call.Args) == 0 {
-		return
+	check, ok := checkCall(pass, parentFunc, rules, call)
+	if !ok {
+		if isSelfLocatingCall(pass, call) {
+			recordPath(pass, parentFunc, call, true)
+		}
+		reportSkippedCall(pass, call)
+		return nil
+	}
+	recordPath(pass, parentFunc, call, check.isPrefixed())
+	recordStyle(pass, parentFunc, rules, call, check)
+	if check.err == nil {
+		return nil
 	}
 
-	callName := code.CallName(pass, call)
-	switch callName {
-	case "errors.New", "fmt.Errorf":
-		format, ok := constantValueString(pass, call.Args[0])
+	err := check.err
+	if err.errType == errMethodNotFound && index.isSibling(parentFunc, err.parsedPrefix) {
+		// most likely the message is copied from another method of the receiver
+		err.errType = errSiblingMethod
+	}
+	if isDebug() {
+		// stdout belongs to the driver, e.g. it carries -json output or the gopls protocol
+		fmt.Fprintf(os.Stderr, "[DEBUG] errchain: %s: %s(%q); err=%+v\n",
+			pass.Fset.Position(call.Pos()), check.ctor.name, check.message, err)
+	}
+	f := &finding{call: call, check: check}
+	if !config.enabled(f.rule()) || !reportable(pass, call.Pos()) {
+		// the recommendations are costly to build for nothing, e.g. in the legacy files outside -report-files
+		return nil
+	}
+	var msg string
+	switch err.errType {
+	case errNoPrefix:
+		recoms := generatePrefixRecomendations(pass, parentFunc, rules, call.Pos())
+		msg = diagnosticMessage + ": " + recoms
+	case errFuncRequired, errRecvRequired:
+		recoms := generatePrefixRecomendations(pass, parentFunc, rules, call.Pos())
+		msg = diagnosticMessage + ": " + err.errType.Error() + ". " + recoms
+	case errInvalidSyntax:
+		msg = diagnosticMessage + ": " + err.errType.Error()
+		if err.expect != "" {
+			msg += ", expected " + strconv.Quote(err.expect)
+		}
+	case errHiddenChar:
+		recoms := generatePrefixRecomendations(pass, parentFunc, rules, call.Pos())
+		msg = fmt.Sprintf("%s: %s %U. %s", diagnosticMessage, err.errType, []rune(err.got)[0], recoms)
+	case errFormatVerb:
+		msg = fmt.Sprintf("Format verb %s does not match argument type %s", err.got, err.expect)
+	case errFormatArgs:
+		msg = "Format verbs do not match arguments: " + err.got
+	case errPassThrough:
+		msg = diagnosticMessage + ": " + err.errType.Error() + ", consider " +
+			strconv.Quote(canonicalPrefix(pass.Pkg, parentFunc)+string(config.separator)+check.message)
+	case errDynamicPrefix:
+		msg = diagnosticMessage + ": " + err.errType.Error() + ", move the value after the prefix: " +
+			strconv.Quote(canonicalPrefix(pass.Pkg, parentFunc)+string(config.separator)+check.message)
+	case errStaleFile, errUnqualifiedPackage, errPathMismatch, errComponentOrder, errPackageMissing:
+		msg = diagnosticMessage + ": " + err.errType.Error() + ", expected " + strconv.Quote(err.expect)
+	case errPackageMismatch:
+		if dir, ok := otherModulePackage(pass, err.got); ok {
+			msg = fmt.Sprintf("%s: prefix refers to package %s (%s), but this code is in %s",
+				diagnosticMessage, err.got, dir, pass.Pkg.Name())
+			break
+		}
+		msg = diagnosticMessage + ": " + err.errType.Error() + ", expected " + expectedPackage(pass.Pkg)
+	case errSiblingMethod:
+		name := pass.Pkg.Name() + "." + parentFunc.prefixRecv() + "."
+		msg = fmt.Sprintf("%s: %s %s, copied from it? This code is in %s",
+			diagnosticMessage, err.errType, name+err.got, name+err.expect)
+	case errFuncNotFound, errMethodNotFound, errRecieverNotFound:
+		msg = diagnosticMessage + ": " + err.errType.Error()
+		if hint := index.explain(pass.Pkg.Name(), err.parsedPrefix); hint != "" {
+			msg += ", " + hint
+		}
+	default:
+		msg = diagnosticMessage + ": " + err.errType.Error()
+	}
+	if check.origin.IsValid() {
+		msg += fmt.Sprintf(" (the message is set at line %d)", pass.Fset.Position(check.origin).Line)
+	}
+	f.diag = analysis.Diagnostic{Pos: node.Pos(), Message: msg}
+	return f
+}
+
+// A callCheck is a result of checking an error constructor call.
+type callCheck struct {
+	ctor    constructor
+	message string
+	err     *prefixError // nil if the message is fine
+	origin  token.Pos    // the value of a local variable the message comes from, see checkReachingMessages
+}
+
+// checkCall checks the message of an error constructor call. It returns false if the call is not
+// a call of a constructor with a message, see constructorOf, or its message can't be checked statically.
+func checkCall(pass *analysis.Pass, parentFunc *funcInfo, rules componentRules, call *ast.CallExpr) (callCheck, bool) {
+	ctor, ok := constructorOf(pass, call)
+	if !ok || ctor.panics {
+		// a helper panicking with the message constructs no error, see handlePanics
+		return callCheck{}, false
+	}
+	return checkMessage(pass, parentFunc, rules, call, ctor)
+}
+
+// checkMessage checks the message of a call of a constructor, see checkCall.
+func checkMessage(pass *analysis.Pass, parentFunc *funcInfo, rules componentRules, call *ast.CallExpr, ctor constructor) (callCheck, bool) {
+	arg := ctor.messageArg(call)
+	if arg == nil {
+		return callCheck{}, false
+	}
+
+	format, ok := stableString(pass, arg)
+	if !ok {
+		if check, ok := checkConcatenation(pass, parentFunc, rules, call, ctor); ok {
+			return check, true
+		}
+		return checkReachingMessages(pass, parentFunc, rules, call, ctor)
+	}
+	return checkFormat(pass, parentFunc, rules, call, ctor, format)
+}
+
+// checkConcatenation checks a message concatenated at the call site from static and dynamic parts. The static
+// leading part must hold the prefix, e.g. errors.New("pkg.Get: " + details), and a message starting with a dynamic
+// part, e.g. errors.New(details + ": boom"), has a prefix known only at runtime. It returns false if the message
+// isn't a concatenation or its static leading part ends before the separator, e.g. "pkg." + name + ": boom".
+func checkConcatenation(pass *analysis.Pass, parentFunc *funcInfo, rules componentRules, call *ast.CallExpr, ctor constructor) (callCheck, bool) {
+	concat, ok := astutil.Unparen(ctor.messageArg(call)).(*ast.BinaryExpr)
+	if !ok || concat.Op != token.ADD {
+		return callCheck{}, false
+	}
+	var head strings.Builder
+	for _, operand := range concatOperands(concat) {
+		value, ok := stableString(pass, operand)
 		if !ok {
-			return
+			break
+		}
+		head.WriteString(value)
+	}
+	if head.Len() == 0 {
+		return callCheck{ctor: ctor, err: &prefixError{errType: errDynamicPrefix}}, true
+	}
+	message := head.String()
+	if ctor.isFormat() {
+		// the verbs of the format may refer to arguments of the dynamic part, so only the literal portion is checked
+		message, _ = formatLiteral(message)
+	}
+	if !strings.Contains(message, string(config.separator)) {
+		return callCheck{}, false
+	}
+	plain := ctor
+	plain.args = -1
+	check, ok := checkFormat(pass, parentFunc, rules, call, plain, message)
+	check.ctor = ctor
+	return check, ok
+}
+
+// concatOperands returns the operands of a chain of string concatenations in order, e.g. a, b and c for a + (b + c).
+func concatOperands(expr ast.Expr) []ast.Expr {
+	concat, ok := astutil.Unparen(expr).(*ast.BinaryExpr)
+	if !ok || concat.Op != token.ADD {
+		return []ast.Expr{expr}
+	}
+	return append(concatOperands(concat.X), concatOperands(concat.Y)...)
+}
+
+// checkFormat checks an error constructor call with the message format resolved to a string.
+func checkFormat(pass *analysis.Pass, parentFunc *funcInfo, rules componentRules, call *ast.CallExpr, ctor constructor, format string) (callCheck, bool) {
+	multiline := isMultilineLiteral(call, ctor)
+	if multiline && skipsMultiline(format) {
+		return callCheck{}, false
+	}
+	args := ctor.formatArgs(call)
+	if ctor.isFormat() && config.enabled(ruleFormatVerb) {
+		if err := checkVerbs(pass, call, args, format); err != nil {
+			return callCheck{ctor: ctor, message: format, err: err}, true
+		}
+	}
+	if isPassThrough(pass, format, args) {
+		// fmt.Errorf("%w", err) passes the text of another error through, which is fine unless -pass-through is set
+		return callCheck{ctor: ctor, message: format, err: &prefixError{errType: errPassThrough}}, true
+	}
+	if len(args) > 0 && startsWithVerb(format) && isSelfLocatingCall(pass, args[0]) {
+		// fmt.Errorf("%w: key %s", errloc.New("bad key"), key) starts with the location
+		return callCheck{ctor: ctor, message: format}, true
+	}
+	if len(args) > 0 && !call.Ellipsis.IsValid() && startsWithVerb(format) {
+		if _, ok := constantValue(pass, args[0]); !ok {
+			if _, ok := stableString(pass, args[0]); !ok {
+				// fmt.Errorf("%q: not found", name) starts with a value known only at runtime
+				return callCheck{ctor: ctor, message: format, err: &prefixError{errType: errDynamicPrefix}}, true
+			}
 		}
+	}
 
-		formatArgs := make([]interface{}, 0, len(call.Args)-1)
-		for i := 1; i < len(call.Args); i++ {
+	errorMessage := format
+	switch {
+	case !ctor.isFormat():
+	case call.Ellipsis.IsValid():
+		// Arguments are spread from a slice, so the format can't be rendered. Only its literal portion is checked:
+		// the prefix must precede the first verb.
+		errorMessage, _ = formatLiteral(format)
+	default:
+		formatArgs := make([]interface{}, 0, len(args))
+		for _, arg := range args {
 			formatArgs = append(formatArgs, printableExpr{
 				pass: pass,
-				expr: call.Args[i],
+				expr: arg,
 			})
 		}
+		errorMessage = fmt.Sprintf(format, formatArgs...)
+	}
+	if i := strings.IndexByte(errorMessage, '\n'); multiline && i >= 0 {
+		// the lines after the first are the text of a template, a separator in them isn't a prefix
+		errorMessage = errorMessage[:i]
+	}
 
-		errorMessage := fmt.Sprintf(format, formatArgs...)
-		prefix, err := parsePrefix(errorMessage)
+	check := callCheck{ctor: ctor, message: errorMessage}
+	if config.dialect != dialectLocation {
+		if file, ok := parseFilePrefix(errorMessage); ok {
+			if actual := filepath.Base(pass.Fset.Position(call.Pos()).Filename); !sameFileName(fileBase(file), actual) {
+				check.err = &prefixError{errType: errStaleFile, got: fileBase(file), expect: actual}
+			}
+			return check, true
+		}
+		if config.dialect == dialectFile {
+			check.err = &prefixError{errType: errNoPrefix}
+			return check, true
+		}
+	}
 
-		report := func(err *prefixError) {
-			if isDebug() {
-				fmt.Printf("[DEBUG] errchain: %s(%q); err=%+v\n", callName, errorMessage, err)
+	check.err = matchMessage(pass, parentFunc, rules, errorMessage)
+	if check.err != nil {
+		if r, ok := hiddenRune(errorMessage); ok {
+			// the prefix may look right, but it has a character which isn't seen or is hard to see
+			check.err = &prefixError{errType: errHiddenChar, got: string(r)}
+		}
+	}
+	return check, true
+}
+
+// isMultilineLiteral tells whether the message of a call is a raw string literal spanning several lines,
+// e.g. a template of a user-facing error.
+func isMultilineLiteral(call *ast.CallExpr, ctor constructor) bool {
+	lit := messageLiteral(call, ctor)
+	return lit != nil && lit.Value[0] == '`' && strings.Contains(lit.Value, "\n")
+}
+
+// skipsMultiline tells whether a multiline message isn't checked: none is with -multiline=skip, and the ones
+// longer than -max-multiline-length aren't otherwise.
+func skipsMultiline(message string) bool {
+	return config.multiline == multilineSkip || config.maxMultilineLength > 0 && len(message) > config.maxMultilineLength
+}
+
+// matchMessage checks the location prefix of an error message.
+func matchMessage(pass *analysis.Pass, parentFunc *funcInfo, rules componentRules, errorMessage string) *prefixError {
+	prefix, err := parsePrefix(slashQualified(pass.Pkg, errorMessage))
+	if err != nil {
+		switch err {
+		case errNoPrefix:
+			return &prefixError{errType: errNoPrefix}
+		case errInvalidSyntax:
+			if repair, ok := repairPrefix(errorMessage); ok {
+				// the author's form is kept, only the receiver is repaired, e.g. "pkg.(*Struct.Method: "
+				if loc, ok := repair.location(pass.Pkg, errorMessage); ok && loc.match(pass.Pkg, parentFunc, rules) == nil {
+					repaired, sep := repair.apply(errorMessage), string(config.separator)
+					return &prefixError{errType: errInvalidSyntax, expect: repaired[:strings.Index(repaired, sep)+len(sep)]}
+				}
+			}
+			if prefix.match(pass.Pkg, parentFunc, rules) == nil {
+				// todo: report("seems like correct prefix but syntax is wrong")
+				return &prefixError{errType: errInvalidSyntax}
 			}
-			var msg string
-			switch err.errType {
-			case errNoPrefix:
-				recoms := generatePrefixRecomendations(pass, parentFunc)
-				msg = diagnosticMessage + ": " + recoms
-			default:
-				msg = diagnosticMessage + ": " + err.errType.Error()
+			return &prefixError{errType: errNoPrefix}
+		default:
+			if isDebug() {
+				panic("unexpected error type: " + err.Error())
 			}
-			pass.Reportf(node.Pos(), msg)
 		}
+	}
 
-		if err != nil {
-			switch err {
-			case errNoPrefix:
-				report(&prefixError{errType: errNoPrefix})
-				return
-			case errInvalidSyntax:
-				if prefix.match(pass.Pkg, parentFunc) == nil {
-					report(&prefixError{errType: errInvalidSyntax})
-					// todo: report("seems like correct prefix but syntax is wrong")
-					return
-				}
-				report(&prefixError{errType: errNoPrefix})
-				return
-			default:
-				if isDebug() {
-					panic("unexpected error type: " + err.Error())
-				}
+	perr := prefix.match(pass.Pkg, parentFunc, rules)
+	if perr != nil && perr.errType == errPathMismatch {
+		// "Method.Struct.pkg" reads as the package path Method/Struct/pkg, but it's rather written backwards
+		if raw, err := parsePrefix(errorMessage); err == nil {
+			if reordered, ok := raw.reordered(pass.Pkg, parentFunc, rules); ok {
+				perr = &prefixError{errType: errComponentOrder, expect: reordered, parsedPrefix: raw}
 			}
 		}
+	}
+	for _, caller := range parentFunc.callers {
+		if perr != nil && prefix.match(pass.Pkg, caller, rules) == nil {
+			// a helper may use the prefix of the function it is called by
+			perr = nil
+		}
+	}
+	return perr
+}
 
-		if err := prefix.match(pass.Pkg, parentFunc); err != nil {
-			report(err)
+// hiddenRune returns the first invisible character of the prefix of an error message: a control or format
+// character like a zero width space, a combining mark like U+0301 in "Cafe\u0301" or a space other than U+0020.
+// Identifiers can't contain them, but they are easily copied along with a name.
+func hiddenRune(errorMessage string) (rune, bool) {
+	i := strings.Index(errorMessage, string(config.separator))
+	if i < 0 {
+		return 0, false
+	}
+	for _, r := range errorMessage[:i] {
+		if unicode.In(r, unicode.Cc, unicode.Cf, unicode.Mn, unicode.Me) || (unicode.IsSpace(r) && r != ' ') {
+			return r, true
 		}
 	}
+	return 0, false
+}
+
+// prefixProblem describes a wrong prefix of a message of a function for the rules reporting texts other
+// than error messages, e.g. "function name is required. Consider starting message with ...".
+func prefixProblem(pass *analysis.Pass, fn *funcInfo, rules componentRules, pos token.Pos, err *prefixError) string {
+	recoms := generatePrefixRecomendations(pass, fn, rules, pos)
+	if err.errType == errNoPrefix {
+		return recoms
+	}
+	return err.errType.Error() + ". " + recoms
 }
 
-func generatePrefixRecomendations(pass *analysis.Pass, parentFunc *ast.FuncDecl) string {
+// recommendationsLead starts the list of recommended prefixes in diagnostic messages, see ExpectedPrefixes.
+const recommendationsLead = "Consider starting message with one of the following strings: "
+
+// A recommendationKey identifies the location prefixes recommended for the messages of a function.
+type recommendationKey struct {
+	fn    *funcInfo
+	rules componentRules
+}
+
+// generatePrefixRecomendations lists the prefixes a message at pos may start with. The location prefixes
+// of a function are the same for all of its messages, so they are quoted once and kept in the package state.
+func generatePrefixRecomendations(pass *analysis.Pass, parentFunc *funcInfo, rules componentRules, pos token.Pos) string {
+	var locations string
+	if config.dialect != dialectFile {
+		locations = quotedPrefixes(pass.Pkg, parentFunc, rules)
+	}
+	var file string
+	if config.dialect != dialectLocation {
+		position := pass.Fset.Position(pos)
+		file = strconv.Quote(filepath.Base(position.Filename) + ":" + strconv.Itoa(position.Line) + string(config.separator))
+	}
+
 	buf := strings.Builder{}
-	buf.WriteString("Consider starting message with one of the following strings: ")
-	for i, prefix := range errorPrefixes(pass.Pkg, parentFunc) {
+	buf.Grow(len(recommendationsLead) + len(locations) + len(", ") + len(file))
+	buf.WriteString(recommendationsLead)
+	buf.WriteString(locations)
+	if locations != "" && file != "" {
+		buf.WriteString(", ")
+	}
+	buf.WriteString(file)
+	return buf.String()
+}
+
+// quotedPrefixes returns the location prefixes of a function quoted and separated by commas,
+// memoized in the state of the package being analyzed.
+func quotedPrefixes(pkg *types.Package, fn *funcInfo, rules componentRules) string {
+	state := stateOf(pkg)
+	key := recommendationKey{fn: fn, rules: rules}
+	if state != nil {
+		if quoted, ok := state.recommendations[key]; ok {
+			return quoted
+		}
+	}
+	prefixes := errorPrefixes(pkg, fn, rules)
+	size := 0
+	for _, prefix := range prefixes {
+		size += len(prefix) + len(`"", `)
+	}
+	buf := strings.Builder{}
+	buf.Grow(size)
+	for i, prefix := range prefixes {
 		if i > 0 {
 			buf.WriteString(", ")
 		}
 		buf.WriteString(strconv.Quote(prefix))
 	}
-	return buf.String()
+	quoted := buf.String()
+	if state != nil {
+		state.recommendations[key] = quoted
+	}
+	return quoted
 }
 
 type location struct {
@@ -229,8 +1016,22 @@ var (
 	errInvalidSyntax    = errorKind("syntax is wrong")
 	errFuncNotFound     = errorKind("neither func nor struct has been found")
 	errMethodNotFound   = errorKind("method not found")
+	errSiblingMethod    = errorKind("prefix refers to a sibling method")
+	errComponentOrder   = errorKind("components out of order")
+	errPackageMissing   = errorKind("package component missing")
 	errRecieverNotFound = errorKind("reciever not found")
 	errNoPointer        = errorKind("reciever has no pointer")
+	errFuncRequired     = errorKind("function name is required")
+	errRecvRequired     = errorKind("reciever name is required")
+	errStaleFile        = errorKind("file name is stale")
+	errDynamicPrefix    = errorKind("prefix must be static")
+	errHiddenChar       = errorKind("prefix contains an invisible character")
+	errFormatVerb       = errorKind("format verb does not match argument type")
+	errFormatArgs       = errorKind("format verbs do not match arguments")
+	errPassThrough      = errorKind("wrapping adds no location information")
+
+	errUnqualifiedPackage = errorKind("package name is ambiguous, qualify it with parent path segments")
+	errPathMismatch       = errorKind("package path mismatch")
 )
 
 type prefixError struct {
@@ -240,32 +1041,320 @@ type prefixError struct {
 	parsedPrefix location
 }
 
-func (loc location) match(pkg *types.Package, fn *ast.FuncDecl) *prefixError {
+func (loc location) match(pkg *types.Package, fn *funcInfo, rules componentRules) *prefixError {
 	if loc.pkg == "" {
 		return &prefixError{errType: errNoPrefix, got: loc.pkg, expect: pkg.Name(), parsedPrefix: loc}
 	}
 
-	if !strings.HasSuffix(pkg.Path(), loc.pkg) {
-		return &prefixError{errType: errPackageMismatch, got: loc.pkg, expect: pkg.Name(), parsedPrefix: loc}
+	if !isPackageName(pkg, loc.pkg) {
+		if aliased, ok := loc.aliased(pkg, fn); ok {
+			// callers know the method by the alias, e.g. "sdk.Client.Do" for type Client = transport.Client
+			return aliased.matchComponents(fn, rules)
+		}
+		err := &prefixError{errType: errPackageMismatch, got: loc.pkg, expect: pkg.Name(), parsedPrefix: loc}
+		if qualifiedErr := matchQualification(pkg, loc); qualifiedErr != nil {
+			err = qualifiedErr
+		}
+		if !rules.pkg.required() {
+			// Prefix may have no package at all, e.g. "Struct.Method: ".
+			if unqualified, ok := loc.unqualified(); ok {
+				switch unqualifiedErr := unqualified.matchComponents(fn, rules); {
+				case unqualifiedErr == nil:
+					return nil
+				case unqualifiedErr.errType == errFuncRequired, unqualifiedErr.errType == errRecvRequired:
+					return unqualifiedErr
+				}
+			}
+		}
+		if err.errType == errPackageMismatch {
+			if reordered, ok := loc.reordered(pkg, fn, rules); ok {
+				return &prefixError{errType: errComponentOrder, expect: reordered, parsedPrefix: loc}
+			}
+			if qualified, ok := loc.qualified(pkg, fn, rules); ok {
+				return &prefixError{errType: errPackageMissing, expect: qualified, parsedPrefix: loc}
+			}
+		}
+		return err
+	}
+
+	err := loc.matchComponents(fn, rules)
+	if err != nil {
+		if reordered, ok := loc.reordered(pkg, fn, rules); ok {
+			return &prefixError{errType: errComponentOrder, expect: reordered, parsedPrefix: loc}
+		}
+	}
+	return err
+}
+
+// componentOrders are the permutations of two and three prefix components other than the written order.
+var componentOrders = [][][]int{2: {{1, 0}}, 3: {{0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}}}
+
+// reordered returns the prefix a location refers to if its components are put in order, e.g. "pkg.Struct.Method: "
+// for "Method.Struct.pkg". The pointer form of the receiver isn't reordered.
+func (loc location) reordered(pkg *types.Package, fn *funcInfo, rules componentRules) (string, bool) {
+	if loc.isRecvPtr || loc.fn == "" {
+		return "", false
+	}
+	components := []string{loc.pkg, loc.fn}
+	if loc.recv != "" {
+		components = []string{loc.pkg, loc.recv, loc.fn}
+	}
+	for _, order := range componentOrders[len(components)] {
+		candidate := location{pkg: components[order[0]], fn: components[order[len(order)-1]]}
+		if len(order) == 3 {
+			candidate.recv = components[order[1]]
+		}
+		if isPackageName(pkg, candidate.pkg) && candidate.matchComponents(fn, rules) == nil {
+			return candidate.String() + string(config.separator), true
+		}
+	}
+	return "", false
+}
+
+// qualified returns the prefix a location without the package component refers to, e.g. "pkg.Struct.Method: "
+// for "Struct.Method", keeping the pointer form of the receiver.
+func (loc location) qualified(pkg *types.Package, fn *funcInfo, rules componentRules) (string, bool) {
+	unqualified, ok := loc.unqualified()
+	if !ok || unqualified.matchComponents(fn, rules) != nil {
+		return "", false
+	}
+	prefix := packageNames(pkg)[0] + "."
+	switch {
+	case unqualified.isRecvPtr:
+		prefix += "(*" + unqualified.recv + ")."
+	case unqualified.recv != "":
+		prefix += unqualified.recv + "."
+	}
+	return prefix + unqualified.fn + string(config.separator), true
+}
+
+// isPackageName tells whether a name used in an error prefix refers to the package. If the package has
+// a prefix directive, only the name it sets matches. Otherwise the declared package name always matches.
+// Unless config.pkgMatch is pkgMatchName, trailing elements of the import path match as well,
+// e.g. "bbb" or "aaa/bbb" for aaa/bbb, and the major version suffix is ignored, so a module served under
+// a vanity path like go.example.com/yaml.v3 or go.example.com/x/v2 may use "yaml" or "x".
+// Partial elements don't match: "bb" doesn't refer to aaa/bbb. A package set by config.qualified matches only
+// names with at least config.minSegments trailing elements, e.g. "storage/util".
+func isPackageName(pkg *types.Package, name string) bool {
+	if prefix, ok := packagePrefix(pkg); ok {
+		return name == prefix
+	}
+	if q, ok := qualifiedName(pkg); ok {
+		return strings.Count(name, "/") >= strings.Count(q, "/") && isPathSuffix(pkg.Path(), name)
+	}
+	if name == pkg.Name() {
+		return true
+	}
+	if config.pkgMatch == pkgMatchName {
+		return false
+	}
+	return isPathSuffix(matchedPath(pkg), name)
+}
+
+// isPathSuffix tells whether a name consists of trailing elements of an import path, with or without
+// the major version suffix.
+func isPathSuffix(path, name string) bool {
+	if path == name || strings.HasSuffix(path, "/"+name) {
+		return true
+	}
+	trimmed := trimMajorVersion(path)
+	return trimmed == name || strings.HasSuffix(trimmed, "/"+name)
+}
+
+// qualifiedName returns the name error prefixes of a package set by config.qualified must start with:
+// config.minSegments trailing elements of its import path, e.g. "storage/util" for example.com/storage/util.
+func qualifiedName(pkg *types.Package) (string, bool) {
+	if len(config.qualified) == 0 || !config.qualified.match(pkg.Path()) {
+		return "", false
+	}
+	if _, ok := packagePrefix(pkg); ok {
+		return "", false
+	}
+	return pathSuffix(pkg.Path(), config.minSegments), true
+}
+
+// pathSuffix returns up to n trailing elements of an import path without the major version suffix.
+func pathSuffix(path string, n int) string {
+	elems := strings.Split(trimMajorVersion(path), "/")
+	if n > 0 && n < len(elems) {
+		elems = elems[len(elems)-n:]
+	}
+	return strings.Join(elems, "/")
+}
+
+// trimMajorVersion removes the major version suffix of an import path: "a/b/v2" and "gopkg.in/b.v2" give "a/b"
+// and "gopkg.in/b".
+func trimMajorVersion(path string) string {
+	i := strings.LastIndex(path, "/")
+	if i >= 0 && isMajorVersion(path[i+1:]) {
+		return path[:i]
+	}
+	if j := strings.LastIndex(path, "."); j > i && isMajorVersion(path[j+1:]) {
+		return path[:j]
+	}
+	return path
+}
+
+// matchQualification explains why a prefix naming the package by its last path element doesn't refer to it:
+// either the parent path segments differ, like "store/postgres" for storage/postgres, or the package
+// is set by config.qualified and the segments are missing. It returns nil if the prefix names another package.
+func matchQualification(pkg *types.Package, loc location) *prefixError {
+	if _, ok := packagePrefix(pkg); ok {
+		return nil
+	}
+	q, qualified := qualifiedName(pkg)
+	if !qualified && config.pkgMatch != pkgMatchPath {
+		return nil
+	}
+	last := loc.pkg[strings.LastIndex(loc.pkg, "/")+1:]
+	if last != pkg.Name() && last != pathPackageName(pkg.Path()) {
+		return nil
+	}
+	if segments := strings.Count(loc.pkg, "/") + 1; segments > 1 {
+		if expect := pathSuffix(pkg.Path(), segments); strings.Count(expect, "/")+1 == segments && expect != loc.pkg {
+			return &prefixError{errType: errPathMismatch, got: loc.pkg, expect: expect, parsedPrefix: loc}
+		}
+	}
+	if qualified {
+		return &prefixError{errType: errUnqualifiedPackage, got: loc.pkg, expect: q, parsedPrefix: loc}
+	}
+	return nil
+}
+
+// packageNames returns the names error prefixes are recommended to start with: the name set by a prefix directive
+// or else the package clause name and, unless config.pkgMatch is pkgMatchName, the last import path element
+// if it differs, e.g. "v1" and "userv1" for "package v1" in api/userv1.
+func packageNames(pkg *types.Package) []string {
+	if prefix, ok := packagePrefix(pkg); ok {
+		return []string{prefix}
+	}
+	if q, ok := qualifiedName(pkg); ok {
+		return []string{q}
+	}
+	names := []string{pkg.Name()}
+	if config.pkgMatch == pkgMatchName {
+		return names
+	}
+	if name := pathPackageName(pkg.Path()); name != pkg.Name() && token.IsIdentifier(name) {
+		names = append(names, name)
+	}
+	return names
+}
+
+// expectedPackage describes the package names a prefix may start with for a package name mismatch diagnostic.
+func expectedPackage(pkg *types.Package) string {
+	if prefix, ok := packagePrefix(pkg); ok {
+		return strconv.Quote(prefix) + " from the " + prefixDirective[2:] + " directive"
+	}
+	names := packageNames(pkg)
+	desc := strconv.Quote(names[0]) + " from the package clause"
+	if len(names) > 1 {
+		desc += " or " + strconv.Quote(names[1]) + " from the import path"
+	}
+	return desc
+}
+
+// pathPackageName returns the last element of an import path without a major version suffix:
+// "a/b/v2" and "gopkg.in/b.v2" give "b".
+func pathPackageName(path string) string {
+	elem := path[strings.LastIndex(path, "/")+1:]
+	if isMajorVersion(elem) && strings.Contains(path, "/") {
+		path = path[:strings.LastIndex(path, "/")]
+		elem = path[strings.LastIndex(path, "/")+1:]
+	}
+	if i := strings.LastIndex(elem, "."); i >= 0 && isMajorVersion(elem[i+1:]) {
+		elem = elem[:i]
+	}
+	return elem
+}
+
+// isMajorVersion tells whether a path element is a major version suffix like "v2".
+func isMajorVersion(elem string) bool {
+	if len(elem) < 2 || elem[0] != 'v' {
+		return false
+	}
+	for _, r := range elem[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// unqualified reinterprets a location parsed as "pkg.X" into a package-less location.
+func (loc location) unqualified() (location, bool) {
+	if loc.recv != "" {
+		// all three components are present so the first one has to be a package
+		return loc, false
+	}
+
+	if loc.fn == "" {
+		return location{fn: loc.pkg}, token.IsIdentifier(loc.pkg)
 	}
 
-	recieverName, isRecieverPointer := recvString(fn)
-	functionName := fn.Name.Name
+	recv := loc.pkg
+	isRecvPtr := strings.HasPrefix(recv, "(*") && strings.HasSuffix(recv, ")")
+	if isRecvPtr {
+		recv = recv[2 : len(recv)-1]
+	}
+	return location{recv: recv, fn: loc.fn, isRecvPtr: isRecvPtr}, token.IsIdentifier(recv)
+}
+
+// promoted reinterprets a location referring to a type the method is promoted to, e.g. "pkg.Server.Close"
+// for (*conn).Close promoted to Server, or to the type an alias receiver refers to, as a location of the method's
+// own receiver.
+func (loc location) promoted(fn *funcInfo) location {
+	for _, name := range fn.otherRecvNames() {
+		switch {
+		case loc.recv == name:
+			loc.recv, loc.isRecvPtr = fn.prefixRecv(), fn.isRecvPtr
+		case loc.recv == "" && loc.fn == name:
+			loc.fn = fn.prefixRecv()
+		}
+	}
+	return loc
+}
+
+// declaredSpelling replaces the receiver and function names of the location referring to the names of the function
+// under config.casing with their declared spelling, e.g. "jsonEncoder" with "JSONEncoder".
+func (loc location) declaredSpelling(fn *funcInfo) location {
+	if config.casing == casingExact {
+		return loc
+	}
+	for _, name := range append(fn.recvNames(), fn.name) {
+		if name == "" {
+			continue
+		}
+		if config.casing.equal(loc.recv, name) {
+			loc.recv = name
+		}
+		if config.casing.equal(loc.fn, name) {
+			loc.fn = name
+		}
+	}
+	return loc
+}
+
+// matchComponents checks the receiver and function components of the location.
+func (loc location) matchComponents(fn *funcInfo, rules componentRules) *prefixError {
+	loc = loc.declaredSpelling(fn).promoted(fn)
+	recieverName, isRecieverPointer := fn.prefixRecv(), fn.pointerForm()
+	functionName := fn.name
 
 	// pkg only
 	if loc.recv == "" && loc.fn == "" {
-		return nil
+		return loc.requireComponents(rules, recieverName, false, false)
 	}
 
 	// pkg.Func, pkg.Struct, pkg.Method
 	if loc.recv == "" && loc.fn != "" {
 		if loc.fn == recieverName {
 			// pkg.Struct
-			return nil
+			return loc.requireComponents(rules, recieverName, true, false)
 		}
 		if loc.fn == functionName {
 			// pkg.Func, pkg.Method
-			return nil
+			return loc.requireComponents(rules, recieverName, false, true)
 		}
 		return &prefixError{
 			errType:      errFuncNotFound,
@@ -325,6 +1414,17 @@ func (loc location) match(pkg *types.Package, fn *ast.FuncDecl) *prefixError {
 	return nil
 }
 
+// requireComponents checks that a matched location contains all the components required by the rules.
+func (loc location) requireComponents(rules componentRules, recieverName string, hasRecv, hasFunc bool) *prefixError {
+	if rules.fn.required() && !hasFunc {
+		return &prefixError{errType: errFuncRequired, parsedPrefix: loc}
+	}
+	if rules.recv.required() && recieverName != "" && !hasRecv {
+		return &prefixError{errType: errRecvRequired, expect: recieverName, parsedPrefix: loc}
+	}
+	return nil
+}
+
 // recvString returns a string representation of the functions reciever.
 func recvString(fn *ast.FuncDecl) (recieverName string, isPointer bool) {
 	if fn.Recv == nil || len(fn.Recv.List) == 0 {
@@ -345,8 +1445,7 @@ func recvString(fn *ast.FuncDecl) (recieverName string, isPointer bool) {
 }
 
 func parsePrefix(errorMessage string) (loc location, err error) {
-	const sep = ": "
-	i := strings.Index(errorMessage, sep)
+	i := strings.Index(errorMessage, string(config.separator))
 	if i < 0 {
 		return loc, errNoPrefix
 	}
@@ -369,13 +1468,6 @@ func parsePrefix(errorMessage string) (loc location, err error) {
 		return loc, errInvalidSyntax
 	}
 
-	if loc.recv != "" && !token.IsIdentifier(loc.recv) {
-		return loc, errInvalidSyntax
-	}
-	if loc.fn != "" && !token.IsIdentifier(loc.fn) {
-		return loc, errInvalidSyntax
-	}
-
 	if strings.HasPrefix(loc.recv, "(*") {
 		loc.recv = loc.recv[2:]
 		if strings.HasSuffix(loc.recv, ")") {
@@ -385,9 +1477,134 @@ func parsePrefix(errorMessage string) (loc location, err error) {
 			return loc, errInvalidSyntax
 		}
 	}
+
+	if loc.recv != "" && !token.IsIdentifier(loc.recv) {
+		return loc, errInvalidSyntax
+	}
+	if loc.fn != "" && !token.IsIdentifier(loc.fn) {
+		return loc, errInvalidSyntax
+	}
 	return loc, nil
 }
 
+// A prefixRepair is an edit of a malformed receiver of a prefix, e.g. "(*Struct" to "(*Struct)"
+// in "pkg.(*Struct.Method: ".
+type prefixRepair struct {
+	start, end int // byte offsets of the malformed receiver in the message
+	text       string
+}
+
+// repairPrefix finds a receiver with misplaced parentheses or a misplaced star in the prefix of an error message:
+// "(*Struct", "*Struct)" and "*Struct" become "(*Struct)", "(Struct)" becomes "Struct".
+func repairPrefix(errorMessage string) (prefixRepair, bool) {
+	i := strings.Index(errorMessage, string(config.separator))
+	if i < 0 {
+		return prefixRepair{}, false
+	}
+	start := 0
+	for _, segment := range strings.Split(errorMessage[:i], ".") {
+		end := start + len(segment)
+		if strings.ContainsAny(segment, "(*)") {
+			name := strings.Trim(segment, "(*)")
+			if !token.IsIdentifier(name) {
+				return prefixRepair{}, false
+			}
+			text := name
+			if strings.Contains(segment, "*") {
+				text = "(*" + name + ")"
+			}
+			return prefixRepair{start: start, end: end, text: text}, text != segment
+		}
+		start = end + 1
+	}
+	return prefixRepair{}, false
+}
+
+func (r prefixRepair) apply(errorMessage string) string {
+	return errorMessage[:r.start] + r.text + errorMessage[r.end:]
+}
+
+// location parses the prefix of an error message as it reads after the repair. The receiver is parsed
+// by its name, e.g. "pkg.Struct.Method: " for "pkg.(*Struct.Method: ", with the pointer form recorded.
+func (r prefixRepair) location(pkg *types.Package, errorMessage string) (location, bool) {
+	name := strings.Trim(r.text, "(*)")
+	loc, err := parsePrefix(slashQualified(pkg, errorMessage[:r.start]+name+errorMessage[r.end:]))
+	if err != nil {
+		return loc, false
+	}
+	loc.isRecvPtr = name != r.text
+	return loc, true
+}
+
+// slashQualified rewrites a prefix qualifying the package with parent path segments separated by dots,
+// like "storage.postgres.Conn.Query: ", to the slash form "storage/postgres.Conn.Query: ", so it's parsed
+// as a location. The parent segments are validated against the import path when the location is matched.
+func slashQualified(pkg *types.Package, errorMessage string) string {
+	if _, ok := packagePrefix(pkg); ok {
+		return errorMessage
+	}
+	i := strings.Index(errorMessage, string(config.separator))
+	if i < 0 {
+		return errorMessage
+	}
+	segments := strings.Split(errorMessage[:i], ".")
+	for j, segment := range segments {
+		if segment == "" || strings.ContainsAny(segment, "(* ") {
+			break
+		}
+		if segment != pkg.Name() && segment != pathPackageName(pkg.Path()) {
+			continue
+		}
+		if j == 0 {
+			break
+		}
+		head := strings.Join(segments[:j+1], "/")
+		if j+1 < len(segments) {
+			head += "." + strings.Join(segments[j+1:], ".")
+		}
+		return head + errorMessage[i:]
+	}
+	return errorMessage
+}
+
+// isPassThrough tells whether a format only formats an error argument without any text of its own,
+// e.g. fmt.Errorf("%w", err) or fmt.Errorf("%v", err).
+func isPassThrough(pass *analysis.Pass, format string, args []ast.Expr) bool {
+	switch format {
+	case "%w", "%v", "%s":
+	default:
+		return false
+	}
+	if len(args) != 1 {
+		return false
+	}
+	t := pass.TypesInfo.TypeOf(args[0])
+	return t != nil && types.Implements(t, types.Universe.Lookup("error").Type().Underlying().(*types.Interface))
+}
+
+// startsWithVerb tells whether a format string starts with a formatting verb like "%q: not found".
+func startsWithVerb(format string) bool {
+	return strings.HasPrefix(format, "%") && !strings.HasPrefix(format, "%%")
+}
+
+// parseFilePrefix parses a file:line prefix like "store/user.go:42: " and returns the file path.
+func parseFilePrefix(errorMessage string) (file string, ok bool) {
+	i := strings.Index(errorMessage, string(config.separator))
+	if i < 0 {
+		return "", false
+	}
+	head := errorMessage[:i]
+	j := strings.LastIndex(head, ":")
+	if j < 0 {
+		return "", false
+	}
+	file, line := head[:j], head[j+1:]
+	if !hasFileSuffix(file, ".go") || line == "" || strings.Trim(line, "0123456789") != "" {
+		return "", false
+	}
+	return file, true
+}
+
 func constantValue(pass *analysis.Pass, expr ast.Expr) (interface{}, bool) {
 	val := pass.TypesInfo.Types[expr].Value
 	if val == nil {
@@ -405,6 +1622,25 @@ func constantValueString(pass *analysis.Pass, expr ast.Expr) (string, bool) {
 	return str, ok
 }
 
+// formatLiteral returns the part of a format string preceding the first verb, with "%%" unescaped.
+// The complete flag tells whether the format has no verbs at all.
+func formatLiteral(format string) (literal string, complete bool) {
+	var buf strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			buf.WriteByte(format[i])
+			continue
+		}
+		if i+1 < len(format) && format[i+1] == '%' {
+			buf.WriteByte('%')
+			i++
+			continue
+		}
+		return buf.String(), false
+	}
+	return buf.String(), true
+}
+
 // An exprString gives a text representation of an ast.Expr which are concise and easy to read.
 func exprString(expr ast.Expr, depth int) string {
 	if depth > 2 {
@@ -475,19 +1711,41 @@ func isGenerated(file *ast.File) bool {
 	return false
 }
 
+// isSkippedGenerated tells whether a file is generated and isn't included back by config.includeGenerated.
+// Skipped files are reported in the verbose mode.
+func isSkippedGenerated(pass *analysis.Pass, file *ast.File) bool {
+	if !isGenerated(file) {
+		return false
+	}
+	filename := pass.Fset.Position(file.Package).Filename
+	if config.includeGenerated.matchFile(filename) {
+		return false
+	}
+	if config.enabled(ruleSkipped) {
+		reportSkipped(pass, file.Package, skipGeneratedFile, filepath.Base(filename))
+	} else if config.verbose && reportable(pass, file.Package) {
+		pass.Report(analysis.Diagnostic{
+			Pos:      file.Package,
+			Category: "info",
+			Message:  "Generated file " + filepath.Base(filename) + " is skipped, use -include-generated to check it",
+		})
+	}
+	return true
+}
+
 // An isTest tells whether a given file is a test file.
 func isTest(pass *analysis.Pass, file *ast.File) bool {
 	f := pass.Fset.File(file.Pos())
 	if f == nil {
 		return false
 	}
-	return strings.HasSuffix(f.Name(), "_test.go")
+	return hasFileSuffix(f.Name(), "_test.go")
 }
 
 // A printableExpr wraps ast.Expr and make it printable via fmt.Errorf function. It implements fmt.Formatter.
 // Main reason for this is to print any ast.Expr via fmt.Errorf via any format string regardless of used verbs.
-// For a constant it prints a constant's value, for variables it prints a variable's name, and for other expressions
-// it is trying to print a short readable description.
+// For a constant or a stable variable (see stableVars) it prints its value, for other variables it prints
+// a variable's name, and for other expressions it is trying to print a short readable description.
 type printableExpr struct {
 	pass *analysis.Pass
 	expr ast.Expr
@@ -497,6 +1755,10 @@ var _ fmt.Formatter = printableExpr{}
 
 // Format implements fmt.Formatter.
 func (e printableExpr) Format(s fmt.State, verb rune) {
+	if str, ok := stableString(e.pass, e.expr); ok {
+		_, _ = fmt.Fprintf(s, "%v", str)
+		return
+	}
 	v, ok := constantValue(e.pass, e.expr)
 	if !ok {
 		_, _ = fmt.Fprintf(s, "{%s}", exprString(e.expr, 0))
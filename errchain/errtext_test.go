@@ -1,12 +1,620 @@
 package errchain
 
 import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
 	"testing"
 
+	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/analysistest"
 )
 
 func Test(t *testing.T) {
 	testdata := analysistest.TestData()
-	analysistest.Run(t, testdata, Analyzer, "./...")
+	analysistest.Run(t, testdata, Analyzer, ".", "./aaa/...")
+}
+
+func TestPackageMatch(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./vanity/...")
+	// checked out under a path other than the vanity path declared by go.mod
+	analysistest.Run(t, testdata, Analyzer, "github.com/org/kv-go/store")
+
+	setFlags(t, map[string]string{"pkg-match": "name"})
+	analysistest.Run(t, testdata, Analyzer, "./pkgmatch/...")
+}
+
+func TestDirectives(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./directive")
+}
+
+func TestQualified(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./qualified/storage/postgres")
+
+	setFlags(t, map[string]string{"qualified": "util", "min-segments": "2"})
+	analysistest.Run(t, testdata, Analyzer, "./qualified/storage/util")
+}
+
+func TestModulePackages(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./module/cache")
+}
+
+func TestNamespaces(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "example.com/ns/...")
+}
+
+func TestThirdParty(t *testing.T) {
+	setFlags(t, map[string]string{"why-skipped": "true"})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer,
+		"example.com/tp/app", "example.com/tp/vendor/example.org/log", "example.com/tp/third_party/yaml")
+
+	setFlags(t, map[string]string{"skip-vendor": "false", "include-third-party": "true"})
+	analysistest.Run(t, testdata, Analyzer, "example.com/tp/vendor/example.org/retry", "example.com/tp/external/json")
+}
+
+func TestComponents(t *testing.T) {
+	setFlags(t, map[string]string{
+		"pkg-component":  "optional",
+		"func-component": "required",
+	})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./components")
+}
+
+// setFlags sets the analyzer flags for the duration of a test.
+func setFlags(t *testing.T, flags map[string]string) {
+	t.Helper()
+	for name, value := range flags {
+		f := Analyzer.Flags.Lookup(name)
+		if f == nil {
+			t.Fatalf("unknown flag %q", name)
+		}
+		prev := f.Value.String()
+		if err := f.Value.Set(value); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = f.Value.Set(prev) })
+	}
+}
+
+func TestMinPrefixComponents(t *testing.T) {
+	setFlags(t, map[string]string{
+		"pkg-component":         "optional",
+		"min-prefix-components": "depth/store=3,*/tiny=1",
+	})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./depth/...")
+}
+
+func TestErrorPosition(t *testing.T) {
+	setFlags(t, map[string]string{
+		"any-error-position": "true",
+		"error-last":         "true",
+	})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./errorposition")
+}
+
+func TestFactory(t *testing.T) {
+	setFlags(t, map[string]string{
+		"pkg-component": "optional",
+		"factory":       "func",
+	})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./factory")
+}
+
+func TestFacts(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./facts")
+}
+
+func TestFixes(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, Analyzer, "./fixes")
+}
+
+func TestCallbacks(t *testing.T) {
+	setFlags(t, map[string]string{"callbacks": "pkg"})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./callbacks")
+}
+
+func TestRegistrars(t *testing.T) {
+	setFlags(t, map[string]string{"registrars": "registry.Register, registry.Registry.Add"})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./registry")
+}
+
+func TestIncludeGenerated(t *testing.T) {
+	caseInsensitive := caseInsensitiveFS
+	caseInsensitiveFS = true
+	t.Cleanup(func() { caseInsensitiveFS = caseInsensitive })
+
+	setFlags(t, map[string]string{
+		"include-generated": `generated\*_SERVICE.go`,
+		"verbose":           "true",
+	})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./generated")
+}
+
+func TestWhySkipped(t *testing.T) {
+	setFlags(t, map[string]string{"why-skipped": "true"})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./skipped/...")
+}
+
+func TestMocks(t *testing.T) {
+	setFlags(t, map[string]string{"why-skipped": "true"})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./mocks/...")
+}
+
+func TestSelfLocating(t *testing.T) {
+	setFlags(t, map[string]string{"self-locating": "selflocating.locate"})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./selflocating")
+}
+
+func TestDialect(t *testing.T) {
+	caseInsensitive := caseInsensitiveFS
+	caseInsensitiveFS = true
+	t.Cleanup(func() { caseInsensitiveFS = caseInsensitive })
+
+	testdata := analysistest.TestData()
+	setFlags(t, map[string]string{"dialect": "any"})
+	analysistest.Run(t, testdata, Analyzer, "./dialect/any")
+
+	setFlags(t, map[string]string{"dialect": "file"})
+	analysistest.Run(t, testdata, Analyzer, "./dialect/file")
+}
+
+func TestCasing(t *testing.T) {
+	testdata := analysistest.TestData()
+	setFlags(t, map[string]string{"casing": "acronyms", "acronyms": "grpc"})
+	analysistest.Run(t, testdata, Analyzer, "./casing/acronyms")
+
+	setFlags(t, map[string]string{"casing": "fold"})
+	analysistest.Run(t, testdata, Analyzer, "./casing/fold")
+}
+
+func TestSeparator(t *testing.T) {
+	setFlags(t, map[string]string{"separator": " - "})
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, Analyzer, "./separator")
+}
+
+func TestMultilineMessages(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./multiline/lines")
+}
+
+func TestMaxMultilineLength(t *testing.T) {
+	setFlags(t, map[string]string{"max-multiline-length": "40"})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./multiline/limit")
+}
+
+func TestRules(t *testing.T) {
+	setFlags(t, map[string]string{
+		"any-error-position": "true",
+		"disable":            "prefix,propagation",
+		"enable":             "error-last",
+	})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./rules")
+}
+
+func TestTableFuncs(t *testing.T) {
+	setFlags(t, map[string]string{"package-level": "false"})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./tables")
+}
+
+func TestPromotedMethods(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./promoted")
+}
+
+func TestLazyInitializers(t *testing.T) {
+	setFlags(t, map[string]string{"package-level": "false"})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./lazy")
+}
+
+func TestInterprocedural(t *testing.T) {
+	setFlags(t, map[string]string{"interprocedural": "true"})
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, Analyzer, "./helpers")
+}
+
+func TestForwarders(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./forwarders")
+}
+
+func TestRequireContext(t *testing.T) {
+	setFlags(t, map[string]string{"require-context": "true"})
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, Analyzer, "./context")
+}
+
+func TestMultiErrors(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./multierr")
+}
+
+// TestDeterministic checks that diagnostics don't depend on map iteration order,
+// which incremental drivers like gopls rely on.
+func TestDeterministic(t *testing.T) {
+	testdata := analysistest.TestData()
+	diagnostics := func() []string {
+		var diags []string
+		for _, result := range analysistest.Run(t, testdata, Analyzer, "./aaa/...") {
+			for _, d := range result.Diagnostics {
+				diags = append(diags, result.Pass.Fset.Position(d.Pos).String()+": "+d.Message)
+			}
+		}
+		return diags
+	}
+
+	first := diagnostics()
+	for i := 0; i < 3; i++ {
+		if next := diagnostics(); !reflect.DeepEqual(first, next) {
+			t.Fatalf("diagnostics differ between runs:\n%v\n%v", first, next)
+		}
+	}
+}
+
+func TestReachingMessages(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./reaching")
+}
+
+func TestUnicodeNames(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./unicode")
+}
+
+func TestErrorCollections(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./collections")
+}
+
+func TestCrossFileConstants(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./crossfile")
+}
+
+// TestUnfoldedConstants checks that constants declared in another file resolve even if the driver doesn't record
+// the values of expressions.
+func TestUnfoldedConstants(t *testing.T) {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, src := range []string{
+		"package users\n\nconst prefUser = \"users.Service\"\n",
+		"package users\n\nvar msg = prefUser + \".Get\" + \": not found\"\n",
+	} {
+		file, err := parser.ParseFile(fset, "", src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+	}
+	info := &types.Info{Defs: make(map[*ast.Ident]types.Object), Uses: make(map[*ast.Ident]types.Object)}
+	pkg, err := (&types.Config{Importer: importer.Default()}).Check("users", fset, files, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pass := &analysis.Pass{Fset: fset, Files: files, Pkg: pkg, TypesInfo: info}
+	expr := files[1].Decls[0].(*ast.GenDecl).Specs[0].(*ast.ValueSpec).Values[0]
+	if got, ok := stableString(pass, expr); !ok || got != "users.Service.Get: not found" {
+		t.Errorf("stableString() = %q, %v", got, ok)
+	}
+}
+
+func TestFormatVerbs(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./verbs")
+}
+
+func TestDuplicateMessages(t *testing.T) {
+	setFlags(t, map[string]string{"duplicate-messages": "true"})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./duplicates")
+}
+
+func TestWrapperPackages(t *testing.T) {
+	setFlags(t, map[string]string{"wrapper-packages": "wrapper/retry"})
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, Analyzer, "./wrapper/retry")
+}
+
+func TestExamples(t *testing.T) {
+	setFlags(t, map[string]string{"separator": " - "})
+	for fn, want := range map[string]string{
+		"store":              "store - ",
+		"store.Open":         "store.Open - ",
+		"store.(*DB).Get":    "store.DB.Get - ",
+		"store.DB.Get":       "store.DB.Get - ",
+		"store.(*DB.Get":     "",
+		"store.DB.Get.Inner": "",
+		"":                   "",
+	} {
+		got, err := CanonicalPrefix(fn)
+		if got != want || (err != nil) != (want == "") {
+			t.Errorf("CanonicalPrefix(%q) = %q, %v; want %q", fn, got, err, want)
+		}
+	}
+
+	for _, r := range Rules() {
+		bad, good, ok, err := Example(r.Code, "store.(*DB).Get")
+		if err != nil || (ok && (bad == "" || good == "" || bad == good)) {
+			t.Errorf("Example(%q) = %q, %q, %v, %v", r.Code, bad, good, ok, err)
+		}
+	}
+	if _, good, _, _ := Example(rulePrefix, "store.(*DB).Get"); good != `errors.New("store.DB.Get - not found")` {
+		t.Errorf("unexpected example of %s: %s", rulePrefix, good)
+	}
+	if _, _, _, err := Example("ERRCHAIN003", "store.Get"); err == nil {
+		t.Error("no error for an unknown rule")
+	}
+}
+
+func TestParsePrefix(t *testing.T) {
+	for msg, want := range map[string]Prefix{
+		"store: closed":              {Pkg: "store"},
+		"store.Open: closed":         {Pkg: "store", Func: "Open"},
+		"store.DB.Get: not found":    {Pkg: "store", Recv: "DB", Func: "Get"},
+		"store.(*DB).Get: not found": {Pkg: "store", Recv: "DB", Func: "Get", IsPtr: true},
+		"store.(*DB.Get: not found":  {},
+		"not found":                  {},
+	} {
+		got, err := ParsePrefix(msg)
+		if got != want || (err != nil) != (want == Prefix{}) {
+			t.Errorf("ParsePrefix(%q) = %+v, %v; want %+v", msg, got, err, want)
+		}
+	}
+
+	for diag, want := range map[string][]string{
+		diagnosticMessage + ": " + recommendationsLead + `"store: ", "store.(*DB).Get: "`:           {"store: ", "store.(*DB).Get: "},
+		diagnosticMessage + ": " + recommendationsLead + `"store: " (the message is set at line 3)`: {"store: "},
+		diagnosticMessage + ": " + errInvalidSyntax.Error() + `, expected "store.(*DB).Get: "`:      nil,
+	} {
+		if got := ExpectedPrefixes(diag); !reflect.DeepEqual(got, want) {
+			t.Errorf("ExpectedPrefixes(%q) = %q; want %q", diag, got, want)
+		}
+	}
+}
+
+func TestConstructors(t *testing.T) {
+	setFlags(t, map[string]string{"constructors": "github.com/pkg/errors.New,pkg/errors.Errorf:args=1," +
+		"pkg/errors.Wrap:message=1:wrapped=0,pkg/errors.Wrapf:message=1:args=2:wrapped=0"})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./constructors")
+
+	for _, value := range []string{"errors", "pkg/errors.New:message", "pkg/errors.New:args=0", "pkg/errors.Wrap:wrapped=0",
+		"pkg/errors.Wrapf:message=1:args=2:wrapped=3", "pkg/errors.New:limit=1"} {
+		var table constructorTable
+		if err := table.Set(value); err == nil {
+			t.Errorf("no error for %q", value)
+		}
+	}
+	var table constructorTable
+	const value = "pkg/errors.Wrapf:message=1:args=2:wrapped=0,example.com/errloc.New:self-locating"
+	if err := table.Set(value); err != nil || table.String() != value {
+		t.Errorf("Set(%q) = %v, String() = %q", value, err, table.String())
+	}
+}
+
+func TestGenericConstructors(t *testing.T) {
+	setFlags(t, map[string]string{"constructors": "example.com/errs.Wrap[T]:message=op:wrapped=err,errs.New:message=M," +
+		"errs.Annotate:message=msg:wrapped=E,errs.Box[T].Errorf:message=format:args=args"})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./generics")
+
+	var table constructorTable
+	const value = "example.com/errs.Wrap:message=op:wrapped=err,errs.Box.Errorf:message=format:args=args"
+	if err := table.Set("example.com/errs.Wrap[T]:message=op:wrapped=err,errs.Box[T].Errorf:message=format:args=args"); err != nil ||
+		table.String() != value {
+		t.Errorf("Set() = %v, String() = %q, want %q", err, table.String(), value)
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	setFlags(t, map[string]string{"coverage": "true"})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./coverage")
+
+	c, err := ParseCoverage("1 of 2 error paths of Store.Get are prefixed")
+	if want := (Coverage{Func: "Store.Get", Paths: 2, Prefixed: 1}); err != nil || c != want {
+		t.Errorf("ParseCoverage() = %+v, %v, want %+v", c, err, want)
+	}
+}
+
+func TestOpLiterals(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, Analyzer, "./oplit")
+}
+
+func TestDuplicateCauses(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, Analyzer, "./requote")
+}
+
+func TestComponentOrder(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, Analyzer, "./order")
+}
+
+func TestPassThrough(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./passthrough/lenient")
+
+	setFlags(t, map[string]string{"pass-through": "true"})
+	analysistest.RunWithSuggestedFixes(t, testdata, Analyzer, "./passthrough/strict")
+}
+
+func TestAliasPackages(t *testing.T) {
+	setFlags(t, map[string]string{"alias-packages": "true"})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./aliases/internal/transport")
+}
+
+func TestCallgraphAttribution(t *testing.T) {
+	setFlags(t, map[string]string{"attribution": "callgraph"})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./attribution")
+}
+
+func TestXerrors(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./xerrors/legacy")
+
+	setFlags(t, map[string]string{"wrapper-packages": "retry"})
+	analysistest.Run(t, testdata, Analyzer, "./xerrors/retry")
+}
+
+func TestLongPrefixes(t *testing.T) {
+	setFlags(t, map[string]string{"max-prefix-components": "3", "max-chain-length": "30"})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./chains")
+}
+
+func TestTypeDirectives(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./typedirective")
+}
+
+func TestCanonicalDirectives(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, Analyzer, "./canonical")
+}
+
+func TestConcatenation(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./concat")
+}
+
+func TestReceiverForms(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./receivers")
+}
+
+func TestPointerForm(t *testing.T) {
+	setFlags(t, map[string]string{"pointer-form": "true"})
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, Analyzer, "./pointerform")
+}
+
+func TestJoinComponents(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, Analyzer, "./join")
+}
+
+func TestAliasReceivers(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./recvalias")
+}
+
+func TestPrefixStyle(t *testing.T) {
+	setFlags(t, map[string]string{"style-consistency": "75"})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./style/consistent", "./style/balanced")
+}
+
+func TestDominantStyle(t *testing.T) {
+	setFlags(t, map[string]string{"dominant-style": "true"})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./style/mandatory")
+}
+
+func TestHTTPResponses(t *testing.T) {
+	setFlags(t, map[string]string{"http-responses": "true"})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./responses")
+}
+
+func TestPanicMessages(t *testing.T) {
+	setFlags(t, map[string]string{"constructors": "example.com/must.OK:message=1:wrapped=0:panics,must.Truef:message=1:args=2:panics"})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./panics")
+
+	var table constructorTable
+	const value = "example.com/must.OK:message=1:wrapped=0:panics"
+	if err := table.Set(value); err != nil || table.String() != value {
+		t.Errorf("Set(%q) = %v, String() = %q", value, err, table.String())
+	}
+}
+
+func TestUserMessages(t *testing.T) {
+	setFlags(t, map[string]string{"user-constructors": "example.com/usererr.New,usererr.Newf:message=1:args=2"})
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, Analyzer, "./usermsg")
+	// a package without functions takes the fast path of data packages
+	analysistest.Run(t, testdata, Analyzer, "./usermsgdata")
+}
+
+func TestWrapContext(t *testing.T) {
+	setFlags(t, map[string]string{"min-context-words": "1"})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./wrapcontext")
+}
+
+func TestReportFiles(t *testing.T) {
+	setFlags(t, map[string]string{"report-files": "reportfiles/reported.go"})
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./reportfiles")
+}
+
+func TestDataPackage(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "./datapkg")
+}
+
+// TestStableVars checks that variables assigned anywhere in the package aren't treated as constants.
+func TestStableVars(t *testing.T) {
+	for _, tt := range []struct {
+		src    string
+		stable bool
+	}{
+		{"func f() error { return errors.New(prefix + \"failed\") }", true},
+		{"func f() { prefix = \"users: \" }", false},
+		{"func f() { (prefix) = \"users: \" }", false},
+		{"func f() { for _, prefix = range names {} }", false},
+		{"func f() { for prefix = range map[string]bool{} {} }", false},
+		{"func f() { for _, prefix := range names { _ = prefix } }", true},
+		{"func f() { p := &prefix; _ = p }", false},
+		{"func f() { p := &(prefix); _ = p }", false},
+	} {
+		src := "package users\n\nimport \"errors\"\n\nvar _ = errors.New\n\nvar prefix = \"users: \"\n\nvar names = []string{\"a\"}\n\n" + tt.src + "\n"
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "", src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		info := &types.Info{Defs: make(map[*ast.Ident]types.Object), Uses: make(map[*ast.Ident]types.Object),
+			Types: make(map[ast.Expr]types.TypeAndValue)}
+		pkg, err := (&types.Config{Importer: importer.Default()}).Check("users", fset, []*ast.File{file}, info)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pass := &analysis.Pass{Fset: fset, Files: []*ast.File{file}, Pkg: pkg, TypesInfo: info}
+		_, stable := stableVars(pass)[pkg.Scope().Lookup("prefix").(*types.Var)]
+		if stable != tt.stable {
+			t.Errorf("stableVars() for %q: stable = %v, want %v", tt.src, stable, tt.stable)
+		}
+	}
 }
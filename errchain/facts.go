@@ -0,0 +1,154 @@
+package errchain
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// PrefixedErrorFunc is a fact about a function whose every error it constructs or returns
+// is verified to be prefixed with the function's location. Returning an error of such a function
+// as is doesn't lose the location information.
+type PrefixedErrorFunc struct{}
+
+// AFact implements analysis.Fact.
+func (*PrefixedErrorFunc) AFact() {}
+
+func (*PrefixedErrorFunc) String() string {
+	return "PrefixedErrorFunc"
+}
+
+// exportPrefixedErrorFacts exports PrefixedErrorFunc facts for the functions of the package.
+// A function returning an error of another function of the package is verified once that function is,
+// so the verification is repeated until nothing changes.
+func exportPrefixedErrorFacts(pass *analysis.Pass, index *packageIndex) map[*types.Func]bool {
+	verified := make(map[*types.Func]bool)
+
+	var candidates []*funcInfo
+	for _, file := range pass.Files {
+		if isTest(pass, file) {
+			continue
+		}
+		for _, decl := range file.Decls {
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Body != nil {
+				// a function returning only a collection of errors has no error result to verify
+				errIndex, _ := errorResultIndex(funcDecl)
+				if fn := index.funcs[funcDecl]; fn != nil && errIndex >= 0 && isReturnsError(funcDecl) {
+					candidates = append(candidates, fn)
+				}
+			}
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, fn := range candidates {
+			obj, ok := pass.TypesInfo.Defs[fn.decl.Name].(*types.Func)
+			if !ok || verified[obj] {
+				continue
+			}
+			if isPrefixedErrorFunc(pass, fn, verified) {
+				verified[obj] = true
+				changed = true
+			}
+		}
+	}
+
+	// facts are exported in the order of declarations so that the output is deterministic
+	for _, fn := range candidates {
+		if obj, ok := pass.TypesInfo.Defs[fn.decl.Name].(*types.Func); ok && verified[obj] {
+			pass.ExportObjectFact(obj, new(PrefixedErrorFunc))
+		}
+	}
+	return verified
+}
+
+// isPrefixedErrorFunc tells whether every error the function constructs or returns is prefixed.
+func isPrefixedErrorFunc(pass *analysis.Pass, fn *funcInfo, verified map[*types.Func]bool) bool {
+	rules, ok := funcRules(pass.Pkg, fn.decl)
+	if !ok {
+		return false
+	}
+	errIndex, last := errorResultIndex(fn.decl)
+
+	prefixed := true
+	ast.Inspect(fn.decl.Body, func(node ast.Node) bool {
+		if !prefixed {
+			return false
+		}
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			// errors of function literals are not returned by the function itself
+			return false
+		case *ast.CallExpr:
+			if check, ok := checkCall(pass, fn, rules, node); ok && check.err != nil {
+				prefixed = false
+			}
+		case *ast.ReturnStmt:
+			switch {
+			case len(node.Results) == 0:
+				// naked return of named results can't be verified
+				prefixed = last < 0
+			case len(node.Results) == 1 && last > 0:
+				// return f()
+				prefixed = isPrefixedErrorCall(pass, fn, rules, node.Results[0], verified)
+			case errIndex < len(node.Results):
+				prefixed = isPrefixedErrorExpr(pass, fn, rules, node.Results[errIndex], verified)
+			}
+		}
+		return true
+	})
+	return prefixed
+}
+
+// isPrefixedErrorExpr tells whether an error expression is nil or an error verified to be prefixed.
+func isPrefixedErrorExpr(pass *analysis.Pass, fn *funcInfo, rules componentRules, expr ast.Expr, verified map[*types.Func]bool) bool {
+	expr = astutil.Unparen(expr)
+	if tv, ok := pass.TypesInfo.Types[expr]; ok && tv.IsNil() {
+		return true
+	}
+	return isPrefixedErrorCall(pass, fn, rules, expr, verified)
+}
+
+// isPrefixedErrorCall tells whether an expression is a call of an error constructor with a correctly prefixed
+// message, a call of a function known to return prefixed errors or a combination of such errors.
+func isPrefixedErrorCall(pass *analysis.Pass, fn *funcInfo, rules componentRules, expr ast.Expr, verified map[*types.Func]bool) bool {
+	call, ok := astutil.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	if args, ok := multiErrorArgs(pass, call); ok {
+		// a combined error is prefixed if all its components are
+		if call.Ellipsis.IsValid() {
+			return false
+		}
+		for _, arg := range args {
+			if !isPrefixedErrorExpr(pass, fn, rules, arg, verified) {
+				return false
+			}
+		}
+		return true
+	}
+	if check, ok := checkCall(pass, fn, rules, call); ok {
+		return check.err == nil
+	}
+	if isSelfLocatingCall(pass, call) {
+		return true
+	}
+	return isPrefixedErrorCallee(pass, call, verified)
+}
+
+// isPrefixedErrorCallee tells whether a function called is known to return prefixed errors.
+func isPrefixedErrorCallee(pass *analysis.Pass, call *ast.CallExpr, verified map[*types.Func]bool) bool {
+	callee, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok {
+		return false
+	}
+	if callee.Pkg() == pass.Pkg {
+		return verified[callee]
+	}
+	return pass.ImportObjectFact(callee, new(PrefixedErrorFunc))
+}
@@ -0,0 +1,92 @@
+package errchain
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// isDataPackage tells whether a package has nothing to check but its package-level declarations, e.g. a package
+// of data types. None of its functions returns an error or, with -http-responses, an http.HandlerFunc, none of
+// its types has an error field a method may store a constructed error in, and no flag makes the analyzer look
+// into other functions, e.g. panicking helpers set by -constructors. It is decided by the declarations and
+// the types, so the bodies of the functions aren't walked.
+func isDataPackage(pass *analysis.Pass) bool {
+	switch {
+	case config.whySkipped, hasPanickingConstructors(), len(config.registrars) > 0,
+		config.enabled(ruleDuplicateMessage), config.enabled(ruleWrapper) && config.wrapperPackages.match(pass.Pkg.Path()):
+		return false
+	}
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			if i, _ := resultIndex(funcDecl, isErrorOrCollection); i >= 0 {
+				return false
+			}
+			if config.enabled(ruleHTTPResponse) && returnsHandlerFunc(pass, funcDecl) {
+				return false
+			}
+		}
+	}
+	scope := pass.Pkg.Scope()
+	for _, name := range scope.Names() {
+		if tn, ok := scope.Lookup(name).(*types.TypeName); ok && hasErrorField(tn.Type(), make(map[types.Type]bool)) {
+			return false
+		}
+	}
+	return true
+}
+
+// runDataPackage checks a package of data types, see isDataPackage: the directives, the namespace,
+// the package-level declarations, including init functions, and the messages of user-facing errors.
+func runDataPackage(pass *analysis.Pass, index *packageIndex) (interface{}, error) {
+	index.lazy = lazyFuncs(pass)
+	index.tables = tableFuncs(pass)
+	typeDirectives(pass, index)
+	defer loadPackageState(pass)()
+	checkNamespace(pass)
+	for _, file := range pass.Files {
+		if isTest(pass, file) || isSkippedGenerated(pass, file) || isSkippedMock(pass, file) {
+			continue
+		}
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Name.Name == "init" && decl.Recv == nil {
+					handleFuncDecl(pass, index, decl)
+				}
+			case *ast.GenDecl:
+				handleGenDecl(pass, index, decl)
+			}
+		}
+		handleUserMessages(pass, file)
+	}
+	return index, nil
+}
+
+// hasErrorField tells whether a struct type has a field of the error type, possibly in a nested struct
+// or a struct pointed to.
+func hasErrorField(t types.Type, seen map[types.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return false
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i).Type()
+		if ptr, ok := field.(*types.Pointer); ok {
+			field = ptr.Elem()
+		}
+		if isErrorType(field) || hasErrorField(field, seen) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,367 @@
+package errchain
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// opConst is a name of the constant introduced by the fixes to hold the function's location.
+const opConst = "op"
+
+// A finding is a diagnostic about an error constructor call which is not reported yet.
+type finding struct {
+	diag  analysis.Diagnostic
+	call  *ast.CallExpr
+	check callCheck
+}
+
+// rule returns the code of the rule a finding belongs to.
+func (f *finding) rule() string {
+	if f.check.err != nil {
+		switch f.check.err.errType {
+		case errDynamicPrefix:
+			return ruleDynamicPrefix
+		case errStaleFile:
+			return ruleStaleFile
+		case errFormatVerb, errFormatArgs:
+			return ruleFormatVerb
+		case errPassThrough:
+			return rulePassThrough
+		}
+	}
+	return rulePrefix
+}
+
+// suggestFixes attaches suggested fixes to the findings of a function body.
+// A message without any prefix gets the canonical prefix of the function. If there are several such messages
+// in a function, the fix instead introduces a "const op" declaration holding the location and uses it
+// in all the messages. The op fix is attached to the first finding only since all its edits are applied together.
+// The components of errors.Join and the like get a fix each, since every one of them is a line of the joined error,
+// unless the op fix rewrites them along with the other messages.
+func suggestFixes(pass *analysis.Pass, fn *funcInfo, body ast.Node, findings []*finding) {
+	if config.dialect == dialectFile {
+		// line numbers of file prefixes are maintained by code generators
+		return
+	}
+	prefix := canonicalPrefix(pass.Pkg, fn)
+
+	joined := joinedCalls(pass, body)
+	var fixable, components []*finding
+	for _, f := range findings {
+		if f.check.err.errType != errNoPrefix || messageLiteral(f.call, f.check.ctor) == nil || f.call.Ellipsis.IsValid() {
+			continue
+		}
+		// A message with a malformed prefix is not fixed since the prefix would be duplicated.
+		if _, err := parsePrefix(f.check.message); err != errNoPrefix || hasDefaultSeparatorPrefix(f.check.message) {
+			continue
+		}
+		if joined[f.call] {
+			components = append(components, f)
+		} else {
+			fixable = append(fixable, f)
+		}
+	}
+
+	block, isBlock := body.(*ast.BlockStmt)
+	if len(fixable) > 1 && isBlock && len(block.List) > 0 && !usesIdent(fn.decl, opConst) {
+		first := block.List[0]
+		indent := strings.Repeat("\t", pass.Fset.Position(first.Pos()).Column-1)
+		edits := []analysis.TextEdit{{
+			Pos:     first.Pos(),
+			End:     first.Pos(),
+			NewText: []byte("const " + opConst + " = " + strconv.Quote(prefix) + "\n\n" + indent),
+		}}
+		for _, f := range append(fixable, components...) {
+			edits = append(edits, opMessageEdit(f))
+		}
+		fixable[0].diag.SuggestedFixes = append(fixable[0].diag.SuggestedFixes, analysis.SuggestedFix{
+			Message:   "Introduce const " + opConst + " = " + strconv.Quote(prefix) + " and start error messages with it",
+			TextEdits: edits,
+		})
+		return
+	}
+
+	sep := string(config.separator)
+	for _, f := range findings {
+		switch {
+		case f.check.err.errType == errInvalidSyntax && f.check.err.expect != "":
+			suggestRepair(f)
+		case f.check.err.errType == errSiblingMethod:
+			suggestSibling(f)
+		case f.check.err.errType == errNoPointer:
+			suggestValueForm(f)
+		case f.check.err.errType == errComponentOrder:
+			suggestPrefix(f, "Reorder the prefix to ")
+		case f.check.err.errType == errPackageMissing:
+			suggestPrefix(f, "Add the package to the prefix: ")
+		case f.check.err.errType == errPassThrough:
+			if lit := messageLiteral(f.call, f.check.ctor); lit != nil {
+				f.diag.SuggestedFixes = append(f.diag.SuggestedFixes, analysis.SuggestedFix{
+					Message: "Add prefix " + strconv.Quote(prefix+sep),
+					TextEdits: []analysis.TextEdit{{
+						Pos:     lit.Pos(),
+						End:     lit.End(),
+						NewText: []byte(strconv.Quote(prefix + sep + f.check.message)),
+					}},
+				})
+			}
+		}
+	}
+	for _, f := range append(fixable, components...) {
+		suggestAddPrefix(f, prefix)
+	}
+}
+
+// suggestAddPrefix attaches a fix starting the message literal of a finding with a prefix.
+func suggestAddPrefix(f *finding, prefix string) {
+	sep := string(config.separator)
+	lit := messageLiteral(f.call, f.check.ctor)
+	value, _ := strconv.Unquote(lit.Value)
+	f.diag.SuggestedFixes = append(f.diag.SuggestedFixes, analysis.SuggestedFix{
+		Message: "Add prefix " + strconv.Quote(prefix+sep),
+		TextEdits: []analysis.TextEdit{{
+			Pos:     lit.Pos(),
+			End:     lit.End(),
+			NewText: []byte(strconv.Quote(prefix + sep + value)),
+		}},
+	})
+}
+
+// suggestRepair attaches a fix repairing just the malformed receiver of a prefix, see repairPrefix.
+// The fix is suggested only if the prefix is written in the literal as is, without escape sequences or formatting verbs.
+func suggestRepair(f *finding) {
+	lit := messageLiteral(f.call, f.check.ctor)
+	if lit == nil {
+		return
+	}
+	repair, ok := repairPrefix(f.check.message)
+	if !ok || !strings.HasPrefix(lit.Value[1:], f.check.message[:repair.end]) {
+		return
+	}
+	f.diag.SuggestedFixes = append(f.diag.SuggestedFixes, analysis.SuggestedFix{
+		Message: "Change the receiver to " + repair.text,
+		TextEdits: []analysis.TextEdit{{
+			Pos:     lit.Pos() + 1 + token.Pos(repair.start),
+			End:     lit.Pos() + 1 + token.Pos(repair.end),
+			NewText: []byte(repair.text),
+		}},
+	})
+}
+
+// suggestSibling attaches a fix replacing just the method of a prefix referring to a sibling method with the method
+// the message is in, e.g. "Save" with "Load" in "pkg.Struct.Save: ". As suggestRepair, it needs the prefix written
+// in the literal as is.
+func suggestSibling(f *finding) {
+	lit := messageLiteral(f.call, f.check.ctor)
+	if lit == nil {
+		return
+	}
+	end := strings.Index(f.check.message, string(config.separator))
+	start := end - len(f.check.err.got)
+	if start <= 0 || f.check.message[start-1] != '.' || f.check.message[start:end] != f.check.err.got ||
+		!strings.HasPrefix(lit.Value[1:], f.check.message[:end]) {
+		return
+	}
+	f.diag.SuggestedFixes = append(f.diag.SuggestedFixes, analysis.SuggestedFix{
+		Message: "Change the method to " + f.check.err.expect,
+		TextEdits: []analysis.TextEdit{{
+			Pos:     lit.Pos() + 1 + token.Pos(start),
+			End:     lit.Pos() + 1 + token.Pos(end),
+			NewText: []byte(f.check.err.expect),
+		}},
+	})
+}
+
+// suggestValueForm attaches a fix replacing the pointer form of the receiver of a prefix with the value form,
+// e.g. "(*Point)" with "Point" in "pkg.(*Point).Validate: ", keeping the spelling of the message.
+// As suggestRepair, it needs the prefix written in the literal as is.
+func suggestValueForm(f *finding) {
+	lit := messageLiteral(f.call, f.check.ctor)
+	if lit == nil {
+		return
+	}
+	head := f.check.message[:strings.Index(f.check.message, string(config.separator))]
+	start := strings.Index(head, "(*")
+	end := strings.Index(head, ")") + 1
+	if start < 0 || end <= start || !strings.HasPrefix(lit.Value[1:], head) {
+		return
+	}
+	recv := head[start+2 : end-1]
+	f.diag.SuggestedFixes = append(f.diag.SuggestedFixes, analysis.SuggestedFix{
+		Message: "Change the receiver to " + recv,
+		TextEdits: []analysis.TextEdit{{
+			Pos:     lit.Pos() + 1 + token.Pos(start),
+			End:     lit.Pos() + 1 + token.Pos(end),
+			NewText: []byte(recv),
+		}},
+	})
+}
+
+// suggestPrefix attaches a fix replacing the whole prefix of a message with the expected one, e.g. "Struct.Method: "
+// with "pkg.Struct.Method: ". As suggestRepair, it needs the prefix written in the literal as is.
+func suggestPrefix(f *finding, fixMessage string) {
+	lit := messageLiteral(f.call, f.check.ctor)
+	if lit == nil {
+		return
+	}
+	sep := string(config.separator)
+	end := strings.Index(f.check.message, sep) + len(sep)
+	if end < len(sep) || !strings.HasPrefix(lit.Value[1:], f.check.message[:end]) {
+		return
+	}
+	f.diag.SuggestedFixes = append(f.diag.SuggestedFixes, analysis.SuggestedFix{
+		Message: fixMessage + strconv.Quote(f.check.err.expect),
+		TextEdits: []analysis.TextEdit{{
+			Pos:     lit.Pos() + 1,
+			End:     lit.Pos() + 1 + token.Pos(end),
+			NewText: []byte(f.check.err.expect),
+		}},
+	})
+}
+
+// opMessageEdit rewrites a message to start with the op constant: errors.New("msg") becomes
+// errors.New(op + ": msg") and fmt.Errorf("msg %d", n) becomes fmt.Errorf("%s: msg %d", op, n).
+// The op is passed as the first formatted argument only if the arguments follow the message right away.
+func opMessageEdit(f *finding) analysis.TextEdit {
+	lit := messageLiteral(f.call, f.check.ctor)
+	value, _ := strconv.Unquote(lit.Value)
+
+	var newText string
+	if f.check.ctor.args != f.check.ctor.message+1 {
+		newText = opConst + " + " + strconv.Quote(string(config.separator)+value)
+	} else {
+		newText = strconv.Quote("%s"+string(config.separator)+value) + ", " + opConst
+	}
+	return analysis.TextEdit{Pos: lit.Pos(), End: lit.End(), NewText: []byte(newText)}
+}
+
+// hasDefaultSeparatorPrefix tells whether a message starts with a location followed by ": " while another separator
+// is configured, e.g. "pkg.Get: not found" with " - ". Such a prefix has to be fixed by hand rather than duplicated.
+func hasDefaultSeparatorPrefix(message string) bool {
+	const defaultSeparator = ": "
+	if config.separator == defaultSeparator {
+		return false
+	}
+	i := strings.Index(message, defaultSeparator)
+	return i > 0 && strings.Contains(message[:i], ".") && !strings.ContainsAny(message[:i], " \t")
+}
+
+// canonicalPrefix returns the most specific location of a function without a separator, e.g. "pkg.Struct.Method".
+func canonicalPrefix(pkg *types.Package, fn *funcInfo) string {
+	name := packageNames(pkg)[0]
+	switch {
+	case len(fn.callers) == 1:
+		// the helper of a single function adds the location of that function
+		return canonicalPrefix(pkg, fn.callers[0])
+	case fn.name == "":
+		return name
+	case fn.recv != "":
+		// a method promoted to an exported type is called as a method of that type outside of the package
+		return name + "." + fn.recvNames()[0] + "." + fn.name
+	}
+	return name + "." + fn.name
+}
+
+// CanonicalPrefix returns the prefix of error messages of a function under the current flags of Analyzer,
+// e.g. "pkg.Type.Method: " for "pkg.Type.Method" or "pkg.(*Type).Method". A package name alone gives
+// the prefix of package-level errors.
+func CanonicalPrefix(fn string) (string, error) {
+	loc, err := parseFuncName(fn)
+	if err != nil {
+		return "", err
+	}
+	return loc.String() + string(config.separator), nil
+}
+
+// A Prefix is the location prefix of an error message split into its components.
+type Prefix struct {
+	Pkg   string
+	Recv  string // receiver type name without the pointer, empty for functions
+	Func  string
+	IsPtr bool // the receiver is written in the pointer form, e.g. "pkg.(*Type).Method: "
+}
+
+// ParsePrefix parses the location prefix of an error message under the current separator of Analyzer,
+// e.g. "pkg.(*Type).Method: not found". It fails if the message has no prefix or it is malformed.
+func ParsePrefix(message string) (Prefix, error) {
+	loc, err := parsePrefix(message)
+	if err != nil {
+		return Prefix{}, fmt.Errorf("ParsePrefix: %w", err)
+	}
+	return Prefix{Pkg: loc.pkg, Recv: loc.recv, Func: loc.fn, IsPtr: loc.isRecvPtr}, nil
+}
+
+// ExpectedPrefixes returns the prefixes a diagnostic message of Analyzer recommends, e.g. "pkg: " and
+// "pkg.Func: ", or nil if it recommends none.
+func ExpectedPrefixes(diagnostic string) []string {
+	i := strings.Index(diagnostic, recommendationsLead)
+	if i < 0 {
+		return nil
+	}
+	var prefixes []string
+	rest := diagnostic[i+len(recommendationsLead):]
+	for {
+		quoted, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			return prefixes
+		}
+		prefix, _ := strconv.Unquote(quoted)
+		prefixes = append(prefixes, prefix)
+		if rest = rest[len(quoted):]; !strings.HasPrefix(rest, ", ") {
+			return prefixes
+		}
+		rest = rest[2:]
+	}
+}
+
+// parseFuncName parses a location written without a separator, like "pkg.(*Type).Method".
+func parseFuncName(fn string) (location, error) {
+	loc, err := parsePrefix(fn + string(config.separator))
+	if err != nil || !token.IsIdentifier(loc.pkg) {
+		return location{}, fmt.Errorf("invalid function %q, expected pkg.Func or pkg.Type.Method", fn)
+	}
+	return loc, nil
+}
+
+// String returns the location in the form of prefixes, e.g. "pkg.Type.Method". The pointer form of the receiver
+// is dropped as the recommendations do.
+func (loc location) String() string {
+	s := loc.pkg
+	if loc.recv != "" {
+		s += "." + loc.recv
+	}
+	if loc.fn != "" {
+		s += "." + loc.fn
+	}
+	return s
+}
+
+// messageLiteral returns the string literal an error message is built from or nil if the message is not a literal.
+func messageLiteral(call *ast.CallExpr, ctor constructor) *ast.BasicLit {
+	lit, ok := ctor.messageArg(call).(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return nil
+	}
+	return lit
+}
+
+// usesIdent tells whether a name is declared or used in a function, so a new declaration could clash with it.
+func usesIdent(decl *ast.FuncDecl, name string) bool {
+	if decl == nil {
+		return true
+	}
+	found := false
+	ast.Inspect(decl, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok && ident.Name == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,271 @@
+package errchain
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// delegateHelpers records the callers of unexported helper functions of the package which are called only
+// by checked functions: exported ones and function values. Prefixes of such a helper may refer to its callers,
+// e.g. "pkg.Get: " in doGet called by Get, since the helper does the work on their behalf.
+// A helper called anywhere else, e.g. by another unexported function or in a package-level declaration, isn't recorded.
+//
+// Without config.interprocedural only forwarders are taken as callers, see forwardee: the chain of
+// func Do(x int) error { return do(x) } shows the prefix of do, so its messages are checked with the prefix of Do.
+// With the callgraph attribution the callers are found through chains of helpers, see reachingEntryPoints.
+func delegateHelpers(pass *analysis.Pass, index *packageIndex) {
+	helpers := make(map[*types.Func]*funcInfo)
+	for decl, fn := range index.funcs {
+		if !ast.IsExported(fn.name) && !index.funcValues[decl] {
+			if obj, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func); ok {
+				helpers[obj] = fn
+			}
+		}
+	}
+	if config.attribution == attributionCallgraph {
+		reachingEntryPoints(pass, index, helpers)
+		return
+	}
+
+	callers := make(map[*funcInfo][]*funcInfo)
+	excluded := make(map[*funcInfo]bool)
+	for _, file := range pass.Files {
+		if isTest(pass, file) {
+			continue
+		}
+		for _, decl := range file.Decls {
+			var own, caller *funcInfo
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok {
+				own = index.funcs[funcDecl]
+				if isCheckedFunc(index, funcDecl) && (config.interprocedural || forwardee(pass, funcDecl) != nil) {
+					caller = own
+				}
+			}
+			ast.Inspect(decl, func(node ast.Node) bool {
+				call, ok := node.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				callee, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+				if !ok {
+					return true
+				}
+				helper := helpers[callee]
+				switch {
+				case helper == nil || helper == own:
+					// recursive calls don't make a helper reachable from elsewhere
+				case caller == nil:
+					excluded[helper] = true
+				case !containsFunc(callers[helper], caller):
+					callers[helper] = append(callers[helper], caller)
+				}
+				return true
+			})
+		}
+	}
+
+	for helper, list := range callers {
+		if !excluded[helper] {
+			helper.callers = list
+		}
+	}
+}
+
+// reachingEntryPoints records the callers of helpers for the callgraph attribution: the checked functions
+// reaching a helper through chains of calls of other helpers, e.g. Get for fetch in Get -> load -> fetch.
+// The callers are ordered as they are declared. A helper called anywhere else, e.g. in a package-level
+// declaration or by an unexported function no checked function reaches, isn't recorded, and neither are
+// the helpers it calls.
+func reachingEntryPoints(pass *analysis.Pass, index *packageIndex, helpers map[*types.Func]*funcInfo) {
+	isHelper := make(map[*funcInfo]bool, len(helpers))
+	for _, helper := range helpers {
+		isHelper[helper] = true
+	}
+
+	var roots []*funcInfo
+	calledBy := make(map[*funcInfo][]*funcInfo) // helper -> functions calling it
+	excluded := make(map[*funcInfo]bool)
+	for _, file := range pass.Files {
+		if isTest(pass, file) {
+			continue
+		}
+		for _, decl := range file.Decls {
+			var own *funcInfo
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok {
+				own = index.funcs[funcDecl]
+				if isCheckedFunc(index, funcDecl) {
+					roots = append(roots, own)
+				} else if !isHelper[own] || funcDecl.Name.Name == "init" {
+					own = nil
+				}
+			}
+			ast.Inspect(decl, func(node ast.Node) bool {
+				call, ok := node.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				callee, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+				if !ok {
+					return true
+				}
+				helper := helpers[callee]
+				switch {
+				case helper == nil || helper == own:
+				case own == nil:
+					excluded[helper] = true
+				case !containsFunc(calledBy[helper], own):
+					calledBy[helper] = append(calledBy[helper], own)
+				}
+				return true
+			})
+		}
+	}
+
+	// reached are the checked functions reaching a helper, computed up to a fixed point to follow cycles
+	reached := make(map[*funcInfo]map[*funcInfo]bool)
+	for changed := true; changed; {
+		changed = false
+		for helper, list := range calledBy {
+			if reached[helper] == nil {
+				reached[helper] = make(map[*funcInfo]bool)
+			}
+			for _, caller := range list {
+				sources := map[*funcInfo]bool{caller: true}
+				if isHelper[caller] {
+					sources = reached[caller]
+				}
+				for root := range sources {
+					if !reached[helper][root] {
+						reached[helper][root] = true
+						changed = true
+					}
+				}
+			}
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for helper, list := range calledBy {
+			for _, caller := range list {
+				if !excluded[helper] && isHelper[caller] && (excluded[caller] || len(reached[caller]) == 0) {
+					excluded[helper] = true
+					changed = true
+				}
+			}
+		}
+	}
+
+	for helper := range calledBy {
+		if excluded[helper] {
+			continue
+		}
+		for _, root := range roots {
+			if reached[helper][root] {
+				helper.callers = append(helper.callers, root)
+			}
+		}
+	}
+}
+
+// forwardee returns the function a forwarder calls: a function whose body is a single return statement
+// of a call, like func (s *Store) Get(key string) (string, error) { return s.get(key) }.
+func forwardee(pass *analysis.Pass, funcDecl *ast.FuncDecl) *types.Func {
+	if funcDecl.Body == nil || len(funcDecl.Body.List) != 1 {
+		return nil
+	}
+	ret, ok := funcDecl.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return nil
+	}
+	call, ok := ret.Results[0].(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	callee, _ := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	return callee
+}
+
+// isCheckedFunc tells whether error messages of a function declaration are checked: it is exported
+// or referenced as a value, and it isn't an init function.
+func isCheckedFunc(index *packageIndex, funcDecl *ast.FuncDecl) bool {
+	if funcDecl.Name.Name == "init" && funcDecl.Recv == nil {
+		return false
+	}
+	return ast.IsExported(funcDecl.Name.Name) || index.funcValues[funcDecl]
+}
+
+// escapingHelpers returns the helpers recorded by delegateHelpers whose errors are returned as is by their callers
+// and aren't verified to be prefixed. Either a helper or its callers have to add the prefix, so messages of other
+// helpers are not checked. With the callgraph attribution errors returned as is by escaping helpers escape too.
+func escapingHelpers(pass *analysis.Pass, index *packageIndex) map[*types.Func]bool {
+	escaping := make(map[*types.Func]bool)
+	checked := make(map[*funcInfo]bool)
+	var queue []*types.Func
+	for _, fn := range index.funcs {
+		for _, caller := range fn.callers {
+			if checked[caller] || !isReturnsError(caller.decl) {
+				continue
+			}
+			checked[caller] = true
+			unprefixedReturns(pass, index, caller, func(_ ast.Expr, callee *types.Func) {
+				if !escaping[callee] {
+					escaping[callee] = true
+					queue = append(queue, callee)
+				}
+			})
+		}
+	}
+	if config.attribution != attributionCallgraph {
+		return escaping
+	}
+
+	decls := make(map[*types.Func]*funcInfo)
+	for decl, fn := range index.funcs {
+		if obj, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func); ok {
+			decls[obj] = fn
+		}
+	}
+	for len(queue) > 0 {
+		helper := decls[queue[0]]
+		queue = queue[1:]
+		if helper == nil || len(helper.callers) == 0 || !isReturnsError(helper.decl) {
+			continue
+		}
+		unprefixedReturns(pass, index, helper, func(_ ast.Expr, callee *types.Func) {
+			if !escaping[callee] {
+				escaping[callee] = true
+				queue = append(queue, callee)
+			}
+		})
+	}
+	return escaping
+}
+
+// handleHelper checks error messages of a helper function recorded by delegateHelpers if its errors escape unprefixed.
+func handleHelper(pass *analysis.Pass, index *packageIndex, funcDecl *ast.FuncDecl) bool {
+	fn := index.funcs[funcDecl]
+	if fn == nil || len(fn.callers) == 0 {
+		return false
+	}
+	obj, ok := pass.TypesInfo.Defs[funcDecl.Name].(*types.Func)
+	if !ok || !index.escaping[obj] {
+		return true
+	}
+	if rules, ok := funcRules(pass.Pkg, funcDecl); ok {
+		handleFuncBody(pass, index, fn, rules, funcDecl.Body)
+		handleOpLiterals(pass, funcDecl)
+	}
+	return true
+}
+
+func containsFunc(list []*funcInfo, fn *funcInfo) bool {
+	for _, elem := range list {
+		if elem == fn {
+			return true
+		}
+	}
+	return false
+}
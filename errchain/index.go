@@ -0,0 +1,565 @@
+package errchain
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// A packageIndex describes declarations of a package which error prefixes can refer to.
+// It is built once per package and is the result of the Analyzer.
+type packageIndex struct {
+	funcs map[*ast.FuncDecl]*funcInfo
+	names map[string]bool       // package level functions
+	types map[string]*typeIndex // named types including interfaces
+
+	// funcValues are unexported functions and methods referenced as values, e.g. method values
+	// passed as callbacks. They are checked like exported ones since their errors escape the package.
+	funcValues map[*ast.FuncDecl]bool
+
+	// registered are function literals passed to the registration functions set by config.registrars.
+	registered map[*ast.FuncLit]bool
+
+	// lazy are function literals passed to sync.OnceFunc, sync.OnceValue and sync.OnceValues
+	// in package-level variable declarations.
+	lazy map[*ast.FuncLit]bool
+
+	// tables are function literals stored in composite literals initializing package-level variables,
+	// e.g. the values of a map of validators.
+	tables map[*ast.FuncLit]bool
+
+	// prefixed are functions of the package which errors are verified to be prefixed.
+	prefixed map[*types.Func]bool
+
+	// escaping are helpers whose errors are returned as is by their callers, see escapingHelpers.
+	escaping map[*types.Func]bool
+}
+
+// A typeIndex describes a named type declared in the package.
+type typeIndex struct {
+	methods     map[string]bool
+	isInterface bool
+	alias       string // the receiver name set by a prefix directive, see typeDirectives
+	exempt      bool   // the methods are exempt from the checks by a directive
+}
+
+// A funcInfo describes a function declaration error prefixes are matched against.
+type funcInfo struct {
+	decl      *ast.FuncDecl
+	name      string
+	recv      string // receiver type name, empty for functions and for methods of unsupported receivers
+	isRecvPtr bool
+	isMethod  bool
+
+	// ptrMethods tells that the receiver type has methods with pointer receivers, so prefixes of any of its methods
+	// may use the pointer form, see pointerMethods.
+	ptrMethods bool
+
+	// recvAlias is the receiver name prefixes must use instead of recv, set by a prefix directive of the type.
+	// exempt tells that the methods of the type are exempt from the checks. See typeDirectives.
+	recvAlias string
+	exempt    bool
+
+	// embedders are exported types of the package the method is promoted to by embedding its unexported
+	// receiver, e.g. Server for (*conn).Close if Server embeds *conn. See promoteMethods.
+	embedders []string
+
+	// named is the name of the type an alias receiver refers to, e.g. serviceImpl for func (s *svc) Get()
+	// if type svc = serviceImpl. See aliasReceivers.
+	named string
+
+	// callers are the checked functions an unexported helper is called by if it isn't called anywhere else,
+	// e.g. Get for doGet. Prefixes of the helper may refer to them. See delegateHelpers.
+	callers []*funcInfo
+}
+
+func newPackageIndex(files []*ast.File) *packageIndex {
+	index := &packageIndex{
+		funcs: make(map[*ast.FuncDecl]*funcInfo),
+		names: make(map[string]bool),
+		types: make(map[string]*typeIndex),
+	}
+
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Name == nil {
+					continue
+				}
+				fn := &funcInfo{
+					decl:     decl,
+					name:     decl.Name.Name,
+					isMethod: decl.Recv != nil && len(decl.Recv.List) > 0,
+				}
+				fn.recv, fn.isRecvPtr = recvString(decl)
+				index.funcs[decl] = fn
+
+				if !fn.isMethod {
+					index.names[fn.name] = true
+				} else if fn.recv != "" {
+					index.typ(fn.recv).methods[fn.name] = true
+				}
+
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					typeSpec, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					t := index.typ(typeSpec.Name.Name)
+					if iface, ok := typeSpec.Type.(*ast.InterfaceType); ok {
+						t.isInterface = true
+						for _, m := range iface.Methods.List {
+							for _, name := range m.Names {
+								t.methods[name.Name] = true
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return index
+}
+
+// recvNames returns the receiver names prefixes of the function may refer to: the exported types the method
+// is promoted to, if any, followed by the declared receiver and the type it refers to if it is an alias.
+// Functions have a single empty receiver name.
+func (fn *funcInfo) recvNames() []string {
+	names := append(fn.embedders[:len(fn.embedders):len(fn.embedders)], fn.prefixRecv())
+	if fn.named != "" && fn.recvAlias == "" {
+		names = append(names, fn.named)
+	}
+	return names
+}
+
+// isOwnRecv tells whether a receiver name of recvNames names the receiver type itself rather than a type
+// the method is promoted to, so the pointer form of the receiver applies to it.
+func (fn *funcInfo) isOwnRecv(name string) bool {
+	return name == fn.prefixRecv() || name == fn.named && fn.recvAlias == ""
+}
+
+// otherRecvNames returns the names prefixes of the method may refer to instead of the receiver: the types
+// the method is promoted to and the type an alias receiver refers to, unless a prefix directive sets the name.
+func (fn *funcInfo) otherRecvNames() []string {
+	if fn.named == "" || fn.recvAlias != "" {
+		return fn.embedders
+	}
+	return append(fn.embedders[:len(fn.embedders):len(fn.embedders)], fn.named)
+}
+
+// pointerForm tells whether prefixes of the method may refer to the receiver in the pointer form,
+// "pkg.(*Struct).Method".
+func (fn *funcInfo) pointerForm() bool {
+	return fn.isRecvPtr || fn.ptrMethods
+}
+
+// prefixRecv returns the receiver name of the method in prefixes: the one set by a prefix directive
+// of the receiver type or the declared one.
+func (fn *funcInfo) prefixRecv() string {
+	if fn.recvAlias != "" {
+		return fn.recvAlias
+	}
+	return fn.recv
+}
+
+// promoteMethods records the exported types of the package which promote methods of embedded unexported types,
+// e.g. Server in type Server struct{ *conn } promotes (*conn).Close. Prefixes of such a method may refer to
+// the exported type, "pkg.Server.Close: ", since that is how the method is called outside of the package.
+func promoteMethods(pass *analysis.Pass, index *packageIndex) {
+	methods := make(map[*types.Func]*funcInfo)
+	for decl, fn := range index.funcs {
+		if fn.isMethod && fn.recv != "" && !ast.IsExported(fn.recv) {
+			if obj, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func); ok {
+				methods[obj] = fn
+			}
+		}
+	}
+	if len(methods) == 0 {
+		return
+	}
+
+	scope := pass.Pkg.Scope()
+	for _, name := range scope.Names() {
+		typeName, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || !typeName.Exported() || typeName.IsAlias() {
+			continue
+		}
+		methodSet := types.NewMethodSet(types.NewPointer(typeName.Type()))
+		for i := 0; i < methodSet.Len(); i++ {
+			sel := methodSet.At(i)
+			if len(sel.Index()) < 2 {
+				// declared by the type itself
+				continue
+			}
+			if fn := methods[sel.Obj().(*types.Func)]; fn != nil {
+				fn.embedders = append(fn.embedders, name)
+			}
+		}
+	}
+}
+
+// pointerMethods records the types of the package with methods declared on pointer receivers. The pointer form
+// is then recommended and accepted for all the methods of such a type, including the ones declared on value
+// receivers, since they are in the method set of the pointer as well. So the recommendations don't depend on
+// the receiver of a single declaration, which may differ between the methods of a type spread across files.
+func pointerMethods(pass *analysis.Pass, index *packageIndex) {
+	withPointers := make(map[string]bool)
+	for decl, fn := range index.funcs {
+		if !fn.isMethod || fn.recv == "" || fn.isRecvPtr {
+			continue
+		}
+		if _, ok := withPointers[fn.recv]; ok {
+			continue
+		}
+		obj, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+		if !ok {
+			continue
+		}
+		recv := obj.Type().(*types.Signature).Recv().Type()
+		withPointers[fn.recv] = types.NewMethodSet(types.NewPointer(recv)).Len() > types.NewMethodSet(recv).Len()
+	}
+	for _, fn := range index.funcs {
+		fn.ptrMethods = !fn.isRecvPtr && withPointers[fn.recv]
+	}
+}
+
+// aliasReceivers records the types the alias receivers of the methods refer to, e.g. serviceImpl
+// for func (s *svc) Get() if type svc = serviceImpl. Prefixes may use either spelling: the alias is how the code
+// names the type, while stack traces and %v of the type print the named type.
+func aliasReceivers(pass *analysis.Pass, index *packageIndex) {
+	aliases := make(map[string]*ast.TypeSpec)
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			if decl, ok := decl.(*ast.GenDecl); ok && decl.Tok == token.TYPE {
+				for _, spec := range decl.Specs {
+					if spec := spec.(*ast.TypeSpec); spec.Assign.IsValid() {
+						aliases[spec.Name.Name] = spec
+					}
+				}
+			}
+		}
+	}
+	if len(aliases) == 0 {
+		return
+	}
+	for _, fn := range index.funcs {
+		if fn.isMethod && fn.recv != "" {
+			fn.named = aliasedType(pass, aliases, fn.recv)
+		}
+	}
+}
+
+// aliasedType resolves an alias of the package through the aliases it refers to, e.g. type svc = impl
+// and type impl = serviceImpl, and returns the name of the named type or an empty string if the name isn't
+// an alias of a type of the package.
+func aliasedType(pass *analysis.Pass, aliases map[string]*ast.TypeSpec, name string) string {
+	for seen := 0; seen <= len(aliases); seen++ {
+		spec, ok := aliases[name]
+		if !ok {
+			if seen == 0 {
+				return ""
+			}
+			return name
+		}
+		ident, ok := astutil.Unparen(spec.Type).(*ast.Ident)
+		if !ok {
+			return ""
+		}
+		obj, ok := pass.TypesInfo.Uses[ident].(*types.TypeName)
+		if !ok || obj.Pkg() != pass.Pkg {
+			return ""
+		}
+		name = obj.Name()
+	}
+	// a cycle of aliases is a type error
+	return ""
+}
+
+// funcValues finds unexported functions and methods of the package which are referenced without being called:
+// function values, method values like s.process and method expressions like (*S).process.
+func funcValues(pass *analysis.Pass, index *packageIndex) map[*ast.FuncDecl]bool {
+	decls := make(map[types.Object]*ast.FuncDecl)
+	for decl, fn := range index.funcs {
+		if !ast.IsExported(fn.name) {
+			if obj := pass.TypesInfo.Defs[decl.Name]; obj != nil {
+				decls[obj] = decl
+			}
+		}
+	}
+
+	values := make(map[*ast.FuncDecl]bool)
+	if len(decls) == 0 {
+		return values
+	}
+	called := make(map[*ast.Ident]bool)
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.CallExpr:
+				switch fun := astutil.Unparen(node.Fun).(type) {
+				case *ast.Ident:
+					called[fun] = true
+				case *ast.SelectorExpr:
+					called[fun.Sel] = true
+				}
+			case *ast.Ident:
+				if decl := decls[pass.TypesInfo.Uses[node]]; decl != nil && !called[node] {
+					values[decl] = true
+				}
+			}
+			return true
+		})
+	}
+	return values
+}
+
+// registeredFuncs finds function literals passed to the registration functions set by config.registrars,
+// e.g. plugins.Register("name", func(ctx context.Context) error { ... }).
+func registeredFuncs(pass *analysis.Pass) map[*ast.FuncLit]bool {
+	registered := make(map[*ast.FuncLit]bool)
+	if len(config.registrars) == 0 {
+		return registered
+	}
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			callee, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+			if !ok || !config.registrars.contains(funcFullName(callee)) {
+				return true
+			}
+			for _, arg := range call.Args {
+				if lit, ok := astutil.Unparen(arg).(*ast.FuncLit); ok {
+					registered[lit] = true
+				}
+			}
+			return true
+		})
+	}
+	return registered
+}
+
+// lazyInitializers are the functions of the sync package which run a function on the first call of their result.
+var lazyInitializers = []string{"sync.OnceFunc", "sync.OnceValue", "sync.OnceValues"}
+
+// lazyFuncs finds function literals passed to lazyInitializers in initial values of package-level variables,
+// e.g. var LoadConfig = sync.OnceValues(func() (*Config, error) { ... }).
+func lazyFuncs(pass *analysis.Pass) map[*ast.FuncLit]bool {
+	lazy := make(map[*ast.FuncLit]bool)
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.VAR {
+				continue
+			}
+			ast.Inspect(genDecl, func(node ast.Node) bool {
+				call, ok := node.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				callee, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+				if !ok || !containsString(lazyInitializers, funcFullName(callee)) {
+					return true
+				}
+				for _, arg := range call.Args {
+					if lit, ok := astutil.Unparen(arg).(*ast.FuncLit); ok {
+						lazy[lit] = true
+					}
+				}
+				return true
+			})
+		}
+	}
+	return lazy
+}
+
+// tableFuncs finds function literals stored in composite literals initializing package-level variables,
+// e.g. var validators = map[string]func(string) error{"email": func(v string) error { ... }}. Nested composite
+// literals are searched too, but not calls or the bodies of functions, where a composite literal is local.
+func tableFuncs(pass *analysis.Pass) map[*ast.FuncLit]bool {
+	tables := make(map[*ast.FuncLit]bool)
+	var collect func(expr ast.Expr, inTable bool)
+	collect = func(expr ast.Expr, inTable bool) {
+		switch expr := astutil.Unparen(expr).(type) {
+		case *ast.FuncLit:
+			if inTable {
+				tables[expr] = true
+			}
+		case *ast.UnaryExpr:
+			if expr.Op == token.AND {
+				collect(expr.X, inTable)
+			}
+		case *ast.KeyValueExpr:
+			collect(expr.Key, inTable)
+			collect(expr.Value, inTable)
+		case *ast.CompositeLit:
+			for _, elt := range expr.Elts {
+				collect(elt, true)
+			}
+		}
+	}
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				if valueSpec, ok := spec.(*ast.ValueSpec); ok {
+					for _, value := range valueSpec.Values {
+						collect(value, false)
+					}
+				}
+			}
+		}
+	}
+	return tables
+}
+
+// funcFullName returns a name of a function like "import/path.Func" or "import/path.Type.Method".
+func funcFullName(fn *types.Func) string {
+	if fn.Pkg() == nil {
+		return fn.Name()
+	}
+	name := fn.Pkg().Path() + "."
+	if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+		t := recv.Type()
+		if ptr, ok := t.(*types.Pointer); ok {
+			t = ptr.Elem()
+		}
+		if named, ok := t.(*types.Named); ok {
+			name += named.Obj().Name() + "."
+		}
+	}
+	return name + fn.Name()
+}
+
+// typ returns a type with a given name adding it to the index if necessary.
+func (index *packageIndex) typ(name string) *typeIndex {
+	t, ok := index.types[name]
+	if !ok {
+		t = &typeIndex{methods: make(map[string]bool)}
+		index.types[name] = t
+	}
+	return t
+}
+
+// isSibling tells whether a location refers to another method of the receiver of a method, e.g. pkg.Struct.Save
+// in Struct.Load.
+func (index *packageIndex) isSibling(fn *funcInfo, loc location) bool {
+	if fn.recv == "" || loc.recv != fn.recv || loc.fn == fn.name {
+		return false
+	}
+	t := index.types[loc.recv]
+	return t != nil && t.methods[loc.fn]
+}
+
+// explain returns a hint about a prefix which doesn't match the function it is used in:
+// either it refers to another declaration of the package (most likely it is stale or copied)
+// or it is similar to an existing declaration (most likely it is a typo).
+func (index *packageIndex) explain(pkgName string, loc location) string {
+	switch {
+	case loc.fn == "":
+		return ""
+
+	case loc.recv == "":
+		if index.names[loc.fn] || index.types[loc.fn] != nil {
+			return "prefix refers to " + pkgName + "." + loc.fn + " declared in the package"
+		}
+		candidates := make([]string, 0, len(index.names)+len(index.types))
+		for name := range index.names {
+			candidates = append(candidates, name)
+		}
+		for name := range index.types {
+			candidates = append(candidates, name)
+		}
+		if name, ok := closest(loc.fn, candidates); ok {
+			return "did you mean " + pkgName + "." + name + "?"
+		}
+
+	default:
+		t := index.types[loc.recv]
+		if t == nil {
+			candidates := make([]string, 0, len(index.types))
+			for name, t := range index.types {
+				if t.methods[loc.fn] {
+					candidates = append(candidates, name)
+				}
+			}
+			if name, ok := closest(loc.recv, candidates); ok {
+				return "did you mean " + pkgName + "." + name + "." + loc.fn + "?"
+			}
+			return ""
+		}
+		if t.methods[loc.fn] {
+			return "prefix refers to " + pkgName + "." + loc.recv + "." + loc.fn + " declared in the package"
+		}
+		candidates := make([]string, 0, len(t.methods))
+		for name := range t.methods {
+			candidates = append(candidates, name)
+		}
+		if name, ok := closest(loc.fn, candidates); ok {
+			return "did you mean " + pkgName + "." + loc.recv + "." + name + "?"
+		}
+	}
+	return ""
+}
+
+// closest returns a candidate which is the most similar to the name if it is similar enough to be a typo.
+func closest(name string, candidates []string) (string, bool) {
+	sort.Strings(candidates)
+	best, bestDist := "", -1
+	for _, c := range candidates {
+		d := editDistance(name, c)
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	maxDist := 2
+	if utf8.RuneCountInString(name) < 5 {
+		maxDist = 1
+	}
+	return best, bestDist >= 0 && bestDist <= maxDist
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
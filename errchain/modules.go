@@ -0,0 +1,177 @@
+package errchain
+
+import (
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// moduleRoot returns the directory of the go.mod file of the module a file belongs to.
+func moduleRoot(filename string) (string, bool) {
+	dir := filepath.Dir(filename)
+	for {
+		if info, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil && !info.IsDir() {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// declaredImportPath returns the import path of the package being analyzed under the module path declared
+// in the go.mod file, e.g. go.example.com/kv/store for the store directory of a module declared as go.example.com/kv
+// but checked out at github.com/org/kv-go. The go command reports this path in module mode, while in GOPATH mode
+// the package path is the directory and the vanity path the module is served under is known only from go.mod.
+func declaredImportPath(pass *analysis.Pass) (string, bool) {
+	file := firstNonTestFile(pass)
+	if file == nil {
+		return "", false
+	}
+	filename := pass.Fset.Position(file.Package).Filename
+	root, ok := moduleRoot(filename)
+	if !ok {
+		return "", false
+	}
+	module, ok := modulePath(root)
+	if !ok {
+		return "", false
+	}
+	rel, err := filepath.Rel(root, filepath.Dir(filename))
+	if err != nil {
+		return "", false
+	}
+	if rel == "." {
+		return module, true
+	}
+	return module + "/" + filepath.ToSlash(rel), true
+}
+
+// matchedPath returns the import path error prefixes of a package are matched against: the path declared
+// by its module if it is known, see declaredImportPath, or else the package path.
+func matchedPath(pkg *types.Package) string {
+	if state := stateOf(pkg); state != nil && state.importPath != "" {
+		return state.importPath
+	}
+	return pkg.Path()
+}
+
+// modulePackages returns the directories of the packages of a module keyed by the names their error prefixes
+// start with: the name set by a prefix directive or else the package name. Only package clauses and the comments
+// preceding them are parsed, so a directive is found only there. As the go command does, directories named testdata or vendor,
+// directories starting with "." or "_" and nested modules are skipped, and so are third-party directories
+// unless they are included by config.includeThirdParty.
+func modulePackages(root string) map[string][]string {
+	type dirPackage struct {
+		name      string
+		directive string
+	}
+	dirs := make(map[string]*dirPackage)
+	walkModuleFiles(root, func(path, dir string) {
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil || file.Name.Name == "main" {
+			return
+		}
+		pkg := dirs[dir]
+		if pkg == nil {
+			pkg = &dirPackage{name: file.Name.Name}
+			dirs[dir] = pkg
+		}
+		for _, group := range file.Comments {
+			for _, c := range group.List {
+				if !strings.HasPrefix(c.Text, prefixDirective+" ") {
+					continue
+				}
+				if fields := strings.Fields(strings.TrimPrefix(c.Text, prefixDirective)); len(fields) > 0 {
+					pkg.directive = fields[0]
+				}
+			}
+		}
+	})
+
+	packages := make(map[string][]string)
+	for dir, pkg := range dirs {
+		name := pkg.name
+		if pkg.directive != "" {
+			name = pkg.directive
+		}
+		packages[name] = append(packages[name], dir)
+	}
+	for _, dirs := range packages {
+		sort.Strings(dirs)
+	}
+	return packages
+}
+
+// walkModuleFiles calls visit for every Go file of a module except test files with the directory of the file
+// relative to the root, e.g. "internal/storage". Directories are skipped as modulePackages describes.
+func walkModuleFiles(root string, visit func(path, dir string)) {
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != root && (name == "testdata" || name == "vendor" || isThirdPartyDir(name) ||
+				strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); path != root && err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		dir, _ := filepath.Rel(root, filepath.Dir(path))
+		visit(path, filepath.ToSlash(dir))
+		return nil
+	})
+}
+
+var modulePackagesCache sync.Map // module root -> map[string][]string
+
+// loadModulePackages returns the packages of the module the package being analyzed belongs to
+// as modulePackages does. Each module is scanned once per process on the first request.
+func loadModulePackages(pass *analysis.Pass) map[string][]string {
+	if len(pass.Files) == 0 {
+		return nil
+	}
+	root, ok := moduleRoot(pass.Fset.Position(pass.Files[0].Package).Filename)
+	if !ok {
+		return nil
+	}
+	if packages, ok := modulePackagesCache.Load(root); ok {
+		return packages.(map[string][]string)
+	}
+	packages, _ := modulePackagesCache.LoadOrStore(root, modulePackages(root))
+	return packages.(map[string][]string)
+}
+
+// otherModulePackage returns the directory of another package of the module with a given name,
+// e.g. "internal/storage" for a prefix "storage: " used in package cache.
+func otherModulePackage(pass *analysis.Pass, name string) (string, bool) {
+	packages := loadModulePackages(pass)
+	if packages == nil {
+		return "", false
+	}
+
+	own := filepath.Dir(pass.Fset.Position(pass.Files[0].Package).Filename)
+	for _, dir := range packages[name] {
+		if !strings.HasSuffix(filepath.ToSlash(own), "/"+dir) && dir != "." {
+			return dir, true
+		}
+	}
+	return "", false
+}
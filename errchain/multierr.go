@@ -0,0 +1,68 @@
+package errchain
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// multiErrorFuncs combine several errors into one. A combined error has no message of its own,
+// so it is as prefixed as its components are.
+var multiErrorFuncs = map[string]bool{
+	"errors.Join":                  true,
+	"go.uber.org/multierr.Append":  true,
+	"go.uber.org/multierr.Combine": true,
+}
+
+// multiErrorArgs returns the errors combined by a call of a multi-error function, or false
+// if an expression is not such a call.
+func multiErrorArgs(pass *analysis.Pass, expr ast.Expr) ([]ast.Expr, bool) {
+	call, ok := astutil.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return nil, false
+	}
+	callee, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || !multiErrorFuncs[funcFullName(callee)] {
+		return nil, false
+	}
+	return call.Args, true
+}
+
+// joinedCalls returns the calls of a function body combined by a multi-error function right away,
+// e.g. errors.New("a") in errors.Join(errors.New("a"), err).
+func joinedCalls(pass *analysis.Pass, body ast.Node) map[*ast.CallExpr]bool {
+	joined := make(map[*ast.CallExpr]bool)
+	ast.Inspect(body, func(node ast.Node) bool {
+		expr, ok := node.(ast.Expr)
+		if !ok {
+			return true
+		}
+		if _, ok := multiErrorArgs(pass, expr); !ok {
+			return true
+		}
+		for _, component := range errorComponents(pass, expr) {
+			if call, ok := astutil.Unparen(component).(*ast.CallExpr); ok {
+				joined[call] = true
+			}
+		}
+		return true
+	})
+	return joined
+}
+
+// errorComponents returns an error expression itself or, if it combines several errors,
+// its components recursively: multierr.Append(err, errors.New("x")) gives err and errors.New("x").
+func errorComponents(pass *analysis.Pass, expr ast.Expr) []ast.Expr {
+	args, ok := multiErrorArgs(pass, expr)
+	if !ok {
+		return []ast.Expr{expr}
+	}
+	var components []ast.Expr
+	for _, arg := range args {
+		components = append(components, errorComponents(pass, arg)...)
+	}
+	return components
+}
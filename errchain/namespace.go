@@ -0,0 +1,145 @@
+package errchain
+
+import (
+	"bufio"
+	"go/ast"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// PrefixNamespace is a fact about a package telling which name its error prefixes start with,
+// e.g. "client" for "client.Get: ". Two packages of a module sharing a namespace make error chains ambiguous.
+type PrefixNamespace struct {
+	Name string
+}
+
+// AFact implements analysis.Fact.
+func (*PrefixNamespace) AFact() {}
+
+func (f *PrefixNamespace) String() string {
+	return "PrefixNamespace(" + f.Name + ")"
+}
+
+var ruleNamespace = registerRule(rule{
+	code:             "namespace",
+	doc:              "packages of a module don't share a prefix namespace",
+	enabledByDefault: always,
+})
+
+// checkNamespace exports the PrefixNamespace fact of the package and reports other packages of the module
+// claiming the same namespace. Namespaces of the dependencies are known exactly from their facts,
+// the rest of the module is found by scanning package clauses and prefix directives, so both packages
+// of a pair get the diagnostic whichever of them imports the other.
+func checkNamespace(pass *analysis.Pass) {
+	file := firstNonTestFile(pass)
+	if file == nil {
+		return
+	}
+	name := packageNames(pass.Pkg)[0]
+	pass.ExportPackageFact(&PrefixNamespace{Name: name})
+	if !config.enabled(ruleNamespace) || strings.Contains(name, "/") {
+		// qualified with parent path segments by config.qualified
+		return
+	}
+
+	filename := pass.Fset.Position(file.Package).Filename
+	root, ok := moduleRoot(filename)
+	if !ok || isDependencyModule(root) {
+		return
+	}
+	own, err := filepath.Rel(root, filepath.Dir(filename))
+	if err != nil {
+		return
+	}
+	own = filepath.ToSlash(own)
+	packages := loadModulePackages(pass)
+	if !containsString(packages[name], own) {
+		// the package isn't a part of the module, e.g. it's in a testdata directory
+		return
+	}
+
+	others := make(map[string]bool)
+	if path, ok := modulePath(root); ok {
+		for _, fact := range pass.AllPackageFacts() {
+			ns, ok := fact.Fact.(*PrefixNamespace)
+			if !ok || fact.Package == pass.Pkg || ns.Name != name {
+				continue
+			}
+			if dir := strings.TrimPrefix(fact.Package.Path(), path+"/"); dir != fact.Package.Path() {
+				others[dir] = true
+			}
+		}
+	}
+	for _, dir := range packages[name] {
+		if dir != own {
+			others[dir] = true
+		}
+	}
+	if len(others) == 0 {
+		return
+	}
+
+	dirs := make([]string, 0, len(others))
+	for dir := range others {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	reportf(pass, file.Name.Pos(), ruleNamespace, "Ambiguous prefix namespace %q: it is also used by %s, "+
+		"qualify the prefixes with the parent path, e.g. %s, and add the package to -qualified",
+		name, strings.Join(dirs, ", "), strconv.Quote(pathSuffix(pass.Pkg.Path(), 2)+string(config.separator)))
+}
+
+// isDependencyModule tells whether a module root is in the module cache or is the standard library,
+// which are analyzed only for facts, so scanning them would be wasted.
+func isDependencyModule(root string) bool {
+	if strings.Contains(filepath.Base(root), "@") {
+		return true
+	}
+	path, _ := modulePath(root)
+	return path == "std" || path == "cmd"
+}
+
+// modulePath returns the module path declared in the go.mod file of a module root directory.
+func modulePath(root string) (string, bool) {
+	f, err := os.Open(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "module" {
+			if path, err := strconv.Unquote(fields[1]); err == nil {
+				return path, true
+			}
+			return fields[1], true
+		}
+	}
+	return "", false
+}
+
+// firstNonTestFile returns the first file of the package which isn't a test file or nil.
+func firstNonTestFile(pass *analysis.Pass) *ast.File {
+	for _, file := range pass.Files {
+		if !isTest(pass, file) {
+			return file
+		}
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, elem := range list {
+		if elem == s {
+			return true
+		}
+	}
+	return false
+}
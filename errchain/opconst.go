@@ -0,0 +1,115 @@
+package errchain
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var ruleOpLiteral = registerRule(rule{
+	code:             "op-literal",
+	doc:              `messages of a function declaring const op start with it rather than repeat its value`,
+	enabledByDefault: always,
+	example: func(fn, _ string) (string, string) {
+		loc := strings.TrimSuffix(fn, string(config.separator))
+		return `const op = "` + loc + `"` + "\n" + `errors.New("` + fn + `not found")`,
+			`const op = "` + loc + `"` + "\n" + `errors.New(op + "` + string(config.separator) + `not found")`
+	},
+})
+
+// handleOpLiterals reports message literals of a function repeating the value of its op constant, e.g.
+// fmt.Errorf("pkg.Struct.Method: %w", err) next to const op = "pkg.Struct.Method". Once the function is renamed,
+// such a literal drifts apart from the constant, so the fix starts the message with the constant instead.
+// The value of the constant may end with the separator or not.
+func handleOpLiterals(pass *analysis.Pass, funcDecl *ast.FuncDecl) {
+	if !config.enabled(ruleOpLiteral) {
+		return
+	}
+	value, ok := opConstant(pass, funcDecl)
+	if !ok {
+		return
+	}
+	sep := string(config.separator)
+	withSep := strings.HasSuffix(value, sep)
+	prefix := value
+	if !withSep {
+		prefix += sep
+	}
+	if prefix == sep {
+		return
+	}
+
+	ast.Inspect(funcDecl.Body, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		ctor, ok := constructorOf(pass, call)
+		if !ok {
+			return true
+		}
+		lit := messageLiteral(call, ctor)
+		if lit == nil {
+			return true
+		}
+		message, err := strconv.Unquote(lit.Value)
+		if err != nil || !strings.HasPrefix(message, prefix) || !reportable(pass, lit.Pos()) {
+			return true
+		}
+
+		rest := message[len(prefix):]
+		if !withSep {
+			rest = sep + rest
+		}
+		var newText string
+		if ctor.isFormat() && ctor.args == ctor.message+1 {
+			newText = strconv.Quote("%s"+rest) + ", " + opConst
+		} else {
+			newText = opConst + " + " + strconv.Quote(rest)
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos:      lit.Pos(),
+			End:      lit.End(),
+			Category: ruleOpLiteral,
+			Message:  "Message repeats the value of const " + opConst + ", start it with " + opConst + " instead",
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message:   "Start the message with " + opConst,
+				TextEdits: []analysis.TextEdit{{Pos: lit.Pos(), End: lit.End(), NewText: []byte(newText)}},
+			}},
+		})
+		return true
+	})
+}
+
+// opConstant returns the value of the op constant declared in a function body. It returns false if there is
+// no such constant or its value isn't a string.
+func opConstant(pass *analysis.Pass, funcDecl *ast.FuncDecl) (string, bool) {
+	for _, stmt := range funcDecl.Body.List {
+		decl, ok := stmt.(*ast.DeclStmt)
+		if !ok {
+			continue
+		}
+		genDecl, ok := decl.Decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			for _, name := range spec.(*ast.ValueSpec).Names {
+				if name.Name != opConst {
+					continue
+				}
+				c, ok := pass.TypesInfo.Defs[name].(*types.Const)
+				if !ok || c.Val().Kind() != constant.String {
+					return "", false
+				}
+				return constant.StringVal(c.Val()), true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,61 @@
+package errchain
+
+import (
+	"go/ast"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var rulePanicMessage = registerRule(rule{
+	code:             "panic-message",
+	doc:              "messages of Must-style helpers set by -constructors with the panics attribute start with the prefix",
+	enabledByDefault: always,
+	flags:            []string{"constructors"},
+	example: func(fn, _ string) (string, string) {
+		return `must.OK(err, "bad config")`, `must.OK(err, "` + fn + `bad config")`
+	},
+})
+
+// handlePanics checks the messages of the helpers panicking with them, e.g. must.OK(err, "msg"), in a function.
+// The panic ends up in the logs as an error does, so the message must start with the prefix of the function,
+// whether it is exported or returns an error, and an init function uses the package prefix.
+func handlePanics(pass *analysis.Pass, index *packageIndex, funcDecl *ast.FuncDecl) {
+	fn := index.funcs[funcDecl]
+	if !config.enabled(rulePanicMessage) || !hasPanickingConstructors() || fn == nil || fn.exempt {
+		return
+	}
+	if funcDecl.Name.Name == "init" && funcDecl.Recv == nil {
+		fn = &funcInfo{}
+	}
+	rules, ok := funcRules(pass.Pkg, funcDecl)
+	if !ok {
+		rules = config.componentRules(pass.Pkg)
+	}
+	ast.Inspect(funcDecl.Body, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		ctor, ok := constructorOf(pass, call)
+		if !ok || !ctor.panics {
+			return true
+		}
+		check, ok := checkMessage(pass, fn, rules, call, ctor)
+		if !ok || check.isPrefixed() || !reportable(pass, call.Pos()) {
+			return true
+		}
+		reportf(pass, call.Pos(), rulePanicMessage, "Panic message must point to the place where it had happened: %s",
+			prefixProblem(pass, fn, rules, call.Pos(), check.err))
+		return true
+	})
+}
+
+// hasPanickingConstructors tells whether any constructor set by -constructors panics.
+func hasPanickingConstructors() bool {
+	for _, c := range config.constructors {
+		if c.panics {
+			return true
+		}
+	}
+	return false
+}
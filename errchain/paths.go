@@ -0,0 +1,41 @@
+package errchain
+
+import (
+	"path"
+	"runtime"
+	"strings"
+)
+
+// caseInsensitiveFS tells whether file names are compared ignoring case, as the default file systems
+// of Windows and macOS do.
+var caseInsensitiveFS = runtime.GOOS == "windows" || runtime.GOOS == "darwin"
+
+// slashPath converts a path to forward slashes whatever platform it comes from. Unlike filepath.ToSlash
+// it replaces backslashes on every platform, so file names in messages and patterns written on Windows
+// compare equal to the ones seen by Linux CI.
+func slashPath(name string) string {
+	return strings.ReplaceAll(name, `\`, "/")
+}
+
+// fileBase returns the last element of a path separated by slashes or backslashes.
+func fileBase(name string) string {
+	return path.Base(slashPath(name))
+}
+
+// foldFileName lower-cases a file name on case-insensitive file systems.
+func foldFileName(name string) string {
+	if caseInsensitiveFS {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// sameFileName tells whether two file paths name the same file as the file system compares them.
+func sameFileName(a, b string) bool {
+	return foldFileName(slashPath(a)) == foldFileName(slashPath(b))
+}
+
+// hasFileSuffix tells whether a file name ends with a suffix as the file system compares them, e.g. "_test.go".
+func hasFileSuffix(name, suffix string) bool {
+	return strings.HasSuffix(foldFileName(name), foldFileName(suffix))
+}
@@ -0,0 +1,67 @@
+package errchain
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var rulePointerForm = registerRule(rule{
+	code:             "pointer-form",
+	doc:              "prefixes of methods with pointer receivers name the receiver in the pointer form",
+	enabledByDefault: func(c *configuration) bool { return c.pointerForm },
+	flags:            []string{"pointer-form"},
+	example: func(fn, _ string) (string, string) {
+		return `errors.New("pkg.Conn.Close: closed")`, `errors.New("pkg.(*Conn).Close: closed")`
+	},
+})
+
+// handlePointerForm reports the messages of a method declared on a pointer receiver naming the receiver
+// in the value form, e.g. "pkg.Conn.Close: " for func (c *Conn) Close(), and suggests the pointer form,
+// which is how stack traces and the method expressions name the method. Both forms are accepted by the prefix rule.
+func handlePointerForm(pass *analysis.Pass, fn *funcInfo, rules componentRules) {
+	if !config.enabled(rulePointerForm) || !fn.isMethod || !fn.isRecvPtr {
+		return
+	}
+	ast.Inspect(fn.decl.Body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			check, ok := checkCall(pass, fn, rules, node)
+			if !ok || check.err != nil || !reportable(pass, node.Pos()) {
+				return true
+			}
+			loc, err := parsePrefix(check.message)
+			if err != nil || loc.recv == "" || loc.isRecvPtr || !fn.isOwnRecv(loc.recv) {
+				return true
+			}
+			head := check.message[:strings.Index(check.message, string(config.separator))]
+			start := strings.LastIndex(head, "."+loc.recv+"."+loc.fn) + 1
+			if start == 0 {
+				return true
+			}
+			end := start + len(loc.recv)
+			diag := analysis.Diagnostic{
+				Pos:      node.Pos(),
+				Category: rulePointerForm,
+				Message: "Method has a pointer receiver, name it in the pointer form: " +
+					head[:start] + "(*" + loc.recv + ")" + head[end:],
+			}
+			if lit := messageLiteral(node, check.ctor); lit != nil && strings.HasPrefix(lit.Value[1:], head) {
+				diag.SuggestedFixes = []analysis.SuggestedFix{{
+					Message: "Change the receiver to (*" + loc.recv + ")",
+					TextEdits: []analysis.TextEdit{{
+						Pos:     lit.Pos() + 1 + token.Pos(start),
+						End:     lit.Pos() + 1 + token.Pos(end),
+						NewText: []byte("(*" + loc.recv + ")"),
+					}},
+				}}
+			}
+			pass.Report(diag)
+		}
+		return true
+	})
+}
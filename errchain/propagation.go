@@ -0,0 +1,106 @@
+package errchain
+
+import (
+	"go/ast"
+	"go/types"
+	"strconv"
+
+	"github.com/iimos/go-check-err-chains/internal/errflow"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+var rulePropagation = registerRule(rule{
+	code:             "propagation",
+	doc:              "errors of unexported functions returned as is by exported ones are prefixed or wrapped",
+	enabledByDefault: func(c *configuration) bool { return c.propagation },
+	flags:            []string{"propagation", "interprocedural", "attribution"},
+	example: func(fn, _ string) (string, string) {
+		return "return parse(s)", "v, err := parse(s)\nif err != nil {\n\treturn nil, fmt.Errorf(\"" + fn + "%w\", err)\n}\nreturn v, nil"
+	},
+})
+
+// handlePropagation checks errors of unexported functions of the package returned as is by an exported function.
+// Messages of unexported functions are not checked, so their errors have to be wrapped unless every error
+// they return is verified to be prefixed (see exportPrefixedErrorFacts).
+func handlePropagation(pass *analysis.Pass, index *packageIndex, fn *funcInfo) {
+	if !config.enabled(rulePropagation) {
+		return
+	}
+	unprefixedReturns(pass, index, fn, func(expr ast.Expr, callee *types.Func) {
+		reportf(pass, expr.Pos(), rulePropagation, "%s: error of %s is returned as is, wrap it: fmt.Errorf(%s, err)",
+			diagnosticMessage, callee.Name(), strconv.Quote(canonicalPrefix(pass.Pkg, fn)+string(config.separator)+"%w"))
+	})
+}
+
+// unprefixedReturns calls found for every error of an unexported function of the package returned as is
+// by a function, unless the error is verified to be prefixed.
+func unprefixedReturns(pass *analysis.Pass, index *packageIndex, fn *funcInfo, found func(expr ast.Expr, callee *types.Func)) {
+	errIndex, last := errorResultIndex(fn.decl)
+	if errIndex < 0 {
+		return
+	}
+
+	ast.Inspect(fn.decl.Body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			var result ast.Expr
+			switch {
+			case len(node.Results) == 1 && last > 0:
+				// return f()
+				result = node.Results[0]
+			case errIndex < len(node.Results):
+				result = node.Results[errIndex]
+			default:
+				return true
+			}
+			for _, expr := range errorComponents(pass, result) {
+				if callee := unprefixedErrorSource(pass, index, fn.decl.Body, expr); callee != nil {
+					found(expr, callee)
+				}
+			}
+		}
+		return true
+	})
+}
+
+// unprefixedErrorSource returns an unexported function of the package whose error is not verified to be prefixed
+// if an error expression is either its call or a variable last assigned from its call in the body.
+func unprefixedErrorSource(pass *analysis.Pass, index *packageIndex, body *ast.BlockStmt, expr ast.Expr) *types.Func {
+	switch expr := astutil.Unparen(expr).(type) {
+	case *ast.CallExpr:
+		return unprefixedErrorCallee(pass, index, expr)
+	case *ast.Ident:
+		if call := errflow.LastAssignedCall(pass.TypesInfo, body, expr); call != nil {
+			return unprefixedErrorCallee(pass, index, call)
+		}
+	}
+	return nil
+}
+
+// unprefixedErrorCallee returns the function called if it is an unexported function of the package
+// which errors are not verified to be prefixed.
+func unprefixedErrorCallee(pass *analysis.Pass, index *packageIndex, call *ast.CallExpr) *types.Func {
+	callee, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || callee.Pkg() != pass.Pkg || callee.Exported() || index.prefixed[callee] {
+		return nil
+	}
+	if isSelfLocatingCall(pass, call) {
+		return nil
+	}
+	results := callee.Type().(*types.Signature).Results()
+	for i := 0; i < results.Len(); i++ {
+		if isErrorType(results.At(i).Type()) {
+			return callee
+		}
+	}
+	return nil
+}
+
+// isErrorType tells whether a type is the error interface.
+func isErrorType(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
@@ -0,0 +1,225 @@
+package errchain
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/cfg"
+)
+
+// checkReachingMessages checks an error constructor call whose message is a local string variable
+// set in several branches:
+//
+//	msg := "pkg.Get: not found"
+//	if deleted {
+//		msg = "pkg.Get: deleted"
+//	}
+//	return errors.New(msg)
+//
+// Every value reaching the call is checked and the first wrong one is reported. The call can't be checked
+// if any of the values isn't a constant string.
+func checkReachingMessages(pass *analysis.Pass, parentFunc *funcInfo, rules componentRules, call *ast.CallExpr, ctor constructor) (callCheck, bool) {
+	ident, ok := astutil.Unparen(ctor.messageArg(call)).(*ast.Ident)
+	if !ok {
+		return callCheck{}, false
+	}
+	v, ok := pass.TypesInfo.Uses[ident].(*types.Var)
+	if !ok {
+		return callCheck{}, false
+	}
+	defs, ok := reachingDefs(pass, v, call)
+	if !ok || len(defs) == 0 {
+		return callCheck{}, false
+	}
+
+	var first callCheck
+	for i, def := range defs {
+		check, ok := checkFormat(pass, parentFunc, rules, call, ctor, def.value)
+		if !ok {
+			return callCheck{}, false
+		}
+		if check.err != nil {
+			check.origin = def.pos
+			return check, true
+		}
+		if i == 0 {
+			first = check
+		}
+	}
+	return first, true
+}
+
+// A stringDef is a definition of a local string variable with a constant value.
+type stringDef struct {
+	pos   token.Pos
+	value string
+}
+
+// reachingDefs returns the definitions of a local variable reaching a node of the function body declaring it,
+// ordered by position. It returns false if any of them isn't a constant string or the variable may be changed
+// out of the control flow of the body.
+func reachingDefs(pass *analysis.Pass, v *types.Var, use ast.Node) ([]stringDef, bool) {
+	body := enclosingFuncBody(pass, use.Pos())
+	if body == nil || v.Pos() < body.Pos() || body.End() <= v.Pos() || isUntracked(pass, v, body) {
+		// parameters and variables captured by function literals aren't tracked
+		return nil, false
+	}
+
+	g := cfg.New(body, func(*ast.CallExpr) bool { return true })
+	preds := make(map[*cfg.Block][]*cfg.Block)
+	var start *cfg.Block
+	startIndex := 0
+	for _, b := range g.Blocks {
+		for _, succ := range b.Succs {
+			preds[succ] = append(preds[succ], b)
+		}
+		for i, node := range b.Nodes {
+			if start == nil && node.Pos() <= use.Pos() && use.End() <= node.End() {
+				start, startIndex = b, i
+			}
+		}
+	}
+	if start == nil {
+		return nil, false
+	}
+
+	// Walk the blocks backwards from the use: the last definition in a block hides the earlier ones
+	// and the predecessors of a block without definitions are visited in turn.
+	var defs []stringDef
+	ok := true
+	visited := make(map[*cfg.Block]bool)
+	var walk func(b *cfg.Block, end int)
+	walk = func(b *cfg.Block, end int) {
+		for i := end - 1; i >= 0; i-- {
+			if def, found := definition(pass, v, b.Nodes[i]); found {
+				if def == nil {
+					ok = false
+				} else {
+					defs = append(defs, *def)
+				}
+				return
+			}
+		}
+		if len(preds[b]) == 0 {
+			// the entry or an unreachable block, the variable isn't defined on this path
+			ok = false
+			return
+		}
+		for _, pred := range preds[b] {
+			if ok && !visited[pred] {
+				visited[pred] = true
+				walk(pred, len(pred.Nodes))
+			}
+		}
+	}
+	walk(start, startIndex)
+	if !ok {
+		return nil, false
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].pos < defs[j].pos })
+	return defs, true
+}
+
+// definition tells whether a node of the control flow graph defines a variable. The definition is nil
+// if the value isn't a constant string.
+func definition(pass *analysis.Pass, v *types.Var, node ast.Node) (*stringDef, bool) {
+	switch node := node.(type) {
+	case *ast.AssignStmt:
+		for i, lhs := range node.Lhs {
+			if !refersTo(pass, lhs, v) {
+				continue
+			}
+			if (node.Tok != token.ASSIGN && node.Tok != token.DEFINE) || len(node.Lhs) != len(node.Rhs) {
+				return nil, true
+			}
+			value, ok := stableString(pass, node.Rhs[i])
+			if !ok {
+				return nil, true
+			}
+			return &stringDef{pos: node.Rhs[i].Pos(), value: value}, true
+		}
+	case *ast.ValueSpec:
+		for i, name := range node.Names {
+			if !refersTo(pass, name, v) {
+				continue
+			}
+			if len(node.Values) == 0 {
+				// var msg string
+				return &stringDef{pos: name.Pos()}, true
+			}
+			if len(node.Names) != len(node.Values) {
+				return nil, true
+			}
+			value, ok := stableString(pass, node.Values[i])
+			if !ok {
+				return nil, true
+			}
+			return &stringDef{pos: node.Values[i].Pos(), value: value}, true
+		}
+	case *ast.Ident:
+		// the key or the value of a range statement
+		if pass.TypesInfo.Defs[node] == v {
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+// isUntracked tells whether a local variable may be changed out of the definitions seen by reachingDefs:
+// its address is taken, a function literal assigns it or a range statement assigns it with =.
+func isUntracked(pass *analysis.Pass, v *types.Var, body *ast.BlockStmt) bool {
+	found := false
+	assigns := func(node ast.Node) {
+		if assign, ok := node.(*ast.AssignStmt); ok {
+			for _, lhs := range assign.Lhs {
+				found = found || refersTo(pass, lhs, v)
+			}
+		}
+	}
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.UnaryExpr:
+			found = found || (node.Op == token.AND && refersTo(pass, node.X, v))
+		case *ast.RangeStmt:
+			found = found || (node.Tok == token.ASSIGN && (refersTo(pass, node.Key, v) || refersTo(pass, node.Value, v)))
+		case *ast.FuncLit:
+			ast.Inspect(node.Body, func(node ast.Node) bool {
+				assigns(node)
+				return !found
+			})
+		}
+		return !found
+	})
+	return found
+}
+
+// refersTo tells whether an expression is an identifier of a variable.
+func refersTo(pass *analysis.Pass, expr ast.Expr, v *types.Var) bool {
+	if expr == nil {
+		return false
+	}
+	ident, ok := astutil.Unparen(expr).(*ast.Ident)
+	return ok && pass.TypesInfo.ObjectOf(ident) == v
+}
+
+// enclosingFuncBody returns the body of the innermost function declaration or literal containing a position.
+func enclosingFuncBody(pass *analysis.Pass, pos token.Pos) *ast.BlockStmt {
+	file := enclosingFile(pass, pos)
+	if file == nil {
+		return nil
+	}
+	path, _ := astutil.PathEnclosingInterval(file, pos, pos)
+	for _, node := range path {
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			return node.Body
+		case *ast.FuncDecl:
+			return node.Body
+		}
+	}
+	return nil
+}
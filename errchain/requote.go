@@ -0,0 +1,150 @@
+package errchain
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"github.com/iimos/go-check-err-chains/internal/fmtverb"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+var ruleDuplicateCause = registerRule(rule{
+	code:             "duplicate-cause",
+	doc:              `messages wrapping an error with %w don't format its text once more, e.g. fmt.Errorf("pkg.Fn: %v: %w", err, err)`,
+	enabledByDefault: always,
+	example: func(fn, _ string) (string, string) {
+		return `fmt.Errorf("` + fn + `%v: %w", err, err)`, `fmt.Errorf("` + fn + `%w", err)`
+	},
+})
+
+// handleDuplicateCauses reports messages of a function formatting the error they wrap with %w once more,
+// e.g. fmt.Errorf("pkg.Fn: %v: %w", err, err) or fmt.Errorf("pkg.Fn: %s (%w)", err.Error(), err):
+// the text of the cause is already the tail of the message, so it would appear in the chain twice.
+// The fix removes the redundant verb along with the text between it and %w and its argument.
+func handleDuplicateCauses(pass *analysis.Pass, funcDecl *ast.FuncDecl) {
+	if !config.enabled(ruleDuplicateCause) {
+		return
+	}
+	ast.Inspect(funcDecl.Body, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || call.Ellipsis.IsValid() {
+			return true
+		}
+		ctor, ok := constructorOf(pass, call)
+		if !ok || !ctor.isFormat() {
+			return true
+		}
+		format, ok := stableString(pass, ctor.messageArg(call))
+		if !ok {
+			return true
+		}
+		verbs, ok := fmtverb.Parse(format)
+		args := ctor.formatArgs(call)
+		if !ok || len(verbs) != len(args) {
+			return true
+		}
+		for _, w := range verbs {
+			if w.Verb != 'w' {
+				continue
+			}
+			for _, v := range verbs {
+				if v.Verb == 'w' || !isCauseText(pass, args[v.Arg], args[w.Arg]) {
+					continue
+				}
+				if reportable(pass, call.Pos()) {
+					reportDuplicateCause(pass, call, ctor, format, verbs, v, w)
+				}
+				return true
+			}
+		}
+		return true
+	})
+}
+
+// reportDuplicateCause reports the verb v of a message formatting the error wrapped by the verb w. The fix needs
+// the verbs to be adjacent and the format to be written in the literal as is, so the offsets of the verbs
+// are the offsets in the literal.
+func reportDuplicateCause(pass *analysis.Pass, call *ast.CallExpr, ctor constructor, format string,
+	verbs []fmtverb.Verb, v, w fmtverb.Verb,
+) {
+	args := ctor.formatArgs(call)
+	diag := analysis.Diagnostic{
+		Pos:      call.Pos(),
+		Category: ruleDuplicateCause,
+		Message: "Message formats the error wrapped by %w once more with " + format[v.Start:v.End] +
+			", its text would appear in the chain twice",
+	}
+	lit := messageLiteral(call, ctor)
+	newFormat, ok := withoutDuplicateVerb(format, verbs, v, w)
+	if lit != nil && ok && lit.Value[1:len(lit.Value)-1] == format {
+		// the argument is removed with the comma separating it from the next one or, if it's the last one,
+		// from the previous one
+		argPos, argEnd := call.Args[ctor.args+v.Arg-1].End(), args[v.Arg].End()
+		if v.Arg+1 < len(args) {
+			argPos, argEnd = args[v.Arg].Pos(), args[v.Arg+1].Pos()
+		}
+		diag.SuggestedFixes = []analysis.SuggestedFix{{
+			Message: "Remove " + format[v.Start:v.End] + " and its argument",
+			TextEdits: []analysis.TextEdit{
+				{Pos: lit.Pos() + 1, End: lit.End() - 1, NewText: []byte(newFormat)},
+				{Pos: argPos, End: argEnd},
+			},
+		}}
+	}
+	pass.Report(diag)
+}
+
+// withoutDuplicateVerb returns a format without the verb v formatting the error wrapped by the verb w
+// and the text between them, e.g. "pkg.Fn: %w" for "pkg.Fn: %v: %w" or "pkg.Fn: %w: %v". A bracket opened
+// by the text is removed with the one closing it right after the second verb, as in "pkg.Fn: %s (%w)".
+// It returns false if another verb lies between them or the bracket isn't closed there.
+func withoutDuplicateVerb(format string, verbs []fmtverb.Verb, v, w fmtverb.Verb) (string, bool) {
+	first, second := v, w
+	if w.Start < v.Start {
+		first, second = w, v
+	}
+	for _, u := range verbs {
+		if first.Start < u.Start && u.Start < second.Start {
+			return "", false
+		}
+	}
+	end := second.End
+	if between := format[first.End:second.Start]; strings.HasSuffix(between, "(") || strings.HasSuffix(between, "[") {
+		closing := map[byte]byte{'(': ')', '[': ']'}[between[len(between)-1]]
+		if end == len(format) || format[end] != closing {
+			return "", false
+		}
+		end++
+	}
+	if first == v {
+		return format[:v.Start] + format[w.Start:w.End] + format[end:], true
+	}
+	return format[:w.End] + format[end:], true
+}
+
+// isCauseText tells whether a formatted argument is the wrapped error or the result of its Error method.
+// The wrapped error must be a variable or a field, so evaluating it twice gives the same error.
+func isCauseText(pass *analysis.Pass, arg, wrapped ast.Expr) bool {
+	if typ := pass.TypesInfo.TypeOf(wrapped); typ == nil || !verbAccepts('w', typ) || !isPlainRef(wrapped) {
+		return false
+	}
+	if call, ok := astutil.Unparen(arg).(*ast.CallExpr); ok && len(call.Args) == 0 {
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Error" {
+			arg = sel.X
+		}
+	}
+	return types.ExprString(astutil.Unparen(arg)) == types.ExprString(astutil.Unparen(wrapped))
+}
+
+// isPlainRef tells whether an expression is a name or a chain of field selections, e.g. err or r.lastErr.
+func isPlainRef(expr ast.Expr) bool {
+	switch expr := astutil.Unparen(expr).(type) {
+	case *ast.Ident:
+		return expr.Name != "_" && expr.Name != "nil"
+	case *ast.SelectorExpr:
+		return isPlainRef(expr.X)
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package errchain
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+var ruleHTTPResponse = registerRule(rule{
+	code:             "http-response",
+	doc:              "error responses written by handlers of exported functions returning http.HandlerFunc start with the prefix",
+	enabledByDefault: func(c *configuration) bool { return c.httpResponses },
+	flags:            []string{"http-responses"},
+	example: func(fn, _ string) (string, string) {
+		return `http.Error(w, "not found", http.StatusNotFound)`, `http.Error(w, "` + fn + `not found", http.StatusNotFound)`
+	},
+})
+
+// responseWriters describe the calls writing the text of an error response as constructors: the message is the text.
+// fmt.Fprintf writes an error response only if it formats an error or follows w.WriteHeader with an error status.
+var responseWriters = constructorTable{
+	{name: "net/http.Error", message: 1, args: -1, wrapped: -1},
+	{name: "fmt.Fprintf", message: 1, args: 2, wrapped: -1},
+}
+
+// handleResponses checks the error responses written by the handlers an exported function returns, e.g. a middleware
+// returning http.HandlerFunc: the text ends up in the logs and error chains of the clients, so it must start
+// with the prefix of the function as error messages do.
+func handleResponses(pass *analysis.Pass, fn *funcInfo) {
+	if !config.enabled(ruleHTTPResponse) || !ast.IsExported(fn.name) || !returnsHandlerFunc(pass, fn.decl) {
+		return
+	}
+	rules := config.componentRules(pass.Pkg)
+	afterErrorStatus := errorStatusWrites(pass, fn.decl.Body)
+	ast.Inspect(fn.decl.Body, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || len(call.Args) < 2 || !isResponseWriter(pass.TypesInfo.TypeOf(call.Args[0])) {
+			return true
+		}
+		callee, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if !ok {
+			return true
+		}
+		ctor, ok := responseWriters.lookup(funcFullName(callee))
+		if !ok || ctor.isFormat() && !afterErrorStatus[call] && !formatsError(pass, ctor.formatArgs(call)) {
+			return true
+		}
+		format, ok := stableString(pass, ctor.messageArg(call))
+		if !ok {
+			return true
+		}
+		check, ok := checkFormat(pass, fn, rules, call, ctor, format)
+		if !ok || check.isPrefixed() || !reportable(pass, call.Pos()) {
+			return true
+		}
+		reportf(pass, call.Pos(), ruleHTTPResponse, "Error response must point to the place where it had happened: %s",
+			prefixProblem(pass, fn, rules, call.Pos(), check.err))
+		return true
+	})
+}
+
+// returnsHandlerFunc tells whether a function returns an http.HandlerFunc.
+func returnsHandlerFunc(pass *analysis.Pass, funcDecl *ast.FuncDecl) bool {
+	if funcDecl.Type.Results == nil {
+		return false
+	}
+	for _, field := range funcDecl.Type.Results.List {
+		if isNetHTTPType(pass.TypesInfo.TypeOf(field.Type), "HandlerFunc") {
+			return true
+		}
+	}
+	return false
+}
+
+// isResponseWriter tells whether a type is http.ResponseWriter.
+func isResponseWriter(t types.Type) bool {
+	return isNetHTTPType(t, "ResponseWriter")
+}
+
+// isNetHTTPType tells whether a type is the named type of the net/http package.
+func isNetHTTPType(t types.Type, name string) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == "net/http" && obj.Name() == name
+}
+
+// formatsError tells whether one of the formatted arguments is an error.
+func formatsError(pass *analysis.Pass, args []ast.Expr) bool {
+	for _, arg := range args {
+		if t := pass.TypesInfo.TypeOf(arg); t != nil && isErrorType(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// errorStatusWrites returns the calls following a w.WriteHeader call with a constant error status, e.g.
+// http.StatusBadRequest, in the same statement list of a block or a case clause.
+func errorStatusWrites(pass *analysis.Pass, body *ast.BlockStmt) map[*ast.CallExpr]bool {
+	writes := make(map[*ast.CallExpr]bool)
+	ast.Inspect(body, func(node ast.Node) bool {
+		var list []ast.Stmt
+		switch node := node.(type) {
+		case *ast.BlockStmt:
+			list = node.List
+		case *ast.CaseClause:
+			list = node.Body
+		case *ast.CommClause:
+			list = node.Body
+		}
+		failed := false
+		for _, stmt := range list {
+			expr, ok := stmt.(*ast.ExprStmt)
+			if !ok {
+				continue
+			}
+			call, ok := expr.X.(*ast.CallExpr)
+			if !ok {
+				continue
+			}
+			if failed {
+				writes[call] = true
+			}
+			failed = failed || isErrorStatusWrite(pass, call)
+		}
+		return true
+	})
+	return writes
+}
+
+// isErrorStatusWrite tells whether a call is w.WriteHeader(status) with a constant status of 400 or greater.
+func isErrorStatusWrite(pass *analysis.Pass, call *ast.CallExpr) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "WriteHeader" || len(call.Args) != 1 || !isResponseWriter(pass.TypesInfo.TypeOf(sel.X)) {
+		return false
+	}
+	value := pass.TypesInfo.Types[call.Args[0]].Value
+	if value == nil || value.Kind() != constant.Int {
+		return false
+	}
+	status, ok := constant.Int64Val(value)
+	return ok && status >= 400
+}
@@ -0,0 +1,159 @@
+package errchain
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// A rule is a check of the analyzer identified by a code. Checks register themselves with registerRule
+// and test config.enabled before reporting.
+type rule struct {
+	code string
+	doc  string
+	// enabledByDefault tells the state of the rule unless it is set by -enable or -disable,
+	// e.g. the error-last rule is enabled by the -error-last flag.
+	enabledByDefault func(c *configuration) bool
+	// flags are the flags configuring the rule besides -enable and -disable.
+	flags []string
+	// example returns a failing and a passing snippet for a function with the given prefix, e.g. "pkg.Type.Method: ",
+	// in a package with the given prefix, e.g. "pkg: ". It is nil if the rule isn't about a single function.
+	example func(fnPrefix, pkgPrefix string) (bad, good string)
+}
+
+// rules are the registered checks of the analyzer in the order they are listed.
+var rules []rule
+
+// registerRule adds a rule to the registry and returns its code.
+func registerRule(r rule) string {
+	for _, known := range rules {
+		if known.code == r.code {
+			panic("errchain: rule " + r.code + " is registered twice")
+		}
+	}
+	rules = append(rules, r)
+	return r.code
+}
+
+// reportf reports a diagnostic of a rule. The category of the diagnostic is the rule code,
+// so the consumers of the -json output can tell the checks apart.
+func reportf(pass *analysis.Pass, pos token.Pos, code, format string, args ...interface{}) {
+	if !reportable(pass, pos) {
+		return
+	}
+	pass.Report(analysis.Diagnostic{Pos: pos, Category: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// reportable tells whether a diagnostic at a position is reported: its file matches -report-files if it is set.
+// The diagnostics are filtered before their messages are built, so the findings outside the files,
+// e.g. in the untouched code of a diff, cost only the check.
+func reportable(pass *analysis.Pass, pos token.Pos) bool {
+	return len(config.reportFiles) == 0 || config.reportFiles.matchFile(pass.Fset.Position(pos).Filename)
+}
+
+func always(*configuration) bool {
+	return true
+}
+
+// enabled tells whether a rule is enabled. A rule set by -disable or -enable is disabled or enabled,
+// a code beats "all" and -disable beats -enable. Other rules are enabled depending on their flags.
+func (c *configuration) enabled(code string) bool {
+	switch {
+	case c.disable.contains(code):
+		return false
+	case c.enable.contains(code):
+		return true
+	case c.disable.contains(allRules):
+		return false
+	case c.enable.contains(allRules):
+		return true
+	}
+	for _, r := range rules {
+		if r.code == code {
+			return r.enabledByDefault(c)
+		}
+	}
+	return false
+}
+
+// RuleInfo describes a check of the analyzer.
+type RuleInfo struct {
+	Code    string
+	Doc     string
+	Enabled bool     // whether the rule is enabled by the current flags of Analyzer
+	Flags   []string // flags configuring the rule besides -enable and -disable
+}
+
+// Rules returns the checks of the analyzer with their states under the current flags.
+func Rules() []RuleInfo {
+	infos := make([]RuleInfo, 0, len(rules))
+	for _, r := range rules {
+		infos = append(infos, RuleInfo{Code: r.code, Doc: r.doc, Enabled: config.enabled(r.code), Flags: r.flags})
+	}
+	return infos
+}
+
+// Example returns a failing and a passing snippet of a rule for a function like "pkg.Type.Method",
+// see CanonicalPrefix. It returns false if the rule has no example.
+func Example(code, fn string) (bad, good string, ok bool, err error) {
+	loc, err := parseFuncName(fn)
+	if err != nil {
+		return "", "", false, err
+	}
+	for _, r := range rules {
+		if r.code != code {
+			continue
+		}
+		if r.example == nil {
+			return "", "", false, nil
+		}
+		bad, good = r.example(loc.String()+string(config.separator), loc.pkg+string(config.separator))
+		return bad, good, true, nil
+	}
+	return "", "", false, fmt.Errorf("unknown rule %q, see -list-rules", code)
+}
+
+// allRules is a code which stands for every rule in -enable and -disable.
+const allRules = "all"
+
+// A ruleList is a comma-separated list of rule codes or "all". It implements flag.Value.
+type ruleList []string
+
+func (l *ruleList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *ruleList) Set(s string) error {
+	list := splitList(s)
+	for _, code := range list {
+		if !isRuleCode(code) {
+			return fmt.Errorf("unknown rule %q, see -list-rules", code)
+		}
+	}
+	*l = list
+	return nil
+}
+
+// contains tells whether the list contains a rule code.
+func (l ruleList) contains(code string) bool {
+	for _, elem := range l {
+		if elem == code {
+			return true
+		}
+	}
+	return false
+}
+
+func isRuleCode(code string) bool {
+	if code == allRules {
+		return true
+	}
+	for _, r := range rules {
+		if r.code == code {
+			return true
+		}
+	}
+	return false
+}
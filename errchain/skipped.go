@@ -0,0 +1,117 @@
+package errchain
+
+import (
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"honnef.co/go/tools/analysis/code"
+)
+
+// skippedCategory is the category of diagnostics reported in the -why-skipped mode.
+// Their messages start with one of the skip reasons, optionally followed by ": " and the name of what was skipped,
+// so the output of "errchain -why-skipped -json" can be processed by tools.
+const skippedCategory = "skipped"
+
+var ruleSkipped = registerRule(rule{
+	code:             "skipped",
+	doc:              "diagnostics about skipped packages, files, functions and messages",
+	enabledByDefault: func(c *configuration) bool { return c.whySkipped },
+	flags:            []string{"why-skipped", "include-generated", "include-third-party", "skip-vendor", "skip-mocks"},
+})
+
+// Reasons of skipping parts of the code.
+const (
+	skipMainPackage    = "main-like package"
+	skipVendored       = "vendored package"
+	skipThirdParty     = "third-party package"
+	skipGeneratedFile  = "generated file"
+	skipMockPackage    = "mock package"
+	skipMockFile       = "mock file"
+	skipTestFile       = "test file"
+	skipUnexported     = "unexported function"
+	skipExemptType     = "method of exempt type"
+	skipDynamicMessage = "non-constant message"
+	skipMultiline      = "multiline message"
+)
+
+// reportSkipped reports a part of the code which is not checked if the skipped rule is enabled.
+func reportSkipped(pass *analysis.Pass, pos token.Pos, reason, name string) {
+	if !config.enabled(ruleSkipped) || !reportable(pass, pos) {
+		return
+	}
+	msg := reason
+	if name != "" {
+		msg += ": " + name
+	}
+	pass.Report(analysis.Diagnostic{Pos: pos, Category: skippedCategory, Message: msg})
+}
+
+// reportSkippedCall reports an error constructor call which message can't be checked statically
+// or is a multiline template skipped by -multiline or -max-multiline-length.
+func reportSkippedCall(pass *analysis.Pass, call *ast.CallExpr) {
+	if !config.enabled(ruleSkipped) {
+		return
+	}
+	ctor, ok := constructorOf(pass, call)
+	if !ok || ctor.messageArg(call) == nil {
+		return
+	}
+	reason := skipDynamicMessage
+	if isMultilineLiteral(call, ctor) {
+		reason = skipMultiline
+	}
+	reportSkipped(pass, call.Pos(), reason, code.CallName(pass, call))
+}
+
+// thirdPartyDirs are names of directories conventionally holding copies of external code.
+var thirdPartyDirs = []string{"third_party", "external"}
+
+// isThirdPartyDir tells whether a directory name is one of thirdPartyDirs not included by config.includeThirdParty.
+func isThirdPartyDir(name string) bool {
+	return !config.includeThirdParty && containsString(thirdPartyDirs, name)
+}
+
+// skippedTree returns the reason to skip a package located in a vendor or third-party directory or an empty string.
+// The directory of the package is taken relative to its module root, so a module checked out into
+// a directory like ~/external isn't skipped. Outside of modules the import path is used.
+func skippedTree(pass *analysis.Pass) string {
+	if len(pass.Files) == 0 {
+		return ""
+	}
+	filename := pass.Fset.Position(pass.Files[0].Package).Filename
+	dir := pass.Pkg.Path()
+	if root, ok := moduleRoot(filename); ok {
+		if rel, err := filepath.Rel(root, filepath.Dir(filename)); err == nil {
+			dir = filepath.ToSlash(rel)
+		}
+	}
+	for _, elem := range strings.Split(dir, "/") {
+		switch {
+		case elem == "vendor" && config.skipVendor:
+			return skipVendored
+		case isThirdPartyDir(elem):
+			return skipThirdParty
+		}
+	}
+	return ""
+}
+
+// isMockPackage tells whether the name or the import path of a package matches config.mockPackages,
+// e.g. "mocks" of mockery, "mock_store" of gomock or "storefakes" of counterfeiter.
+func isMockPackage(pass *analysis.Pass) bool {
+	return config.skipMocks && (config.mockPackages.match(pass.Pkg.Name()) || config.mockPackages.match(pass.Pkg.Path()))
+}
+
+// isSkippedMock tells whether a file is a mock matching config.mockFiles, like mock_store.go of gomock.
+// Mocks are generated, but their headers vary between generators and versions, so the name is relied on.
+func isSkippedMock(pass *analysis.Pass, file *ast.File) bool {
+	filename := pass.Fset.Position(file.Package).Filename
+	if !config.skipMocks || !config.mockFiles.matchFile(filename) {
+		return false
+	}
+	reportSkipped(pass, file.Package, skipMockFile, filepath.Base(filename))
+	return true
+}
@@ -0,0 +1,200 @@
+package errchain
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strconv"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// A packageState holds data about the package being analyzed which is needed deep in the checks
+// getting only the package, e.g. in location.match.
+type packageState struct {
+	prefix    string // set by a prefix directive
+	hasPrefix bool
+
+	// importPath is the import path of the package under the module path declared in go.mod, see declaredImportPath.
+	importPath string
+
+	// stableVars are unexported package-level string variables initialized with a constant and never assigned,
+	// e.g. var pkgPrefix = "pkg: ". Messages built from them are checked as if they were constants.
+	stableVars map[*types.Var]string
+
+	// aliases are the re-exports of the types of the package by other packages of the module, set by
+	// config.aliasPackages. See moduleAliases.
+	aliases map[string][]typeAlias
+
+	// reported are positions of the error constructor calls already reported.
+	reported map[token.Pos]bool
+
+	// coverage are the error paths of the functions for the coverage summaries, see recordPath.
+	coverage map[*ast.FuncDecl]*funcCoverage
+
+	// styles are the prefix styles of the accepted messages by call position, see recordStyle.
+	styles map[token.Pos]styleSite
+
+	// recommendations are the quoted location prefixes of the functions recommended in diagnostics,
+	// see generatePrefixRecomendations.
+	recommendations map[recommendationKey]string
+}
+
+var packageStates sync.Map // *types.Package -> *packageState
+
+// loadPackageState collects the state of the package being analyzed and keeps it until the returned function
+// is called.
+func loadPackageState(pass *analysis.Pass) (release func()) {
+	state := &packageState{stableVars: stableVars(pass), reported: make(map[token.Pos]bool),
+		coverage: make(map[*ast.FuncDecl]*funcCoverage), styles: make(map[token.Pos]styleSite),
+		recommendations: make(map[recommendationKey]string)}
+	state.prefix, state.hasPrefix = parsePrefixDirective(pass)
+	state.importPath, _ = declaredImportPath(pass)
+	if config.aliasPackages {
+		state.aliases = moduleAliases(pass)
+	}
+	packageStates.Store(pass.Pkg, state)
+	return func() { packageStates.Delete(pass.Pkg) }
+}
+
+// stateOf returns the state of a package being analyzed or nil.
+func stateOf(pkg *types.Package) *packageState {
+	state, ok := packageStates.Load(pkg)
+	if !ok {
+		return nil
+	}
+	return state.(*packageState)
+}
+
+// stableVars finds unexported package-level string variables which are initialized with a constant and
+// never changed afterwards: neither assigned nor taken the address of.
+func stableVars(pass *analysis.Pass) map[*types.Var]string {
+	vars := make(map[*types.Var]string)
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				if len(valueSpec.Names) != len(valueSpec.Values) {
+					continue
+				}
+				for i, name := range valueSpec.Names {
+					v, ok := pass.TypesInfo.Defs[name].(*types.Var)
+					if !ok || name.IsExported() {
+						continue
+					}
+					if value, ok := constantValueString(pass, valueSpec.Values[i]); ok {
+						vars[v] = value
+					}
+				}
+			}
+		}
+	}
+	if len(vars) == 0 {
+		return vars
+	}
+
+	changed := func(expr ast.Expr) {
+		for {
+			paren, ok := expr.(*ast.ParenExpr)
+			if !ok {
+				break
+			}
+			expr = paren.X
+		}
+		if ident, ok := expr.(*ast.Ident); ok {
+			if v, ok := pass.TypesInfo.Uses[ident].(*types.Var); ok {
+				delete(vars, v)
+			}
+		}
+	}
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.AssignStmt:
+				for _, lhs := range node.Lhs {
+					changed(lhs)
+				}
+			case *ast.RangeStmt:
+				if node.Tok == token.ASSIGN {
+					if node.Key != nil {
+						changed(node.Key)
+					}
+					if node.Value != nil {
+						changed(node.Value)
+					}
+				}
+			case *ast.IncDecStmt:
+				changed(node.X)
+			case *ast.UnaryExpr:
+				if node.Op == token.AND {
+					changed(node.X)
+				}
+			}
+			return true
+		})
+	}
+	return vars
+}
+
+// stableString returns the value of a string expression built of constants and stable variables
+// with the + operator, e.g. pkgPrefix + "not found".
+func stableString(pass *analysis.Pass, expr ast.Expr) (string, bool) {
+	if value, ok := constantValueString(pass, expr); ok {
+		return value, true
+	}
+	switch expr := expr.(type) {
+	case *ast.ParenExpr:
+		return stableString(pass, expr.X)
+	case *ast.BasicLit:
+		if expr.Kind != token.STRING {
+			return "", false
+		}
+		value, err := strconv.Unquote(expr.Value)
+		return value, err == nil
+	case *ast.SelectorExpr:
+		// a constant of another package: errs.PrefUser
+		return constString(pass, expr.Sel)
+	case *ast.Ident:
+		if value, ok := constString(pass, expr); ok {
+			return value, true
+		}
+		v, ok := pass.TypesInfo.Uses[expr].(*types.Var)
+		if !ok {
+			return "", false
+		}
+		state := stateOf(pass.Pkg)
+		if state == nil {
+			return "", false
+		}
+		value, ok := state.stableVars[v]
+		return value, ok
+	case *ast.BinaryExpr:
+		if expr.Op != token.ADD {
+			return "", false
+		}
+		x, ok := stableString(pass, expr.X)
+		if !ok {
+			return "", false
+		}
+		y, ok := stableString(pass, expr.Y)
+		return x + y, ok
+	}
+	return "", false
+}
+
+// constString returns the value of a string constant an identifier refers to. Constants are usually folded
+// by the type checker, but drivers may leave the values of some expressions unrecorded, e.g. of a constant
+// declared in another file of the package, so the declaration is looked up as a fallback.
+func constString(pass *analysis.Pass, ident *ast.Ident) (string, bool) {
+	c, ok := pass.TypesInfo.ObjectOf(ident).(*types.Const)
+	if !ok || c.Val().Kind() != constant.String {
+		return "", false
+	}
+	return constant.StringVal(c.Val()), true
+}
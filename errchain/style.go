@@ -0,0 +1,153 @@
+package errchain
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var rulePrefixStyle = registerRule(rule{
+	code:             "prefix-style",
+	doc:              "prefixes of a package follow the style of most of its messages, e.g. pkg.Recv.Method rather than pkg",
+	enabledByDefault: func(c *configuration) bool { return c.styleConsistency > 0 || c.dominantStyle },
+	flags:            []string{"style-consistency", "dominant-style"},
+})
+
+// A prefixStyle is the set of location components a prefix has relative to the function it is written in.
+type prefixStyle int
+
+const (
+	stylePackage  prefixStyle = iota // pkg
+	styleFull                        // pkg.Func or pkg.Recv.Method
+	styleReceiver                    // pkg.Recv in a method
+	styleMethod                      // pkg.Method in a method
+)
+
+func (s prefixStyle) String() string {
+	switch s {
+	case stylePackage:
+		return "the package only"
+	case styleFull:
+		return "the function"
+	case styleReceiver:
+		return "the receiver only"
+	}
+	return "the method without the receiver"
+}
+
+// A styleSite is a prefixed message of a function recorded for the prefix-style rule.
+type styleSite struct {
+	fn     *funcInfo
+	style  prefixStyle
+	prefix string // the prefix with the separator as written
+}
+
+// recordStyle records the style of a message accepted for a function. Messages matching the location
+// of another function, e.g. the one calling a helper, and messages of package scope aren't recorded.
+func recordStyle(pass *analysis.Pass, fn *funcInfo, rules componentRules, call *ast.CallExpr, check callCheck) {
+	state := stateOf(pass.Pkg)
+	if state == nil || fn == nil || fn.decl == nil || check.err != nil || !config.enabled(rulePrefixStyle) {
+		return
+	}
+	if _, seen := state.styles[call.Pos()]; seen {
+		return
+	}
+	sep := string(config.separator)
+	i := strings.Index(check.message, sep)
+	if i < 0 {
+		return
+	}
+	loc, err := parsePrefix(check.message)
+	if err != nil || !isPackageName(pass.Pkg, loc.pkg) || loc.match(pass.Pkg, fn, rules) != nil {
+		return
+	}
+	state.styles[call.Pos()] = styleSite{fn: fn, style: loc.style(fn), prefix: check.message[:i+len(sep)]}
+}
+
+// style returns the style of a location matching a function.
+func (loc location) style(fn *funcInfo) prefixStyle {
+	loc = loc.declaredSpelling(fn).promoted(fn)
+	switch {
+	case loc.fn == "":
+		return stylePackage
+	case loc.recv != "", fn.recv == "":
+		return styleFull
+	case loc.fn == fn.prefixRecv():
+		return styleReceiver
+	}
+	return styleMethod
+}
+
+// stylePrefix returns the prefix of a function in a style or false if the style doesn't apply to it,
+// e.g. a function has no receiver.
+func stylePrefix(pkg string, fn *funcInfo, style prefixStyle) (string, bool) {
+	loc := location{pkg: pkg}
+	switch style {
+	case styleFull:
+		loc.fn = fn.name
+		if fn.recv != "" {
+			loc.recv = fn.recvNames()[0]
+		}
+	case styleReceiver, styleMethod:
+		if fn.recv == "" {
+			return "", false
+		}
+		loc.fn = fn.name
+		if style == styleReceiver {
+			loc.fn = fn.recvNames()[0]
+		}
+	}
+	return loc.String() + string(config.separator), true
+}
+
+// reportStyles reports the messages of the package not following the dominant prefix style: the style of
+// at least -style-consistency percent of the recorded messages or, with -dominant-style, the most used one
+// whatever its share. There is no dominant style if two styles are used equally.
+func reportStyles(pass *analysis.Pass) {
+	state := stateOf(pass.Pkg)
+	if state == nil || len(state.styles) == 0 {
+		return
+	}
+	counts := make(map[prefixStyle]int)
+	for _, site := range state.styles {
+		counts[site.style]++
+	}
+	if len(counts) < 2 {
+		return
+	}
+	dominant, tie := stylePackage, false
+	for style, n := range counts {
+		switch {
+		case n > counts[dominant]:
+			dominant, tie = style, false
+		case n == counts[dominant] && style != dominant:
+			tie = true
+		}
+	}
+	total := len(state.styles)
+	if tie || !config.dominantStyle && counts[dominant]*100 < config.styleConsistency*total {
+		return
+	}
+
+	positions := make([]token.Pos, 0, len(state.styles))
+	for pos := range state.styles {
+		positions = append(positions, pos)
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i] < positions[j] })
+	name := packageNames(pass.Pkg)[0]
+	for _, pos := range positions {
+		site := state.styles[pos]
+		if site.style == dominant {
+			continue
+		}
+		expected, ok := stylePrefix(name, site.fn, dominant)
+		if !ok {
+			continue
+		}
+		reportf(pass, pos, rulePrefixStyle, "Prefix %q names %s, while %d of %d messages of the package name %s, consider %q",
+			site.prefix, site.style, counts[dominant], total, dominant, expected)
+	}
+}
@@ -1,4 +1,4 @@
-package aaa
+package aaa // want package:`PrefixNamespace\(aaa\)`
 
 import (
 	"errors"
@@ -21,7 +21,7 @@ func (x *Struct) Method(input string) (int, error) {
 		return 0, fmt.Errorf("input too short, require longer than %d, input=%q", 3, input) // want `Error message must point to the place where it had happened. Consider starting message with one of the following strings: "aaa: ", "aaa\.Struct\.Method: ", "aaa\.\(\*Struct\)\.Method: "`
 	}
 	if len(input) < 4 {
-		return 0, fmt.Errorf("aaa.(*Struct.Method: error") // want `Error message must point to the place where it had happened. Consider starting message with one of the following strings: "aaa: ", "aaa\.Struct\.Method: ", "aaa\.\(\*Struct\)\.Method: "`
+		return 0, fmt.Errorf("aaa.(*Struct.Method: error") // want `Error message must point to the place where it had happened: syntax is wrong, expected "aaa\.\(\*Struct\)\.Method: "`
 	}
 	if len(input) < 5 {
 		return 0, errors.New("errrrrr") // want `Error message must point to the place where it had happened. Consider starting message with one of the following strings: "aaa: ", "aaa\.Struct\.Method: ", "aaa\.\(\*Struct\)\.Method: "`
@@ -34,8 +34,8 @@ func (x *Struct) Method(input string) (int, error) {
 	return 0, nil
 }
 
-func (x Struct) MethodWithoutPointer() error {
-	return fmt.Errorf("aaa.(*Struct).MethodWithoutPointer: error") // want `Error message must point to the place where it had happened. Consider starting message with one of the following strings: "aaa: ", "aaa\.Struct\.MethodWithoutPointer: "`
+func (x Struct) MethodWithoutPointer() error { // want MethodWithoutPointer:"PrefixedErrorFunc"
+	return fmt.Errorf("aaa.(*Struct).MethodWithoutPointer: error")
 }
 
 func (x *Struct) method() (string, error) {
@@ -57,15 +57,28 @@ func PublicFunction() error {
 }
 
 var AnonymFunc = func() error {
-	return errors.New("such functions are skiped for now but should be handled in future")
+	return errors.New("package-level function literals are checked too") // want `Error message must point to the place where it had happened. Consider starting message with one of the following strings: "aaa: ", "aaa\.AnonymFunc: "`
 }
 
 func PublicFunction2() error {
-	text := "passing message via var or function call is not supported yet"
-	return errors.New(text)
+	text := "messages of local variables are checked too"
+	return errors.New(text) // want `Consider starting message with one of the following strings: "aaa: ", "aaa\.PublicFunction2: " \(the message is set at line 64\)`
 }
 
 func PublicFunction3() string {
 	err := errors.New("skip check if function doesn't return an error")
 	return err.Error()
 }
+
+func (x *Struct) Other() error {
+	if x == nil {
+		return errors.New("aaa.Struct.Method: copied from another method") // want `Error message must point to the place where it had happened: prefix refers to a sibling method aaa\.Struct\.Method, copied from it\? This code is in aaa\.Struct\.Other$`
+	}
+	if x != nil {
+		return errors.New("aaa.Struct.Othr: typo") // want `Error message must point to the place where it had happened: method not found, did you mean aaa\.Struct\.Other\?`
+	}
+	if x != nil {
+		return errors.New("aaa.Strutc.Other: typo") // want `Error message must point to the place where it had happened: reciever not found, did you mean aaa\.Struct\.Other\?`
+	}
+	return errors.New("aaa.PublicFunction: copied from another function") // want `Error message must point to the place where it had happened: neither func nor struct has been found, prefix refers to aaa\.PublicFunction declared in the package`
+}
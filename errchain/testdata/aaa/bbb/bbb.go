@@ -1,7 +1,7 @@
-package bbb
+package bbb // want package:`PrefixNamespace\(bbb\)`
 
 import "fmt"
 
-func SubPkgFunction() error {
+func SubPkgFunction() error { // want SubPkgFunction:"PrefixedErrorFunc"
 	return fmt.Errorf("aaa/bbb.SubPkgFunction: err")
 }
@@ -0,0 +1,41 @@
+package aaa
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Builder struct {
+	name  string
+	err   error
+	state struct{ err error }
+}
+
+func (b *Builder) WithName(name string) *Builder {
+	if name == "" {
+		b.err = errors.New("aaa.Builder.WithName: empty name")
+		return b
+	}
+	if len(name) > 64 {
+		b.err = fmt.Errorf("name %q is too long", name) // want `Error message must point to the place where it had happened: Consider starting message with one of the following strings: "aaa: ", "aaa\.Builder\.WithName: ", "aaa\.\(\*Builder\)\.WithName: ", "aaa\.Builder: "`
+		return b
+	}
+	b.name = name
+	return b
+}
+
+func (b *Builder) WithState(ok bool) *Builder {
+	if !ok {
+		b.state.err = errors.New("aaa.Builder.Build: bad state") // want `Error message must point to the place where it had happened: prefix refers to a sibling method aaa\.Builder\.Build, copied from it\? This code is in aaa\.Builder\.WithState`
+	}
+	other := errors.New("not stored in the receiver")
+	_ = other
+	return b
+}
+
+func (b *Builder) Build() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+	return b.name, nil
+}
@@ -0,0 +1,35 @@
+package aaa
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Service struct{}
+
+func (s *Service) Handlers() []func() error {
+	h := s.process
+	v := (*Service).validate
+	return []func() error{h, func() error { return v(s) }, s.helper}
+}
+
+func (s *Service) Run() error {
+	return s.called() // want `error of called is returned as is, wrap it: fmt.Errorf\("aaa.Service.Run: %w", err\)`
+}
+
+func (s *Service) process() error {
+	return errors.New("process failed") // want `Error message must point to the place where it had happened: Consider starting message with one of the following strings: "aaa: ", "aaa.Service.process: ", "aaa.\(\*Service\).process: ", "aaa.Service: "`
+}
+
+func (s *Service) validate() error {
+	return errors.New("aaa.Service.validat: failed") // want `Error message must point to the place where it had happened: method not found, did you mean aaa.Service.validate\?`
+}
+
+func (s *Service) helper() error { // want helper:"PrefixedErrorFunc"
+	return fmt.Errorf("aaa.Service.helper: failed")
+}
+
+func (s *Service) called() error {
+	// Run forwards to the method, so the message may start with the prefix of Run
+	return errors.New("called failed") // want `Error message must point to the place where it had happened: Consider starting message with one of the following strings: "aaa: ", "aaa\.Service\.called: ", "aaa\.\(\*Service\)\.called: ", "aaa\.Service: ", "aaa\.Service\.Run: ", "aaa\.\(\*Service\)\.Run: "`
+}
@@ -0,0 +1,15 @@
+package aaa
+
+import "fmt"
+
+const getPrefix2 = "aaa.DynamicPrefix"
+
+func DynamicPrefix(name string, n int) error {
+	switch n {
+	case 0:
+		return fmt.Errorf("%q: not found", name) // want `Error message must point to the place where it had happened: prefix must be static, move the value after the prefix: "aaa.DynamicPrefix: %q: not found"`
+	case 1:
+		return fmt.Errorf("%s: %d is out of range", getPrefix2, n)
+	}
+	return fmt.Errorf("%v", name) // want `prefix must be static`
+}
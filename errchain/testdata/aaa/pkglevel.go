@@ -0,0 +1,24 @@
+package aaa
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrSentinel = errors.New("aaa: sentinel error")
+
+var errUnprefixed = errors.New("sentinel error") // want `Error message must point to the place where it had happened. Consider starting message with one of the following strings: "aaa: ", "aaa\.errUnprefixed: "`
+
+var handler = makeHandler(errors.New("aaa.handler: error"))
+
+var registry []error
+
+func makeHandler(err error) func() error {
+	return func() error { return err }
+}
+
+func init() {
+	registry = append(registry, errors.New("aaa: registered error"))
+	registry = append(registry, fmt.Errorf("aaa.init: registered error")) // want `Error message must point to the place where it had happened: neither func nor struct has been found`
+	registry = append(registry, errors.New("registered error"))           // want `Error message must point to the place where it had happened. Consider starting message with one of the following strings: "aaa: "`
+}
@@ -0,0 +1,39 @@
+package aaa
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+func parse(s string) (int, error) { // want parse:"PrefixedErrorFunc"
+	if s == "" {
+		return 0, errors.New("aaa.parse: empty string")
+	}
+	return len(s), nil
+}
+
+func atoi(s string) (int, error) {
+	return strconv.Atoi(s)
+}
+
+func Propagate(s string) (int, error) {
+	switch s {
+	case "a":
+		return parse(s)
+	case "b":
+		return atoi(s) // want `error of atoi is returned as is, wrap it: fmt.Errorf\("aaa.Propagate: %w", err\)`
+	}
+	n, err := atoi(s)
+	if err != nil {
+		return 0, err // want `error of atoi is returned as is, wrap it`
+	}
+	m, err := parse(s)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := atoi(s); err != nil {
+		return 0, fmt.Errorf("aaa.Propagate: %w", err)
+	}
+	return n + m, nil
+}
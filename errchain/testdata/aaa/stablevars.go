@@ -0,0 +1,39 @@
+package aaa
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	pkgPrefix    = "aaa: "
+	wrongPrefix  = "bbb: "
+	getPrefix    = pkgPrefix[:3] + ".StableGet: "
+	mutable      = "aaa: "
+	addressTaken = "aaa: "
+)
+
+func init() {
+	mutable = "x"
+	_ = &addressTaken
+}
+
+func StableVars(n int) error {
+	switch n {
+	case 0:
+		return errors.New(pkgPrefix + "not found")
+	case 1:
+		return errors.New((pkgPrefix) + "zero")
+	case 2:
+		return fmt.Errorf("%sbad number %d", pkgPrefix, n)
+	case 3:
+		return errors.New(wrongPrefix + "not found") // want `Error message must point to the place where it had happened: package name mismatch`
+	case 4:
+		return errors.New(mutable + "changed at runtime") // want `Error message must point to the place where it had happened: prefix must be static`
+	case 5:
+		return errors.New(addressTaken + "changed at runtime") // want `Error message must point to the place where it had happened: prefix must be static`
+	case 6:
+		return errors.New("not found: " + pkgPrefix) // want `Error message must point to the place where it had happened: package name mismatch`
+	}
+	return errors.New(getPrefix + "slicing isn't resolved") // want `Error message must point to the place where it had happened: prefix must be static`
+}
@@ -0,0 +1,36 @@
+package aaa
+
+import (
+	"errors"
+	"fmt"
+)
+
+func IfInit(n int) error {
+	if err := fmt.Errorf("negative %d", n); n < 0 { // want `Error message must point to the place where it had happened: Consider starting message with one of the following strings: "aaa: ", "aaa.IfInit: "`
+		return err
+	}
+	if err := errors.New("aaa.IfInit: zero"); n == 0 {
+		return err
+	}
+	return nil
+}
+
+func TypeSwitch(x interface{}) error {
+	switch v := x.(type) {
+	case int:
+		return fmt.Errorf("unexpected int %d", v) // want `Error message must point to the place where it had happened: Consider starting message with one of the following strings: "aaa: ", "aaa.TypeSwitch: "`
+	case string:
+		if err := errors.New("aaa.TypeSwitch: empty string"); v == "" {
+			return err
+		}
+	case func() error:
+		return func() error {
+			switch v := x.(type) {
+			case error:
+				return fmt.Errorf("aaa.TypeSwitch: %w", v)
+			}
+			return errors.New("nested literal") // want `Error message must point to the place where it had happened: Consider starting message with one of the following strings: "aaa: ", "aaa.TypeSwitch: "`
+		}()
+	}
+	return nil
+}
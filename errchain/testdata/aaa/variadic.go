@@ -0,0 +1,19 @@
+package aaa
+
+import "fmt"
+
+func VariadicArgs(args ...interface{}) error {
+	if len(args) == 0 {
+		return fmt.Errorf("aaa.VariadicArgs: %d %s", args...)
+	}
+	if len(args) == 1 {
+		return fmt.Errorf("%s: the prefix isn't rendered", args...) // want `Error message must point to the place where it had happened: .*"aaa\.VariadicArgs: "`
+	}
+	if len(args) == 2 {
+		return fmt.Errorf("bbb.VariadicArgs: %d %s", args...) // want `Error message must point to the place where it had happened: package name mismatch`
+	}
+	if len(args) == 3 {
+		return fmt.Errorf("", args...) // want `Error message must point to the place where it had happened: .*"aaa\.VariadicArgs: "`
+	}
+	return fmt.Errorf("no prefix %d%%", args...) // want `Error message must point to the place where it had happened: .*"aaa\.VariadicArgs: "`
+}
@@ -0,0 +1,36 @@
+package transport // want package:`PrefixNamespace\(transport\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Client struct {
+	addr string
+}
+
+func (c *Client) Do(req string) error { // want Do:"PrefixedErrorFunc"
+	if req == "" {
+		return errors.New("sdk.Client.Do: empty request")
+	}
+	if c.addr == "" {
+		return errors.New("sdk.(*Client).Do: no address")
+	}
+	return fmt.Errorf("transport.Client.Do: %s: not implemented", req)
+}
+
+func (c *Client) Close() error { // want Close:"PrefixedErrorFunc"
+	return errors.New("sdk.Close: already closed")
+}
+
+type Conn struct{}
+
+func (c Conn) Read() error {
+	return errors.New("sdk.Client.Read: closed") // want `prefix refers to package sdk \(sdk\), but this code is in transport`
+}
+
+type Options struct{}
+
+func (o Options) Validate() error {
+	return errors.New("sdk.Options.Validate: empty") // want `prefix refers to package sdk \(sdk\), but this code is in transport`
+}
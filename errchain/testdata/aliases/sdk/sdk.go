@@ -0,0 +1,15 @@
+// Package sdk is the public API of the module re-exporting the transport client.
+package sdk
+
+import (
+	"example.com/aliases/internal/transport"
+)
+
+type Client = transport.Client
+
+type Conn = transport.Conn
+
+// Options aren't re-exported, so their methods keep the transport prefixes.
+type options struct {
+	transport.Options
+}
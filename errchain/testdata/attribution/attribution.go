@@ -0,0 +1,55 @@
+package attribution // want package:`PrefixNamespace\(attribution\)`
+
+import (
+	"errors"
+	"sync"
+)
+
+type Store struct {
+	mu    sync.Mutex
+	items map[string]string
+}
+
+func (s *Store) Get(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load(key) // want `error of load is returned as is`
+}
+
+func (s *Store) load(key string) (string, error) {
+	if key == "" {
+		return "", errors.New("attribution.Store.Get: empty key")
+	}
+	return s.fetch(key)
+}
+
+func (s *Store) fetch(key string) (string, error) {
+	find := func() (string, error) {
+		if v, ok := s.items[key]; ok {
+			return v, nil
+		}
+		return "", errors.New("not found") // want `Consider starting message with one of the following strings: "attribution: ", "attribution\.Store\.fetch: ", "attribution\.\(\*Store\)\.fetch: ", "attribution\.Store: ", "attribution\.Store\.Get: ", "attribution\.\(\*Store\)\.Get: "$`
+	}
+	return find()
+}
+
+var defaultStore = &Store{}
+
+func init() {
+	_ = defaultStore.reset()
+}
+
+func (s *Store) Reset() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reset() // want `error of reset is returned as is`
+}
+
+// reset is called by init as well, so its errors aren't attributed to Reset.
+func (s *Store) reset() error {
+	return s.clear()
+}
+
+func (s *Store) clear() error {
+	return errors.New("not checked")
+}
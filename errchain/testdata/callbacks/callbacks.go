@@ -0,0 +1,30 @@
+package callbacks // want package:`PrefixNamespace\(callbacks\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Router struct{}
+
+func (r *Router) Handle(path string, h func() error) {}
+
+func Register(r *Router) error {
+	r.Handle("/a", func() error {
+		return errors.New("callbacks: handler failed")
+	})
+	r.Handle("/b", func() error {
+		return errors.New("callbacks.Register: handler failed") // want `Error message must point to the place where it had happened: neither func nor struct has been found`
+	})
+	r.Handle("/c", func() error {
+		return fmt.Errorf("handler failed") // want `Error message must point to the place where it had happened: Consider starting message with one of the following strings: "callbacks: "`
+	})
+
+	check := func() error {
+		return errors.New("callbacks.Register: not a callback")
+	}
+	if err := check(); err != nil {
+		return err
+	}
+	return errors.New("callbacks.Register: failed")
+}
@@ -0,0 +1,29 @@
+package canonical // want package:`PrefixNamespace\(canonical\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Store struct{}
+
+func (s *Store) Get(key string) error {
+	switch {
+	case key == "":
+		//errchain:canonical "canonical.Store.Get"
+		return errors.New("canonical.Store.Get: empty key")
+	case len(key) > 64:
+		//errchain:canonical "canonical.Store.Get" // from the errgen template
+		return fmt.Errorf("canonical.(*Store).Get: %q: too long", key) // want `Message does not start with the canonical prefix "canonical\.Store\.Get"`
+	case key == "-":
+		//errchain:canonical "canonical.Cache.Get" // want `Canonical prefix "canonical\.Cache\.Get" does not match the function: reciever not found`
+		return errors.New("canonical.Store.Get: reserved key")
+	case key == "*":
+		//errchain:canonical canonical.Store.Get // want `Malformed errchain:canonical directive: the prefix must be a quoted string`
+		return errors.New("canonical.Store.Get: wildcard key")
+	}
+	//errchain:canonical "canonical.Store.Get" // want `Misplaced errchain:canonical directive: there is no error constructor call on the next line`
+	msg := "canonical.Store.Get: " + key
+	//errchain:canonical "canonical.Store.Get"
+	return errors.New(msg) // want `Message is not static, the canonical prefix "canonical\.Store\.Get" can't be verified`
+}
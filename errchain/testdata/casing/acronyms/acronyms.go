@@ -0,0 +1,35 @@
+package acronyms // want package:`PrefixNamespace\(acronyms\)`
+
+import "errors"
+
+type JSONEncoder struct{}
+
+func (e *JSONEncoder) Encode() error { // want Encode:"PrefixedErrorFunc"
+	if e == nil {
+		return errors.New("acronyms.jsonEncoder.Encode: nil encoder")
+	}
+	if true {
+		return errors.New("acronyms.JsonEncoder.Encode: unsupported value")
+	}
+	return errors.New("acronyms.JSONEncoder.Encode: failed")
+}
+
+type HTTPClient struct{}
+
+func (c HTTPClient) GetUserID() error {
+	if true {
+		return errors.New("acronyms.httpClient.GetUserId: no user")
+	}
+	return errors.New("acronyms.HttpCli.GetUserID: no session") // want `reciever not found`
+}
+
+type GRPCServer struct{}
+
+func (s *GRPCServer) Serve() error { // want Serve:"PrefixedErrorFunc"
+	return errors.New("acronyms.grpcServer.Serve: closed")
+}
+
+// Only acronyms are compared ignoring the case.
+func Decode() error {
+	return errors.New("acronyms.DECODE: failed") // want `neither func nor struct has been found`
+}
@@ -0,0 +1,12 @@
+package fold // want package:`PrefixNamespace\(fold\)`
+
+import "errors"
+
+type JSONEncoder struct{}
+
+func (e *JSONEncoder) Encode() error {
+	if e == nil {
+		return errors.New("fold.jsonencoder.encode: nil encoder")
+	}
+	return errors.New("fold.JSONDecoder.Encode: failed") // want `reciever not found, did you mean fold.JSONEncoder.Encode\?`
+}
@@ -0,0 +1,44 @@
+package chains // want package:`PrefixNamespace\(chains\)`
+
+import (
+	"errors"
+	"fmt"
+
+	"example.com/chains/store"
+)
+
+type Cache struct{}
+
+func (c *Cache) Get(key string) error { // want Get:"PrefixedErrorFunc" Get:`PrefixChain\(18\)`
+	return errors.New("chains.Cache.Get: not cached")
+}
+
+func (c *Cache) Fill(key string) error { // want Fill:"PrefixedErrorFunc" Fill:`PrefixChain\(37\)`
+	if err := c.Get(key); err != nil {
+		return fmt.Errorf("chains.Cache.Fill: %w", err) // want `Error chain starts with 37 characters of prefixes, at most 30 are allowed by -max-chain-length`
+	}
+	return nil
+}
+
+func Fetch(key string) error { // want Fetch:"PrefixedErrorFunc" Fetch:`PrefixChain\(37\)`
+	if err := store.Get(key); err != nil {
+		return fmt.Errorf("chains.Fetch: %w", err) // want `Error chain starts with 37 characters of prefixes, at most 30 are allowed by -max-chain-length`
+	}
+	return nil
+}
+
+func Stale(key string) error { // want Stale:"PrefixedErrorFunc" Stale:`PrefixChain\(25\)`
+	return fmt.Errorf("chains.Stale: store.Get: %s is stale", key) // want `Error prefix has 4 components, at most 3 are allowed by -max-prefix-components`
+}
+
+func Retry(key string) error { // want Retry:"PrefixedErrorFunc" Retry:`PrefixChain\(51\)`
+	err := Fetch(key)
+	if err != nil {
+		return fmt.Errorf("chains.Retry: %w", err) // want `Error chain starts with 51 characters of prefixes, at most 30 are allowed by -max-chain-length`
+	}
+	return nil
+}
+
+func Wrap(err error) error { // want Wrap:"PrefixedErrorFunc" Wrap:`PrefixChain\(13\)`
+	return fmt.Errorf("chains.Wrap: %w", err)
+}
@@ -0,0 +1,43 @@
+package collections // want package:`PrefixNamespace\(collections\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+type User struct {
+	Name  string
+	Email string
+}
+
+func Validate(u User) []error {
+	var errs []error
+	if u.Name == "" {
+		errs = append(errs, errors.New("collections.Validate: empty name"))
+	}
+	if u.Email == "" {
+		errs = append(errs, errors.New("empty email")) // want `Consider starting message with one of the following strings: "collections: ", "collections\.Validate: "`
+	}
+	return errs
+}
+
+func (u User) Fields() map[string]error {
+	return map[string]error{
+		"name":  fmt.Errorf("collections.User.Fields: name %q is taken", u.Name),
+		"email": fmt.Errorf("email %q is taken", u.Email), // want `Consider starting message with one of the following strings: "collections: ", "collections\.User\.Fields: "`
+	}
+}
+
+func Limits(n int) (int, [2]error) {
+	return n, [2]error{errors.New("too small"), nil} // want `Consider starting message with one of the following strings: "collections: ", "collections\.Limits: "`
+}
+
+// Other collections aren't errors.
+
+func Names() []string {
+	return []string{errors.New("not an error result").Error()}
+}
+
+func validate(u User) []error {
+	return []error{errors.New("unexported functions aren't checked")}
+}
@@ -0,0 +1,28 @@
+package components // want package:`PrefixNamespace\(components\)`
+
+import "errors"
+
+type Struct struct{}
+
+func (x *Struct) Method() error {
+	if x == nil {
+		return errors.New("Struct.Method: nil receiver")
+	}
+	if x != nil {
+		return errors.New("(*Struct).Method: error")
+	}
+	if x != nil {
+		return errors.New("components.Struct.Method: error")
+	}
+	if x != nil {
+		return errors.New("Struct: error") // want `Error message must point to the place where it had happened: function name is required. Consider starting message with one of the following strings: "components\.Struct\.Method: ", "components\.\(\*Struct\)\.Method: ", "Struct\.Method: ", "\(\*Struct\)\.Method: "`
+	}
+	return errors.New("Other.Method: error") // want `Error message must point to the place where it had happened: package name mismatch`
+}
+
+func Function() error {
+	if true {
+		return errors.New("Function: error")
+	}
+	return errors.New("components: error") // want `Error message must point to the place where it had happened: function name is required. Consider starting message with one of the following strings: "components\.Function: ", "Function: "`
+}
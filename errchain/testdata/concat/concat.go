@@ -0,0 +1,27 @@
+package concat // want package:`PrefixNamespace\(concat\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+func Get(key, details string, err error) error {
+	switch key {
+	case "a":
+		return errors.New("concat.Get: " + details)
+	case "b":
+		return errors.New("concat.Get: " + key + ": " + details)
+	case "c":
+		return errors.New(details + ": boom") // want `Error message must point to the place where it had happened: prefix must be static`
+	case "d":
+		return errors.New("concat.Fetch: " + details) // want `Error message must point to the place where it had happened: neither func nor struct has been found`
+	case "e":
+		return fmt.Errorf("concat.Get: "+details+": %w", err)
+	case "f":
+		return fmt.Errorf(details+": %s", key) // want `Error message must point to the place where it had happened: prefix must be static`
+	case "g":
+		return errors.New("not found: " + details) // want `Error message must point to the place where it had happened: package name mismatch`
+	}
+	// the prefix spans the static and dynamic parts, so it isn't checked
+	return errors.New("concat." + details + ": boom")
+}
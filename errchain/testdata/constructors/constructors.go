@@ -0,0 +1,29 @@
+package constructors // want package:`PrefixNamespace\(constructors\)`
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+func Open(name string) (*os.File, error) {
+	if name == "" {
+		return nil, errors.New("empty name") // want `Consider starting message with one of the following strings: "constructors: ", "constructors\.Open: "`
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "open %s", name) // want `Consider starting message with one of the following strings: "constructors: ", "constructors\.Open: "`
+	}
+	return f, nil
+}
+
+func Remove(name string) error { // want Remove:"PrefixedErrorFunc"
+	if err := os.Remove(name); err != nil {
+		return errors.Wrap(err, "constructors.Remove: remove")
+	}
+	return errors.Errorf("constructors.Remove: %d%% done", 100)
+}
+
+func Check(n int) error {
+	return errors.Errorf("constructors.Check: %s", n) // want `Format verb %s does not match argument type int`
+}
@@ -0,0 +1,7 @@
+package context // want package:`PrefixNamespace\(context\)`
+
+import "errors"
+
+func Open(_ string, path string) error { // want Open:"PrefixedErrorFunc"
+	return errors.New("context.Open: permission denied") // want `Error message has no context: use fmt.Errorf to include a value, e.g. path`
+}
@@ -0,0 +1,39 @@
+package context
+
+import (
+	stdcontext "context"
+	"errors"
+	"fmt"
+)
+
+var ErrNotFound = errors.New("context: not found")
+
+type Users struct{}
+
+func (u *Users) Get(id int) error {
+	if id < 0 {
+		return errors.New("context.Users.Get: negative id") // want `Error message has no context: use fmt.Errorf to include a value, e.g. id`
+	}
+	if id == 0 {
+		return fmt.Errorf("context.Users.Get: %w", ErrNotFound)
+	}
+	return errors.New("no such user") // want `Consider starting message with one of the following strings`
+}
+
+// Functions without arguments have no context to add.
+func List() error { // want List:"PrefixedErrorFunc"
+	return errors.New("context.List: empty")
+}
+
+// The context and the receiver-like parameters are skipped by the fix.
+func Reserve(ctx stdcontext.Context, u *Users, percent int) error {
+	if percent > 100 {
+		return errors.New("context.Reserve: over 100% full") // want `Error message has no context: use fmt.Errorf to include a value, e.g. percent`
+	}
+	return ctx.Err()
+}
+
+// A function taking nothing worth reporting has no context to add.
+func Ping(ctx stdcontext.Context) error { // want Ping:"PrefixedErrorFunc"
+	return errors.New("context.Ping: unreachable")
+}
@@ -0,0 +1,36 @@
+package coverage // want package:`PrefixNamespace\(coverage\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Store struct {
+	items map[string]string
+}
+
+func (s *Store) Get(key string) (string, error) { // want `1 of 2 error paths of Store\.Get are prefixed`
+	if key == "" {
+		return "", errors.New("empty key") // want `Consider starting message with one of the following strings: "coverage: ", "coverage\.Store\.Get: "`
+	}
+	item, ok := s.items[key]
+	if !ok {
+		return "", fmt.Errorf("coverage.Store.Get: no item %q", key)
+	}
+	return item, nil
+}
+
+func Put(s *Store, key string, n int) error { // want `2 of 2 error paths of Put are prefixed`
+	if key == "" {
+		return errors.New("coverage.Put: empty key")
+	}
+	validate := func() error {
+		return fmt.Errorf("coverage.Put: bad value %s", n) // want `Format verb %s does not match argument type int`
+	}
+	return validate()
+}
+
+// Close has no error paths to summarize.
+func (s *Store) Close() error { // want Close:"PrefixedErrorFunc"
+	return nil
+}
@@ -0,0 +1,17 @@
+package crossfile // want package:`PrefixNamespace\(crossfile\)`
+
+import "errors"
+
+const (
+	prefService = "crossfile.Service"
+	prefStale   = "crossfile.Store"
+	sep         = ": "
+)
+
+type opPrefix string
+
+const prefGet opPrefix = "crossfile.Service.Get"
+
+var errClosed = errors.New(pkgPrefix + "closed")
+
+const pkgPrefix = "crossfile: "
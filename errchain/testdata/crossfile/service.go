@@ -0,0 +1,25 @@
+package crossfile
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Service struct {
+	closed bool
+}
+
+func (s *Service) Open() error { // want Open:"PrefixedErrorFunc"
+	return errors.New(prefService + ".Open" + sep + "already open")
+}
+
+func (s *Service) Get(key string) error { // want Get:"PrefixedErrorFunc"
+	if s.closed {
+		return fmt.Errorf(string(prefGet)+sep+"%w", errClosed)
+	}
+	return fmt.Errorf(string(prefGet)+": %q: not found", key)
+}
+
+func (s *Service) Put(key string) error {
+	return errors.New(prefStale + ".Put: read only") // want `reciever not found`
+}
@@ -0,0 +1,32 @@
+package datapkg // want package:`PrefixNamespace\(datapkg\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The package has no functions returning errors, so only its package-level declarations are checked.
+
+var ErrNotFound = errors.New("not found") // want `Error message must point to the place where it had happened: Consider starting message with one of the following strings: "datapkg: "`
+
+var ErrEmpty = errors.New("datapkg: empty")
+
+var errUnset error
+
+func init() {
+	errUnset = errors.New("unset") // want `Error message must point to the place where it had happened`
+}
+
+type Point struct {
+	X, Y int
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("(%d, %d)", p.X, p.Y)
+}
+
+func (p Point) Check() {
+	if p.X < 0 {
+		panic(errors.New("negative"))
+	}
+}
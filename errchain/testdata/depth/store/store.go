@@ -0,0 +1,23 @@
+package store // want package:`PrefixNamespace\(store\)`
+
+import "errors"
+
+type DB struct{}
+
+func (db *DB) Get(key string) error { // want Get:"PrefixedErrorFunc"
+	if key == "" {
+		return errors.New("store.DB.Get: empty key")
+	}
+	return errors.New("store.(*DB).Get: not found")
+}
+
+func (db *DB) Put(key string) error {
+	if key == "" {
+		return errors.New("store.Put: empty key") // want `reciever name is required`
+	}
+	return errors.New("store: read only") // want `function name is required`
+}
+
+func Open(dsn string) error { // want Open:"PrefixedErrorFunc"
+	return errors.New("store.Open: bad dsn")
+}
@@ -0,0 +1,16 @@
+package tiny // want package:`PrefixNamespace\(tiny\)`
+
+import "errors"
+
+type Buffer struct{}
+
+func (b *Buffer) Grow(n int) error { // want Grow:"PrefixedErrorFunc"
+	if n < 0 {
+		return errors.New("tiny: negative count")
+	}
+	return errors.New("tiny.Buffer.Grow: too large")
+}
+
+func Parse(s string) error {
+	return errors.New("Parse: bad input") // want `package component missing, expected "tiny\.Parse: "`
+}
@@ -0,0 +1,27 @@
+package any // want package:`PrefixNamespace\(any\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+func Get(id int) error { // want Get:"PrefixedErrorFunc"
+	if id == 0 {
+		return errors.New("store/user.go:11: zero id")
+	}
+	return fmt.Errorf("any.Get: id %d not found", id)
+}
+
+func Put(id int) error {
+	switch id {
+	case 0:
+		return errors.New("store/users.go:18: zero id") // want `Error message must point to the place where it had happened: file name is stale, expected "user.go"`
+	case 1:
+		return errors.New("user.go:x: bad line") // want `Error message must point to the place where it had happened`
+	case 2:
+		return errors.New(`store\user.go:22: written on windows`)
+	case 3:
+		return errors.New("store/User.go:24: case-insensitive file system")
+	}
+	return errors.New("not found") // want `Error message must point to the place where it had happened: Consider starting message with one of the following strings: "any: ", "any.Put: ", "user.go:26: "`
+}
@@ -0,0 +1,13 @@
+package file // want package:`PrefixNamespace\(file\)`
+
+import "errors"
+
+func Get(id int) error {
+	switch id {
+	case 0:
+		return errors.New("user.go:8: zero id")
+	case 1:
+		return errors.New("file.Get: location prefixes are not accepted") // want `Error message must point to the place where it had happened: Consider starting message with one of the following strings: "user.go:10: "`
+	}
+	return errors.New("internal/store/profile.go:12: not found") // want `file name is stale, expected "user.go"`
+}
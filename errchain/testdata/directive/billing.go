@@ -0,0 +1,20 @@
+package billing // want package:`PrefixNamespace\(billingapi\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+func Charge(amount int) error { // want Charge:"PrefixedErrorFunc"
+	if amount < 0 {
+		return fmt.Errorf("billingapi.Charge: negative amount %d", amount)
+	}
+	return errors.New("billingapi: not implemented")
+}
+
+func Refund(amount int) error {
+	if amount < 0 {
+		return errors.New("billing.Refund: negative amount") // want `Error message must point to the place where it had happened: package name mismatch, expected "billingapi" from the errchain:prefix directive`
+	}
+	return errors.New("not implemented") // want `Error message must point to the place where it had happened: Consider starting message with one of the following strings: "billingapi: ", "billingapi.Refund: "`
+}
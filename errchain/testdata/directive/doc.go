@@ -0,0 +1,4 @@
+// Package billing is known as billingapi to its clients.
+//
+//errchain:prefix billingapi
+package billing
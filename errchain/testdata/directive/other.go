@@ -0,0 +1,5 @@
+package billing
+
+//errchain:prefix billing-api // want `Malformed errchain:prefix directive: the package name must be an identifier`
+
+//errchain:prefix payments // want `Conflicting errchain:prefix directive: "billingapi" is already set at .*doc.go:3:1`
@@ -0,0 +1,34 @@
+package duplicates // want package:`PrefixNamespace\(duplicates\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrClosed = errors.New("duplicates: closed")
+
+type Store struct{}
+
+func (s *Store) Get(key string) error {
+	if key == "" {
+		return errors.New("empty key") // want `Error message "empty key" is constructed at 2 sites, which makes error chains ambiguous; start it with the function prefix: "duplicates\.Store\.Get: empty key"` `Consider starting message`
+	}
+	return fmt.Errorf("duplicates.Store.Get: %w", ErrClosed)
+}
+
+func (s *Store) Delete(key string) error {
+	if key == "" {
+		return errors.New("empty key") // want `Error message "empty key" is constructed at 2 sites, which makes error chains ambiguous; start it with the function prefix: "duplicates\.Store\.Delete: empty key"` `Consider starting message`
+	}
+	return fmt.Errorf("duplicates.Store.Delete: %w", ErrClosed)
+}
+
+func Open(path string) error { // want Open:"PrefixedErrorFunc"
+	if path == "" {
+		return errors.New("duplicates.Open: bad path") // want `Error message "duplicates\.Open: bad path" is constructed at 2 sites, which makes error chains ambiguous$`
+	}
+	if path == "/" {
+		return errors.New("duplicates.Open: bad path") // want `Error message "duplicates\.Open: bad path" is constructed at 2 sites, which makes error chains ambiguous$`
+	}
+	return errors.New("duplicates.Open: not implemented")
+}
@@ -0,0 +1,18 @@
+package errorposition // want package:`PrefixNamespace\(errorposition\)`
+
+import "errors"
+
+func Legacy(s string) (error, bool) { // want `Error should be the last result`
+	if s == "" {
+		return errors.New("empty string"), false // want `Error message must point to the place where it had happened`
+	}
+	return errors.New("errorposition.Legacy: error"), true
+}
+
+func Regular() (int, error) {
+	return 0, errors.New("no prefix") // want `Error message must point to the place where it had happened`
+}
+
+func legacy() (error, bool) {
+	return errors.New("private functions are allowed to return any error message"), false
+}
@@ -0,0 +1,19 @@
+package factory // want package:`PrefixNamespace\(factory\)`
+
+import "fmt"
+
+func ErrTooBig(limit int) error {
+	return fmt.Errorf("factory: too big: %d", limit) // want `Error message must point to the place where it had happened: function name is required. Consider starting message with one of the following strings: "factory\.ErrTooBig: "`
+}
+
+func NewErrTooSmall(limit int) error { // want NewErrTooSmall:"PrefixedErrorFunc"
+	return fmt.Errorf("factory.NewErrTooSmall: too small: %d", limit)
+}
+
+func Errorf(format string, args ...interface{}) error { // want Errorf:"PrefixedErrorFunc"
+	return fmt.Errorf("Errorf: not a factory")
+}
+
+func Regular() error { // want Regular:"PrefixedErrorFunc"
+	return fmt.Errorf("Regular: regular function")
+}
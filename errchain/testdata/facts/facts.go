@@ -0,0 +1,55 @@
+package facts // want package:`PrefixNamespace\(facts\)`
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+func Parse(s string) (int, error) { // want Parse:"PrefixedErrorFunc"
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("facts.Parse: %w", err)
+	}
+	return n, nil
+}
+
+func Load(s string) (int, error) { // want Load:"PrefixedErrorFunc"
+	if s == "" {
+		return 0, errors.New("facts.Load: empty input")
+	}
+	return Parse(s)
+}
+
+func Reload(s string) error { // want Reload:"PrefixedErrorFunc"
+	_, err := Load(s)
+	if err != nil {
+		return fmt.Errorf("facts.Reload: %w", err)
+	}
+	return nil
+}
+
+func PassThrough(s string) error {
+	_, err := strconv.Atoi(s)
+	return err
+}
+
+func Unprefixed() error {
+	return errors.New("no prefix") // want `Error message must point to the place where it had happened`
+}
+
+func CallsUnprefixed() error {
+	return Unprefixed()
+}
+
+func Named() (err error) {
+	err = errors.New("facts.Named: error")
+	return
+}
+
+func parse(s string) error { // want parse:"PrefixedErrorFunc"
+	if s == "" {
+		return errors.New("facts.parse: empty input")
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package fixes // want package:`PrefixNamespace\(fixes\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Client struct{}
+
+func (c *Client) Do(n int) error {
+	if n < 0 {
+		return errors.New("negative input") // want `Error message must point to the place where it had happened`
+	}
+	if n == 0 {
+		return fmt.Errorf("zero input %d", n) // want `Error message must point to the place where it had happened`
+	}
+	return nil
+}
+
+func Single() error {
+	return errors.New("single site") // want `Error message must point to the place where it had happened`
+}
+
+func WithOp(op string) error {
+	if op == "" {
+		return errors.New("empty op") // want `Error message must point to the place where it had happened`
+	}
+	return errors.New("bad op") // want `Error message must point to the place where it had happened`
+}
+
+func (c *Client) Close() error {
+	return errors.New("fixes.(*Client.Close: already closed") // want `syntax is wrong, expected "fixes\.\(\*Client\)\.Close: "`
+}
+
+func (c *Client) Reset(n int) error {
+	return fmt.Errorf("fixes.*Client).Reset: bad size %d", n) // want `syntax is wrong, expected "fixes\.\(\*Client\)\.Reset: "`
+}
+
+func (c Client) String() error {
+	return errors.New("fixes.(Client).String: no name") // want `syntax is wrong, expected "fixes\.Client\.String: "`
+}
+
+func (c *Client) Open() error {
+	return errors.New("fixes.Client.Close: not open") // want `prefix refers to a sibling method fixes\.Client\.Close, copied from it\?`
+}
+
+// Point has methods with value receivers only.
+type Point struct{}
+
+func (p Point) Validate() error {
+	return errors.New("fixes.(*Point).Validate: out of range") // want `reciever has no pointer`
+}
@@ -0,0 +1,45 @@
+package forwarders // want package:`PrefixNamespace\(forwarders\)`
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Do forwards to do, so the chain shows the prefix of do, which refers to Do.
+func Do(x int) error { // want Do:"PrefixedErrorFunc"
+	return do(x)
+}
+
+func do(x int) error { // want do:"PrefixedErrorFunc"
+	if x < 0 {
+		return errors.New("forwarders.Do: negative")
+	}
+	return fmt.Errorf("forwarders.Do: %d is too large", x)
+}
+
+// Both forwarders are accepted in the prefixes of send.
+func Send(msg string) error {
+	return send(context.Background(), msg) // want `error of send is returned as is`
+}
+
+func SendContext(ctx context.Context, msg string) error {
+	return send(ctx, msg) // want `error of send is returned as is`
+}
+
+func send(ctx context.Context, msg string) error {
+	if msg == "" {
+		return errors.New("forwarders.SendContext: empty message")
+	}
+	return errors.New("no route") // want `Consider starting message with one of the following strings: "forwarders: ", "forwarders\.send: ", "forwarders\.Send: ", "forwarders\.SendContext: "`
+}
+
+// Close isn't a forwarder, so messages of closeAll aren't checked.
+func Close() error {
+	err := closeAll()
+	return err // want `error of closeAll is returned as is`
+}
+
+func closeAll() error {
+	return errors.New("busy")
+}
@@ -0,0 +1 @@
+package generated // want package:`PrefixNamespace\(generated\)`
@@ -0,0 +1,14 @@
+// Code generated by scaffolder, but edited by hand.
+
+package generated
+
+import "errors"
+
+type UserService struct{}
+
+func (s *UserService) Get(id int) error {
+	if id == 0 {
+		return errors.New("generated.UserService.Get: zero id")
+	}
+	return errors.New("not found") // want `Error message must point to the place where it had happened: Consider starting message`
+}
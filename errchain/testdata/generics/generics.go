@@ -0,0 +1,35 @@
+package generics // want package:`PrefixNamespace\(generics\)`
+
+import (
+	"os"
+
+	"example.com/errs"
+)
+
+type Message string
+
+func Load(name string) ([]byte, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, errs.Wrap("read", name, err) // want `Consider starting message with one of the following strings: "generics: ", "generics\.Load: "`
+	}
+	if len(data) == 0 {
+		return nil, errs.New(Message("empty file")) // want `Consider starting message with one of the following strings: "generics: ", "generics\.Load: "`
+	}
+	return data, nil
+}
+
+func Save(name string, data []byte) error { // want Save:"PrefixedErrorFunc"
+	if err := os.WriteFile(name, data, 0o600); err != nil {
+		return errs.Annotate[error](err, "generics.Save: write")
+	}
+	return errs.Wrap[int]("generics.Save: written", len(data), nil)
+}
+
+func Check(n int) error {
+	box := errs.Box[int]{V: n}
+	if n < 0 {
+		return box.Errorf("negative %d", n) // want `Consider starting message with one of the following strings: "generics: ", "generics\.Check: "`
+	}
+	return box.Errorf("generics.Check: %s", n) // want `Format verb %s does not match argument type int`
+}
@@ -0,0 +1,53 @@
+package helpers // want package:`PrefixNamespace\(helpers\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Store struct{}
+
+func (s *Store) Get(key string) (string, error) {
+	return s.get(key) // want `error of get is returned as is, wrap it: fmt.Errorf\("helpers.Store.Get: %w", err\)`
+}
+
+func (s *Store) get(key string) (string, error) {
+	if key == "" {
+		return "", errors.New("empty key") // want `Consider starting message with one of the following strings: "helpers: ", "helpers.Store.get: ", "helpers.\(\*Store\).get: ", "helpers.Store: ", "helpers.Store.Get: ", "helpers.\(\*Store\).Get: "`
+	}
+	return "", fmt.Errorf("helpers.Store.Get: key %s not found", key)
+}
+
+// The helper adds the prefix of its caller, so its errors are verified.
+func Load() error { // want Load:"PrefixedErrorFunc"
+	return load()
+}
+
+func load() error { // want load:"PrefixedErrorFunc"
+	return errors.New("helpers.Load: no config")
+}
+
+// The caller wraps the error, so messages of the helper are not checked.
+func Save() error { // want Save:"PrefixedErrorFunc"
+	if err := save(); err != nil {
+		return fmt.Errorf("helpers.Save: %w", err)
+	}
+	return nil
+}
+
+func save() error {
+	return errors.New("disk is full")
+}
+
+// A helper called by another unexported function isn't checked.
+func Parse() error {
+	return parse() // want `error of parse is returned as is`
+}
+
+func parse() error {
+	return tokenize()
+}
+
+func tokenize() error {
+	return errors.New("unexpected token")
+}
@@ -0,0 +1,27 @@
+package join // want package:`PrefixNamespace\(join\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+func Validate(n int) error {
+	return errors.Join(
+		errors.New("empty"),          // want `Error message must point to the place where it had happened`
+		fmt.Errorf("bad size %d", n), // want `Error message must point to the place where it had happened`
+	)
+}
+
+func Check(n int, err error) error {
+	return errors.Join(err, errors.New("join.Check: empty"), fmt.Errorf("join.Check: bad size %d", n))
+}
+
+func Load(name string, err error) error {
+	if name == "" {
+		return errors.New("no name") // want `Error message must point to the place where it had happened`
+	}
+	if err != nil {
+		return errors.New("not found") // want `Error message must point to the place where it had happened`
+	}
+	return errors.Join(errors.New("closed"), err) // want `Error message must point to the place where it had happened`
+}
@@ -0,0 +1,41 @@
+package lazy // want package:`PrefixNamespace\(lazy\)`
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+type Config struct{}
+
+// not checked with -package-level=false
+var ErrNotFound = errors.New("not found")
+
+var LoadConfig = sync.OnceValues(func() (*Config, error) {
+	return nil, errors.New("read config") // want `Consider starting message with one of the following strings: "lazy: ", "lazy.LoadConfig: "`
+})
+
+var loadEnv = sync.OnceValue(func() error {
+	return fmt.Errorf("lazy.loadEnv: empty env")
+})
+
+var Init = sync.OnceFunc(func() {
+	panic(errors.New("init failed")) // want `Consider starting message with one of the following strings: "lazy: ", "lazy.Init: "`
+})
+
+var Default = struct {
+	Load func() error
+}{
+	Load: sync.OnceValue(func() error {
+		return errors.New("lazy: load")
+	}),
+}
+
+func Get() (*Config, error) {
+	var once sync.Once
+	var err error
+	once.Do(func() {
+		err = errors.New("lazy.Get: load")
+	})
+	return nil, err
+}
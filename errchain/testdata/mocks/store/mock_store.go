@@ -0,0 +1,10 @@
+package store // want `mock file: mock_store.go` package:`PrefixNamespace\(store\)`
+
+import "errors"
+
+// MockStore is regenerated by hand after the generator had been removed.
+type MockStore struct{}
+
+func (m *MockStore) Get(key string) error {
+	return errors.New("unexpected call")
+}
@@ -0,0 +1,14 @@
+package store
+
+import "errors"
+
+type Store interface {
+	Get(key string) error
+}
+
+func Open(dsn string) error { // want Open:"PrefixedErrorFunc"
+	if dsn == "" {
+		return errors.New("store.Open: empty dsn")
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+// storemocks is generated by counterfeiter with a custom header.
+
+package storemocks // want `mock package: .*mocks/storemocks`
+
+import "errors"
+
+type FakeStore struct{}
+
+func (f *FakeStore) Get(key string) error {
+	return errors.New("not found")
+}
@@ -0,0 +1,13 @@
+package cache // want package:`PrefixNamespace\(cache\)`
+
+import "errors"
+
+func Get(key string) error {
+	switch key {
+	case "":
+		return errors.New("storage: empty key") // want `Error message must point to the place where it had happened: prefix refers to package storage \(internal/storage\), but this code is in cache`
+	case "a":
+		return errors.New("cache: not found")
+	}
+	return errors.New("store: not found") // want `Error message must point to the place where it had happened: package name mismatch, expected "cache" from the package clause`
+}
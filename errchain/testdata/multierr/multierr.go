@@ -0,0 +1,38 @@
+package multierr // want package:`PrefixNamespace\(multierr\)`
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/multierr"
+)
+
+func Close(a, b error) error { // want Close:"PrefixedErrorFunc"
+	if a != nil {
+		return multierr.Append(errors.New("multierr.Close: first"), fmt.Errorf("multierr.Close: second"))
+	}
+	return multierr.Combine(errors.New("multierr.Close: first"), nil, multierr.Append(nil, errors.New("multierr: third")))
+}
+
+func Flush(errs []error) error {
+	if len(errs) == 0 {
+		return multierr.Combine(errors.New("no prefix")) // want `Error message must point to the place where it had happened`
+	}
+	if len(errs) == 1 {
+		return multierr.Combine(errs...)
+	}
+	return multierr.Append(errs[0], errs[1])
+}
+
+type Batch struct {
+	err error
+}
+
+func (b *Batch) Add(n int) {
+	if n < 0 {
+		b.err = multierr.Append(b.err, fmt.Errorf("negative %d", n)) // want `Error message must point to the place where it had happened: Consider starting message with one of the following strings: "multierr: ", "multierr.Batch.Add: ", "multierr.\(\*Batch\).Add: ", "multierr.Batch: "`
+	}
+	if n == 0 {
+		b.err = multierr.Append(b.err, errors.New("multierr.Batch.Add: zero"))
+	}
+}
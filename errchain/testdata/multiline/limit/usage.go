@@ -0,0 +1,16 @@
+package limit // want package:`PrefixNamespace\(limit\)`
+
+import "errors"
+
+func Parse(args []string) error {
+	if len(args) == 0 {
+		return errors.New( // want `Error message must point to the place where it had happened`
+			`no arguments
+Usage: tool <file>`)
+	}
+	// the template is longer than -max-multiline-length
+	return errors.New(`too many arguments
+
+Usage: tool [flags] <file>
+Flags: see -help`)
+}
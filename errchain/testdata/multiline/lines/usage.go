@@ -0,0 +1,23 @@
+package lines // want package:`PrefixNamespace\(lines\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+func Parse(args []string) error {
+	switch len(args) {
+	case 0:
+		return errors.New(`lines.Parse: no arguments
+
+Usage: tool [flags] <file>
+Flags: see -help`)
+	case 1:
+		return fmt.Errorf(`lines.Parse: unknown flag %q
+
+Usage: tool [flags] <file>`, args[0])
+	}
+	return errors.New( // want `Error message must point to the place where it had happened`
+		`too many arguments
+Usage: tool [flags] <file>`)
+}
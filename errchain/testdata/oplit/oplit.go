@@ -0,0 +1,43 @@
+package oplit // want package:`PrefixNamespace\(oplit\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Struct struct {
+	name string
+}
+
+func (s *Struct) Method(n int) error {
+	const op = "oplit.Struct.Method"
+
+	if n < 0 {
+		return fmt.Errorf("%s: negative %d", op, n)
+	}
+	if n == 0 {
+		return fmt.Errorf("oplit.Struct.Method: zero for %s", s.name) // want `Message repeats the value of const op, start it with op instead`
+	}
+	check := func() error {
+		return errors.New("oplit.Struct.Method: too big") // want `Message repeats the value of const op, start it with op instead`
+	}
+	return check()
+}
+
+func Close() error { // want Close:"PrefixedErrorFunc"
+	const op = "oplit.Close: "
+
+	if err := flush(); err != nil {
+		return fmt.Errorf(op+"flush: %w", err)
+	}
+	return errors.New("oplit.Close: already closed") // want `Message repeats the value of const op, start it with op instead`
+}
+
+// Open repeats the location without declaring op, which is fine.
+func Open() error { // want Open:"PrefixedErrorFunc"
+	return errors.New("oplit.Open: not implemented")
+}
+
+func flush() error { // want flush:"PrefixedErrorFunc"
+	return nil
+}
@@ -0,0 +1,35 @@
+package order // want package:`PrefixNamespace\(order\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Struct struct{}
+
+func (s *Struct) Method() error {
+	return errors.New("Method.Struct.order: failed") // want `components out of order, expected "order\.Struct\.Method: "`
+}
+
+func (s *Struct) Close() error {
+	return errors.New("order.Close.Struct: already closed") // want `components out of order, expected "order\.Struct\.Close: "`
+}
+
+func (s *Struct) Open(name string) error {
+	return fmt.Errorf("Struct.Open: %s not found", name) // want `package component missing, expected "order\.Struct\.Open: "`
+}
+
+func (s *Struct) Load() error {
+	return errors.New("(*Struct).Load: failed") // want `package component missing, expected "order\.\(\*Struct\)\.Load: "`
+}
+
+func Parse(s string) error {
+	if s == "" {
+		return errors.New("Parse.order: empty") // want `components out of order, expected "order\.Parse: "`
+	}
+	return errors.New("Parse: bad input") // want `package component missing, expected "order\.Parse: "`
+}
+
+func Read() error {
+	return errors.New("other.Read: failed") // want `package name mismatch, expected "order" from the package clause`
+}
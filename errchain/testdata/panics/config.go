@@ -0,0 +1,35 @@
+package panics // want package:`PrefixNamespace\(panics\)`
+
+import (
+	"os"
+	"strconv"
+
+	"example.com/must"
+)
+
+var port int
+
+func init() {
+	_, err := os.Stat("config.yaml")
+	must.OK(err, "panics: no config")
+	must.OK(err, "no config") // want `Panic message must point to the place where it had happened: Consider starting message with one of the following strings: "panics: "`
+}
+
+// Port doesn't return an error, but its panics end up in the logs as errors do.
+func Port(s string) int {
+	n, err := strconv.Atoi(s)
+	must.OK(err, "panics.Port: bad port")
+	must.OK(err, "bad port")                                  // want `Panic message must point to the place where it had happened: Consider starting message with one of the following strings: "panics: ", "panics.Port: "`
+	must.Truef(n > 0, "panics.Load: port %d out of range", n) // want `Panic message must point to the place where it had happened: neither func nor struct has been found. Consider starting message`
+	must.Truef(n < 65536, "panics.Port: port %d out of range", n)
+	return n
+}
+
+func parse(s string) int {
+	n, err := strconv.Atoi(s)
+	must.OK(err, "panics.parse: bad number")
+	must.OK(err, "bad number") // want `Panic message must point to the place where it had happened: Consider starting message`
+	return n
+}
+
+var _ = parse
@@ -0,0 +1,18 @@
+package lenient // want package:`PrefixNamespace\(lenient\)`
+
+import (
+	"fmt"
+	"os"
+)
+
+func Open(name string) (*os.File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err)
+	}
+	return f, nil
+}
+
+func Chdir(dir string) error {
+	return fmt.Errorf("%s", dir) // want `prefix must be static`
+}
@@ -0,0 +1,28 @@
+package strict // want package:`PrefixNamespace\(strict\)`
+
+import (
+	"fmt"
+	"os"
+)
+
+func Open(name string) (*os.File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w", err) // want `wrapping adds no location information, consider "strict\.Open: %w"`
+	}
+	return f, nil
+}
+
+func Remove(name string) error {
+	if err := os.Remove(name); err != nil {
+		return fmt.Errorf("%v", err) // want `wrapping adds no location information, consider "strict\.Remove: %v"`
+	}
+	return nil
+}
+
+func Stat(name string) error { // want Stat:"PrefixedErrorFunc"
+	if _, err := os.Stat(name); err != nil {
+		return fmt.Errorf("strict.Stat: %w", err)
+	}
+	return nil
+}
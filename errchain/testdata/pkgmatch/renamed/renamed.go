@@ -0,0 +1,10 @@
+package other // want package:`PrefixNamespace\(other\)`
+
+import "errors"
+
+func Get(key string) error {
+	if key == "" {
+		return errors.New("other.Get: empty key")
+	}
+	return errors.New("renamed.Get: not found") // want `Error message must point to the place where it had happened: package name mismatch, expected "other" from the package clause$`
+}
@@ -0,0 +1,26 @@
+package pointerform // want package:`PrefixNamespace\(pointerform\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Conn struct{}
+
+func (c *Conn) Close() error { // want Close:"PrefixedErrorFunc"
+	return errors.New("pointerform.Conn.Close: already closed") // want `Method has a pointer receiver, name it in the pointer form: pointerform\.\(\*Conn\)\.Close`
+}
+
+func (c *Conn) Read(n int) error { // want Read:"PrefixedErrorFunc"
+	return fmt.Errorf("pointerform.(*Conn).Read: short read of %d bytes", n)
+}
+
+func (c Conn) Addr() error { // want Addr:"PrefixedErrorFunc"
+	// the method has a value receiver
+	return errors.New("pointerform.Conn.Addr: not connected")
+}
+
+func (c *Conn) Reset() error { // want Reset:"PrefixedErrorFunc"
+	// the prefix doesn't name the receiver
+	return errors.New("pointerform.Reset: busy")
+}
@@ -0,0 +1,36 @@
+package promoted // want package:`PrefixNamespace\(promoted\)`
+
+import "errors"
+
+type conn struct {
+	closed bool
+}
+
+func (c *conn) Close() error {
+	if c.closed {
+		return errors.New("promoted.Server.Close: already closed")
+	}
+	if c == nil {
+		return errors.New("promoted.conn.Close: nil connection")
+	}
+	return errors.New("promoted.Client.Close: not implemented") // want `reciever not found`
+}
+
+func (c *conn) Flush() error {
+	return errors.New("flush failed") // want `Consider starting message with one of the following strings: "promoted: ", "promoted\.Server\.Flush: ", "promoted\.Server: ", "promoted\.conn\.Flush: ", "promoted\.\(\*conn\)\.Flush: ", "promoted\.conn: "$`
+}
+
+func (c *conn) Reset() error { // want Reset:"PrefixedErrorFunc"
+	return errors.New("promoted.conn.Reset: not supported")
+}
+
+type Server struct {
+	*conn
+}
+
+// Reset is declared by Server itself, so (*conn).Reset isn't promoted.
+func (s *Server) Reset() error { // want Reset:"PrefixedErrorFunc"
+	return errors.New("promoted.Server.Reset: not supported")
+}
+
+type Client struct{}
@@ -0,0 +1,27 @@
+package postgres // want package:`PrefixNamespace\(postgres\)`
+
+import "errors"
+
+type Conn struct{}
+
+func (c *Conn) Query(q string) error { // want Query:"PrefixedErrorFunc"
+	switch q {
+	case "":
+		return errors.New("storage/postgres: empty query")
+	case "select":
+		return errors.New("storage.postgres.Conn.Query: nothing selected")
+	case "update":
+		return errors.New("qualified.storage.postgres.Conn: nothing updated")
+	}
+	return errors.New("postgres.Conn.Query: unknown query")
+}
+
+func Open(dsn string) error {
+	switch dsn {
+	case "":
+		return errors.New("store/postgres.Open: empty dsn") // want `package path mismatch, expected "storage/postgres"$`
+	case "local":
+		return errors.New("store.postgres.Open: local dsn") // want `package path mismatch, expected "storage/postgres"$`
+	}
+	return errors.New("storage.postgres.Dial: unreachable") // want `neither func nor struct has been found`
+}
@@ -0,0 +1,15 @@
+package util // want package:`PrefixNamespace\(storage/util\)`
+
+import "errors"
+
+func Parse(s string) error {
+	switch s {
+	case "":
+		return errors.New("util.Parse: empty input") // want `package name is ambiguous, qualify it with parent path segments, expected "storage/util"$`
+	case "-":
+		return errors.New("storage.util.Parse: dash")
+	case "+":
+		return errors.New("qualified/storage/util.Parse: plus")
+	}
+	return errors.New("bad input") // want `Consider starting message with one of the following strings: "storage/util: ", "storage/util.Parse: "$`
+}
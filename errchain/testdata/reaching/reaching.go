@@ -0,0 +1,82 @@
+package reaching // want package:`PrefixNamespace\(reaching\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+var deleted = map[string]bool{}
+
+func Get(key string) error { // want Get:"PrefixedErrorFunc"
+	msg := "reaching.Get: not found"
+	if deleted[key] {
+		msg = "reaching.Get: deleted"
+	}
+	return errors.New(msg)
+}
+
+func Put(key string) error {
+	msg := "reaching.Put: read only"
+	if deleted[key] {
+		msg = "deleted"
+	}
+	return errors.New(msg) // want `"reaching: ", "reaching.Put: " \(the message is set at line 21\)`
+}
+
+func Delete(key string) error {
+	var format string
+	switch {
+	case key == "":
+		format = "reaching.Delete: empty key%s"
+	case deleted[key]:
+		format = "reaching.Delete: %q is already deleted"
+	default:
+		return nil
+	}
+	return fmt.Errorf(format, key)
+}
+
+func Move(from, to string) error { // want Move:"PrefixedErrorFunc"
+	msg := "move failed"
+	msg = "reaching.Move: " + "failed"
+	return errors.New(msg)
+}
+
+func Copy(keys []string) error {
+	msg := "reaching.Copy: no keys"
+	for _, key := range keys {
+		if deleted[key] {
+			return errors.New(msg) // want `"reaching: ", "reaching.Copy: " \(the message is set at line 52\)`
+		}
+		msg = "copy: " + key
+		msg = "bad key"
+	}
+	return errors.New(msg) // want `"reaching: ", "reaching.Copy: " \(the message is set at line 52\)`
+}
+
+// Messages changed out of the control flow of the function aren't checked.
+
+func Load(key string) error {
+	msg := "reaching.Load: failed"
+	update := func() { msg = "failed" }
+	update()
+	return errors.New(msg)
+}
+
+func Store(key string) error {
+	msg := "reaching.Store: failed"
+	set(&msg)
+	return errors.New(msg)
+}
+
+func Find(key string) error {
+	msg := "reaching.Find: not found"
+	if deleted[key] {
+		msg = key
+	}
+	return errors.New(msg)
+}
+
+func set(msg *string) {
+	*msg = "failed"
+}
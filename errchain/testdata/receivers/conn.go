@@ -0,0 +1,17 @@
+package receivers // want package:`PrefixNamespace\(receivers\)`
+
+import "errors"
+
+// Conn has methods with value and pointer receivers in different files.
+type Conn struct{}
+
+func (c *Conn) Close() error { // want Close:"PrefixedErrorFunc"
+	return errors.New("receivers.(*Conn).Close: already closed")
+}
+
+// Point has methods with value receivers only.
+type Point struct{}
+
+func (p Point) Validate() error {
+	return errors.New("receivers.(*Point).Validate: out of range") // want `Error message must point to the place where it had happened: reciever has no pointer`
+}
@@ -0,0 +1,15 @@
+package receivers
+
+import "errors"
+
+func (c Conn) Read() error {
+	return errors.New("closed") // want `Consider starting message with one of the following strings: "receivers: ", "receivers\.Conn\.Read: ", "receivers\.\(\*Conn\)\.Read: "`
+}
+
+func (c Conn) Write() error { // want Write:"PrefixedErrorFunc"
+	return errors.New("receivers.(*Conn).Write: closed")
+}
+
+func (p Point) String() (string, error) {
+	return "", errors.New("failed") // want `Consider starting message with one of the following strings: "receivers: ", "receivers\.Point\.String: ", "receivers\.Point: "$`
+}
@@ -0,0 +1,33 @@
+package recvalias // want package:`PrefixNamespace\(recvalias\)`
+
+import "errors"
+
+type serviceImpl struct{}
+
+// svc is the short name of serviceImpl the methods are declared with.
+type svc = serviceImpl
+
+func (s *svc) Start() error { // want Start:"PrefixedErrorFunc"
+	return errors.New("recvalias.(*svc).Start: already started")
+}
+
+func (s *svc) Stop() error { // want Stop:"PrefixedErrorFunc"
+	return errors.New("recvalias.(*serviceImpl).Stop: not started")
+}
+
+func (s *svc) Reload() error {
+	return errors.New("reload failed") // want `Consider starting message with one of the following strings: "recvalias: ", "recvalias\.svc\.Reload: ", "recvalias\.\(\*svc\)\.Reload: ", "recvalias\.svc: ", "recvalias\.serviceImpl\.Reload: ", "recvalias\.\(\*serviceImpl\)\.Reload: ", "recvalias\.serviceImpl: "`
+}
+
+func (s *svc) Close() error {
+	return errors.New("recvalias.(*other).Close: closed") // want `Error message must point to the place where it had happened`
+}
+
+type impl = serviceImpl
+
+// chained is resolved through impl.
+type chained = impl
+
+func (c chained) Ping() error { // want Ping:"PrefixedErrorFunc"
+	return errors.New("recvalias.serviceImpl.Ping: unreachable")
+}
@@ -0,0 +1,45 @@
+package registry // want package:`PrefixNamespace\(registry\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Handler func(name string) error
+
+func Register(name string, h Handler) {}
+
+type Registry struct{}
+
+func (r *Registry) Add(name string, h Handler) {}
+
+func init() {
+	Register("init", func(name string) error {
+		return errors.New("registry: init handler failed")
+	})
+}
+
+func setup(r *Registry) {
+	Register("a", func(name string) error {
+		return fmt.Errorf("handler %s failed", name) // want `Error message must point to the place where it had happened: Consider starting message with one of the following strings: "registry: "`
+	})
+	r.Add("b", func(name string) error {
+		if name == "" {
+			return errors.New("registry: empty name")
+		}
+		return errors.New("registry.setup: failed") // want `Error message must point to the place where it had happened: neither func nor struct has been found`
+	})
+	notRegistered(func() error {
+		return errors.New("unexported function is not checked")
+	})
+}
+
+func notRegistered(f func() error) {}
+
+func Setup() error { // want Setup:"PrefixedErrorFunc"
+	Register("c", func(name string) error {
+		return errors.New("registry.Setup: handler failed") // want `Error message must point to the place where it had happened: neither func nor struct has been found`
+	})
+	setup(&Registry{})
+	return errors.New("registry.Setup: failed")
+}
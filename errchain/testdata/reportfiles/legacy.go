@@ -0,0 +1,11 @@
+package reportfiles // want package:`PrefixNamespace\(reportfiles\)`
+
+import "errors"
+
+// Close is outside -report-files, so its message isn't reported.
+func Close(name string) error {
+	if name == "" {
+		return errors.New("no name")
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+package reportfiles
+
+import "errors"
+
+func Open(name string) error {
+	if name == "" {
+		return errors.New("no name") // want `Error message must point to the place where it had happened`
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package requote // want package:`PrefixNamespace\(requote\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errClosed = errors.New("requote: closed")
+
+type Reader struct {
+	name    string
+	lastErr error
+}
+
+func (r *Reader) Load(n int) error { // want Load:"PrefixedErrorFunc"
+	err := r.read(n)
+	switch {
+	case n < 0:
+		return fmt.Errorf("requote.Reader.Load: %v: %w", err, err) // want `Message formats the error wrapped by %w once more with %v, its text would appear in the chain twice`
+	case n == 0:
+		return fmt.Errorf("requote.Reader.Load: %s (%w)", err.Error(), err) // want `Message formats the error wrapped by %w once more with %s`
+	case n == 1:
+		return fmt.Errorf("requote.Reader.Load: %w [%v]", r.lastErr, r.lastErr) // want `Message formats the error wrapped by %w once more with %v`
+	case n == 2:
+		return fmt.Errorf("requote.Reader.Load: read %s: %q: %w", r.name, err, err) // want `Message formats the error wrapped by %w once more with %q`
+	case n == 3:
+		// another verb lies between them
+		return fmt.Errorf("requote.Reader.Load: %v: %s: %w", err, r.name, err) // want `Message formats the error wrapped by %w once more with %v`
+	case n == 4:
+		return fmt.Errorf("requote.Reader.Load: %v: %w", errClosed, err)
+	}
+	return fmt.Errorf("requote.Reader.Load: read %s: %w", r.name, err)
+}
+
+func (r *Reader) read(n int) error {
+	return r.lastErr
+}
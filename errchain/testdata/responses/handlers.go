@@ -0,0 +1,47 @@
+package responses // want package:`PrefixNamespace\(responses\)`
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var errDenied = errors.New("responses: denied")
+
+func Auth(token string, next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Authorization") {
+		case "":
+			http.Error(w, "missing token", http.StatusUnauthorized) // want `Error response must point to the place where it had happened: Consider starting message with one of the following strings: "responses: ", "responses.Auth: "`
+			return
+		case "expired":
+			http.Error(w, "responses.Auth: token expired", http.StatusUnauthorized)
+			return
+		case "denied":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprintf(w, "access denied for %s", r.URL.Path) // want `Error response must point to the place where it had happened: Consider starting message`
+			return
+		case "other":
+			fmt.Fprintf(w, "responses.Login: %v", errDenied) // want `Error response must point to the place where it had happened: neither func nor struct has been found. Consider starting message`
+			return
+		}
+		fmt.Fprintf(w, "welcome to %s", r.URL.Path)
+		next.ServeHTTP(w, r)
+	}
+}
+
+func Health() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, "responses.Health: %s is down", "db")
+	}
+}
+
+// unexported handlers aren't checked
+func fallback() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+var _ = fallback
@@ -0,0 +1,21 @@
+package rules // want package:`PrefixNamespace\(rules\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+func Get(key string) (error, bool) { // want `Error should be the last result`
+	if key == "" {
+		return errors.New("empty key"), false
+	}
+	return fmt.Errorf("%s: not found", key), false // want `prefix must be static`
+}
+
+func Put(key string) error {
+	return put(key)
+}
+
+func put(key string) error {
+	return errors.New("not implemented")
+}
@@ -0,0 +1,28 @@
+package selflocating // want package:`PrefixNamespace\(selflocating\)`
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+// locate adds file:line of the caller to the message.
+func locate(msg string) error {
+	_, file, line, _ := runtime.Caller(1)
+	return fmt.Errorf("%s:%d: %s", file, line, msg)
+}
+
+func other() error {
+	return errors.New("other")
+}
+
+func Get(key string) error { // want Get:"PrefixedErrorFunc"
+	if key == "" {
+		return locate("empty key")
+	}
+	return fmt.Errorf("%w: key %s", locate("bad key"), key)
+}
+
+func Put(key string) error {
+	return fmt.Errorf("%w: key %s", other(), key) // want `Error message must point to the place where it had happened`
+}
@@ -0,0 +1,40 @@
+package separator // want package:`PrefixNamespace\(separator\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Conn struct{}
+
+func (c *Conn) Query(q string) error { // want Query:"PrefixedErrorFunc"
+	if q == "" {
+		return errors.New("separator.Conn.Query - empty query")
+	}
+	return fmt.Errorf("separator.Conn - bad query %q", q)
+}
+
+func Open(dsn string) error {
+	if dsn == "" {
+		return errors.New("separator.Open: empty dsn") // want `Error message must point to the place where it had happened: Consider starting message with one of the following strings: "separator - ", "separator.Open - "$`
+	}
+	return errors.New("separator.Dial - unreachable") // want `neither func nor struct has been found`
+}
+
+func Close() error {
+	return errors.New("already closed") // want `Consider starting message with one of the following strings: "separator - ", "separator.Close - "`
+}
+
+func Ping(n int) error {
+	if n < 0 {
+		return fmt.Errorf("%d - negative attempts", n) // want `prefix must be static, move the value after the prefix: "separator.Ping - %d - negative attempts"`
+	}
+	return parse(n) // want `error of parse is returned as is, wrap it: fmt.Errorf\("separator.Ping - %w", err\)`
+}
+
+func parse(n int) error {
+	if n == 0 {
+		return errors.New("zero")
+	}
+	return nil
+}
@@ -0,0 +1,3 @@
+package main // want `main-like package: .*skipped/cmd`
+
+func main() {}
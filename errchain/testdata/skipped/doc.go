@@ -0,0 +1 @@
+package skipped // want package:`PrefixNamespace\(skipped\)`
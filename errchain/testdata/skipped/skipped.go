@@ -0,0 +1,21 @@
+package skipped
+
+import (
+	"errors"
+	"fmt"
+)
+
+var messages = map[int]string{}
+
+func Get(id int) error {
+	if msg, ok := messages[id]; ok {
+		return errors.New(msg) // want `non-constant message: errors.New`
+	}
+	return fmt.Errorf("skipped.Get: %d not found", id)
+}
+
+func get(id int) error { // want `unexported function: get`
+	return errors.New("not checked")
+}
+
+func helper() {}
@@ -0,0 +1 @@
+package skipped // want `test file: skipped_test.go`
@@ -0,0 +1,14 @@
+package store
+
+import "fmt"
+
+func Get(key string) error {
+	if err := load(key); err != nil {
+		return fmt.Errorf("store.Get: %w", err)
+	}
+	return nil
+}
+
+func load(key string) error {
+	return fmt.Errorf("store.load: %s not found", key)
+}
@@ -0,0 +1,28 @@
+// Package errs is a stub of generics-based error helpers.
+package errs
+
+import "fmt"
+
+// Wrap annotates an error with an operation and a value it failed on.
+func Wrap[T any](op string, v T, err error) error {
+	return fmt.Errorf("%s %v: %w", op, v, err)
+}
+
+// New returns an error with a message of any string type.
+func New[M ~string](msg M) error {
+	return fmt.Errorf("%s", string(msg))
+}
+
+// Annotate adds a message to an error keeping its type.
+func Annotate[E error](err E, msg string) error {
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// Box formats errors about its value.
+type Box[T any] struct {
+	V T
+}
+
+func (b Box[T]) Errorf(format string, args ...interface{}) error {
+	return fmt.Errorf(format, args...)
+}
@@ -0,0 +1,18 @@
+// Package must is a stub of Must-style helpers panicking on errors.
+package must
+
+import "fmt"
+
+// OK panics with the message if the error isn't nil.
+func OK(err error, msg string) {
+	if err != nil {
+		panic(fmt.Sprintf("%s: %v", msg, err))
+	}
+}
+
+// Truef panics with the formatted message if the condition is false.
+func Truef(cond bool, format string, args ...interface{}) {
+	if !cond {
+		panic(fmt.Sprintf(format, args...))
+	}
+}
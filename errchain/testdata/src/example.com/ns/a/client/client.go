@@ -0,0 +1,7 @@
+package client // want package:`PrefixNamespace\(client\)` `Ambiguous prefix namespace "client": it is also used by b/client, qualify the prefixes with the parent path, e.g. "a/client: ", and add the package to -qualified`
+
+import "errors"
+
+func Dial(addr string) error { // want Dial:"PrefixedErrorFunc"
+	return errors.New("client.Dial: not implemented")
+}
@@ -0,0 +1,14 @@
+package client // want package:`PrefixNamespace\(client\)` `Ambiguous prefix namespace "client": it is also used by a/client, qualify the prefixes with the parent path, e.g. "b/client: ", and add the package to -qualified`
+
+import (
+	"errors"
+
+	dial "example.com/ns/a/client"
+)
+
+func Connect(addr string) error {
+	if err := dial.Dial(addr); err != nil {
+		return err
+	}
+	return errors.New("client.Connect: not implemented")
+}
@@ -0,0 +1,7 @@
+package client // want package:`PrefixNamespace\(cclient\)`
+
+import "errors"
+
+func Get(key string) error { // want Get:"PrefixedErrorFunc"
+	return errors.New("cclient.Get: not implemented")
+}
@@ -0,0 +1,2 @@
+//errchain:prefix cclient
+package client
@@ -0,0 +1,3 @@
+module example.com/ns
+
+go 1.19
@@ -0,0 +1,7 @@
+package app // want package:`PrefixNamespace\(app\)`
+
+import "errors"
+
+func Run() error {
+	return errors.New("run failed") // want `Consider starting message with one of the following strings: "app: ", "app.Run: "`
+}
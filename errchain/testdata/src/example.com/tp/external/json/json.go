@@ -0,0 +1,7 @@
+package json // want package:`PrefixNamespace\(json\)`
+
+import "errors"
+
+func Decode() error {
+	return errors.New("unexpected end") // want `Consider starting message with one of the following strings: "json: ", "json.Decode: "`
+}
@@ -0,0 +1,7 @@
+package yaml // want `third-party package: example.com/tp/third_party/yaml`
+
+import "errors"
+
+func Unmarshal() error {
+	return errors.New("unexpected token")
+}
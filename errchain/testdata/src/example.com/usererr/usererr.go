@@ -0,0 +1,17 @@
+// Package usererr is a stub of errors shown to end users as is.
+package usererr
+
+import "fmt"
+
+// Error is an error with a message for end users.
+type Error struct{ msg string }
+
+func (e *Error) Error() string { return e.msg }
+
+// New returns an error with the message.
+func New(msg string) error { return &Error{msg: msg} }
+
+// Newf returns an error with the formatted message.
+func Newf(code int, format string, args ...interface{}) error {
+	return &Error{msg: fmt.Sprintf(format, args...)}
+}
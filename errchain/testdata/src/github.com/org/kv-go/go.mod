@@ -0,0 +1,3 @@
+module go.example.com/kv
+
+go 1.19
@@ -0,0 +1,10 @@
+package store // want package:`PrefixNamespace\(store\)`
+
+import "errors"
+
+func Get(key string) error {
+	if key == "" {
+		return errors.New("kv/store.Get: empty key")
+	}
+	return errors.New("kv-go/store.Get: not found") // want `Error message must point to the place where it had happened: package name mismatch`
+}
@@ -0,0 +1,29 @@
+// Package errors is a stub of github.com/pkg/errors.
+package errors
+
+import "fmt"
+
+type withMessage struct {
+	cause error
+	msg   string
+}
+
+func (w *withMessage) Error() string { return w.msg + ": " + w.cause.Error() }
+
+func (w *withMessage) Unwrap() error { return w.cause }
+
+func New(message string) error {
+	return fmt.Errorf("%s", message)
+}
+
+func Errorf(format string, args ...interface{}) error {
+	return fmt.Errorf(format, args...)
+}
+
+func Wrap(err error, message string) error {
+	return &withMessage{cause: err, msg: message}
+}
+
+func Wrapf(err error, format string, args ...interface{}) error {
+	return &withMessage{cause: err, msg: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,6 @@
+// Package multierr is a stub of go.uber.org/multierr for tests.
+package multierr
+
+func Append(left, right error) error { return nil }
+
+func Combine(errors ...error) error { return nil }
@@ -0,0 +1,12 @@
+// Package xerrors is a stub of golang.org/x/xerrors.
+package xerrors
+
+import "fmt"
+
+func New(text string) error {
+	return fmt.Errorf("%s", text)
+}
+
+func Errorf(format string, a ...interface{}) error {
+	return fmt.Errorf(format, a...)
+}
@@ -0,0 +1,21 @@
+package balanced // want package:`PrefixNamespace\(balanced\)`
+
+import "errors"
+
+// The styles are used equally often, so neither of them is dominant.
+
+func Open() error { // want Open:"PrefixedErrorFunc"
+	return errors.New("balanced.Open: not supported")
+}
+
+func Close() error { // want Close:"PrefixedErrorFunc"
+	return errors.New("balanced.Close: not supported")
+}
+
+func Remove() error { // want Remove:"PrefixedErrorFunc"
+	return errors.New("balanced: not supported")
+}
+
+func Rename() error { // want Rename:"PrefixedErrorFunc"
+	return errors.New("balanced: not supported yet")
+}
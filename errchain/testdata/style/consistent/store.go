@@ -0,0 +1,41 @@
+package consistent // want package:`PrefixNamespace\(consistent\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DB is a store most of whose messages name the receiver and the method.
+type DB struct{}
+
+func (db *DB) Get(key string) error { // want Get:"PrefixedErrorFunc"
+	if key == "" {
+		return errors.New("consistent.DB.Get: empty key")
+	}
+	return fmt.Errorf("consistent.DB.Get: %s not found", key)
+}
+
+func (db *DB) Put(key string) error { // want Put:"PrefixedErrorFunc"
+	if key == "" {
+		return errors.New("consistent.DB.Put: empty key")
+	}
+	return errors.New("consistent: read only") // want `Prefix "consistent: " names the package only, while 7 of 9 messages of the package name the function, consider "consistent.DB.Put: "`
+}
+
+func (db *DB) Close() error { // want Close:"PrefixedErrorFunc"
+	return errors.New("consistent.DB: already closed") // want `Prefix "consistent.DB: " names the receiver only, while 7 of 9 messages of the package name the function, consider "consistent.DB.Close: "`
+}
+
+func Open(name string) (*DB, error) { // want Open:"PrefixedErrorFunc"
+	switch name {
+	case "":
+		return nil, errors.New("consistent.Open: empty name")
+	case "memory":
+		return nil, errors.New("consistent.Open: not supported")
+	}
+	return nil, fmt.Errorf("consistent.Open: unknown %s", name)
+}
+
+func Remove(name string) error { // want Remove:"PrefixedErrorFunc"
+	return errors.New("consistent.Remove: not supported")
+}
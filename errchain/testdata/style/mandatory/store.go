@@ -0,0 +1,23 @@
+package mandatory // want package:`PrefixNamespace\(mandatory\)`
+
+import "errors"
+
+func Open() error { // want Open:"PrefixedErrorFunc"
+	return errors.New("mandatory.Open: not supported")
+}
+
+func Close() error { // want Close:"PrefixedErrorFunc"
+	return errors.New("mandatory.Close: not supported")
+}
+
+func Remove() error { // want Remove:"PrefixedErrorFunc"
+	return errors.New("mandatory.Remove: not supported")
+}
+
+func Rename() error { // want Rename:"PrefixedErrorFunc"
+	return errors.New("mandatory: not supported") // want `Prefix "mandatory: " names the package only, while 3 of 5 messages of the package name the function, consider "mandatory.Rename: "`
+}
+
+func Move() error { // want Move:"PrefixedErrorFunc"
+	return errors.New("mandatory: not supported yet") // want `Prefix "mandatory: " names the package only, while 3 of 5 messages of the package name the function, consider "mandatory.Move: "`
+}
@@ -0,0 +1,55 @@
+package tables // want package:`PrefixNamespace\(tables\)`
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// not checked with -package-level=false
+var ErrUnknownField = errors.New("unknown field")
+
+var validators = map[string]func(v string) error{
+	"email": func(v string) error {
+		if !strings.Contains(v, "@") {
+			return errors.New("invalid email") // want `Consider starting message with one of the following strings: "tables: ", "tables\.validators: "`
+		}
+		return nil
+	},
+	"name": func(v string) error {
+		if v == "" {
+			return fmt.Errorf("tables.validators: empty name")
+		}
+		return nil
+	},
+}
+
+type rule struct {
+	field string
+	check func(v string) error
+}
+
+var rules = []*rule{
+	&rule{field: "age", check: func(v string) error {
+		return fmt.Errorf("tables: bad age %q", v)
+	}},
+	{field: "zip", check: func(v string) error {
+		return errors.New("bad zip") // want `Consider starting message with one of the following strings: "tables: ", "tables\.rules: "`
+	}},
+}
+
+var lookup = func(field string) error {
+	checks := map[string]func() error{
+		"id": func() error { return errors.New("bad id") },
+	}
+	return checks[field]()
+}
+
+// Validate checks a value of a field.
+func Validate(field, v string) error {
+	check, ok := validators[field]
+	if !ok {
+		return fmt.Errorf("tables.Validate: %w: %s", ErrUnknownField, field)
+	}
+	return check(v)
+}
@@ -0,0 +1,39 @@
+package typedirective // want package:`PrefixNamespace\(typedirective\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+//errchain:prefix Factory
+type DefaultHTTPSRoundTripperFactoryImpl struct{}
+
+func (f *DefaultHTTPSRoundTripperFactoryImpl) New(name string) error { // want New:"PrefixedErrorFunc"
+	return fmt.Errorf("typedirective.Factory.New: %s: unknown", name)
+}
+
+func (f *DefaultHTTPSRoundTripperFactoryImpl) Close() error { // want Close:"PrefixedErrorFunc"
+	return errors.New("typedirective.(*Factory).Close: already closed")
+}
+
+func (f *DefaultHTTPSRoundTripperFactoryImpl) Reset() error {
+	return errors.New("typedirective.DefaultHTTPSRoundTripperFactoryImpl.Reset: busy") // want `Error message must point to the place where it had happened: reciever not found`
+}
+
+type (
+	// Client calls the legacy API.
+	//errchain:exempt
+	Client struct{}
+
+	// Pool is a pool of clients.
+	//errchain:prefix pool-of-clients // want `Malformed errchain:prefix directive of type Pool: the receiver name must be an identifier`
+	Pool struct{}
+)
+
+func (c Client) Do() error {
+	return errors.New("legacy failure")
+}
+
+func (p *Pool) Get() error { // want Get:"PrefixedErrorFunc"
+	return errors.New("typedirective.Pool.Get: empty")
+}
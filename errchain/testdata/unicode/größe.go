@@ -0,0 +1,40 @@
+package größe // want package:`PrefixNamespace\(größe\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Straße struct{}
+
+func (s *Straße) Länge() error { // want Länge:"PrefixedErrorFunc"
+	return errors.New("größe.Straße.Länge: unknown")
+}
+
+func (s *Straße) Breite() error {
+	return errors.New("größe.Straße.Lange: unknown") // want `method not found, did you mean größe\.Straße\.Länge\?`
+}
+
+func Größ() error {
+	return errors.New("größe.Grüs: unknown") // want `neither func nor struct has been found$`
+}
+
+func Über(name string) error {
+	return fmt.Errorf("größe.Über\u200b: %s not found", name) // want `prefix contains an invisible character U\+200B. Consider starting message with one of the following strings: "größe: ", "größe\.Über: "`
+}
+
+func Café() error {
+	return errors.New("größe.Cafe\u0301: closed") // want `prefix contains an invisible character U\+0301. Consider starting message with one of the following strings: "größe: ", "größe\.Café: "`
+}
+
+func 名前() error { // want 名前:"PrefixedErrorFunc"
+	return errors.New("größe.名前: unknown")
+}
+
+func Öffnen() error {
+	return errors.New("grösse.Öffnen: unknown") // want `package name mismatch, expected "größe" from the package clause`
+}
+
+func Schließen() error {
+	return errors.New("closed") // want `Consider starting message with one of the following strings: "größe: ", "größe\.Schließen: "`
+}
@@ -0,0 +1,42 @@
+package usermsg // want package:`PrefixNamespace\(usermsg\)`
+
+import (
+	"fmt"
+	"strings"
+
+	"example.com/usererr"
+)
+
+var errTaken = usererr.New("usermsg: the name is taken") // want `User-facing message must not contain the internal location prefix "usermsg: "`
+
+const internal = "usermsg.Register: email is invalid"
+
+func Register(name, email string) error {
+	if !strings.Contains(email, "@") {
+		return usererr.New("usermsg.Register: email is invalid") // want `User-facing message must not contain the internal location prefix "usermsg\.Register: "`
+	}
+	if email == "root@localhost" {
+		return usererr.New(internal) // want `User-facing message must not contain the internal location prefix "usermsg\.Register: "`
+	}
+	if name == "" {
+		return usererr.Newf(400, "store.DB.Insert: %q is reserved", name) // want `User-facing message must not contain the internal location prefix "store\.DB\.Insert: "`
+	}
+	if err := save(name); err != nil {
+		return usererr.Newf(500, "Sorry, try again later: %v", err) // want `User-facing message formats the error err, its chain of internal prefixes would be shown to users`
+	}
+	if name == "admin" {
+		return errTaken
+	}
+	return usererr.Newf(200, "Welcome, %s! Note: check your inbox", name)
+}
+
+func validate(email string) error {
+	if email == "" {
+		return usererr.New(`usermsg.validate: empty email`) // want `User-facing message must not contain the internal location prefix "usermsg\.validate: "`
+	}
+	return usererr.New("Error: email is invalid")
+}
+
+func save(name string) error { // want save:"PrefixedErrorFunc"
+	return fmt.Errorf("usermsg.save: %s", name)
+}
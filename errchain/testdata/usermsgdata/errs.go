@@ -0,0 +1,9 @@
+package usermsgdata // want package:`PrefixNamespace\(usermsgdata\)`
+
+import "example.com/usererr"
+
+// The package has no functions, so only its declarations are checked.
+
+var ErrEmail = usererr.New("usermsgdata.Register: email is invalid") // want `User-facing message must not contain the internal location prefix "usermsgdata\.Register: "`
+
+var ErrTaken = usererr.New("The name is taken")
@@ -0,0 +1,15 @@
+package v1 // want package:`PrefixNamespace\(v1\)`
+
+import "errors"
+
+func Get(id int) error {
+	switch id {
+	case 0:
+		return errors.New("v1.Get: zero id")
+	case 1:
+		return errors.New("userv1.Get: reserved id")
+	case 2:
+		return errors.New("user.Get: unknown id") // want `Error message must point to the place where it had happened: package name mismatch, expected "v1" from the package clause or "userv1" from the import path`
+	}
+	return errors.New("not found") // want `Error message must point to the place where it had happened: Consider starting message with one of the following strings: "v1: ", "v1.Get: ", "userv1: ", "userv1.Get: "`
+}
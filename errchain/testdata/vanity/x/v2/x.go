@@ -0,0 +1,13 @@
+package x // want package:`PrefixNamespace\(x\)`
+
+import "errors"
+
+func Get(key string) error {
+	switch key {
+	case "":
+		return errors.New("x.Get: empty key")
+	case "a":
+		return errors.New("x/v2.Get: path prefix")
+	}
+	return errors.New("xx.Get: not found") // want `Error message must point to the place where it had happened: package name mismatch`
+}
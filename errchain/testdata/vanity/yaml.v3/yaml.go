@@ -0,0 +1,10 @@
+package yaml // want package:`PrefixNamespace\(yaml\)`
+
+import "errors"
+
+func Decode(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("yaml.Decode: empty document")
+	}
+	return errors.New("v3.Decode: bad document") // want `Error message must point to the place where it had happened: package name mismatch`
+}
@@ -0,0 +1,32 @@
+package verbs // want package:`PrefixNamespace\(verbs\)`
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var errNotFound = errors.New("verbs: not found")
+
+type Key string
+
+func (k Key) String() string { return string(k) }
+
+func Get(name string, id int, timeout time.Duration) error {
+	switch {
+	case id < 0:
+		return fmt.Errorf("verbs.Get: %d: not found", name) // want `Format verb %d does not match argument type string`
+	case id == 0:
+		return fmt.Errorf("%s: not found") // want `Format verbs do not match arguments: 1 verbs for 0 arguments`
+	case id == 1:
+		return fmt.Errorf("verbs.Get: %w", name) // want `Format verb %w does not match argument type string`
+	case id == 2:
+		return fmt.Errorf("verbs.Get: %s", name, id) // want `Format verbs do not match arguments: 1 verbs for 2 arguments`
+	case id == 3:
+		return fmt.Errorf("verbs.Get: %t", id) // want `Format verb %t does not match argument type int`
+	}
+	if timeout > time.Second {
+		return fmt.Errorf("verbs.Get: %s: %q: %x: %v", timeout, id, name, Key(name))
+	}
+	return fmt.Errorf("verbs.Get: %s: %-8.2f: %w", Key(name), float64(id), errNotFound)
+}
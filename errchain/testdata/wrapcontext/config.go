@@ -0,0 +1,23 @@
+package wrapcontext // want package:`PrefixNamespace\(wrapcontext\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errMissing = errors.New("wrapcontext: missing")
+
+func Load(path string) error { // want Load:"PrefixedErrorFunc"
+	switch path {
+	case "":
+		return fmt.Errorf("wrapcontext.Load: %w", errMissing) // want `Wrapping adds 0 of 1 required context words after the prefix, describe what failed, e.g. "wrapcontext.Load: load config: %w"`
+	case "-":
+		return fmt.Errorf("wrapcontext.Load: %s: %w", path, errMissing) // want `Wrapping adds 0 of 1 required context words`
+	case "/":
+		return fmt.Errorf("wrapcontext.Load: read %s: %w", path, errMissing)
+	case ".":
+		// no error is wrapped
+		return fmt.Errorf("wrapcontext.Load: %s", path)
+	}
+	return fmt.Errorf("wrapcontext.Load: parse config: %w", errMissing)
+}
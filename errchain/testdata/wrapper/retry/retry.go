@@ -0,0 +1,37 @@
+package retry // want package:`PrefixNamespace\(retry\)`
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errLimit = errors.New("retry: limit exceeded")
+
+func Do(attempts int, f func() error) error { // want Do:"PrefixedErrorFunc"
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = f(); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("retry.Do: %w", giveUp(attempts, err))
+}
+
+func giveUp(attempts int, err error) error {
+	if attempts == 0 {
+		return fmt.Errorf("retry: %w", errLimit)
+	}
+	if err == nil {
+		return fmt.Errorf("no attempts made") // no %w, nothing is wrapped
+	}
+	return fmt.Errorf("after %d attempts: %w", attempts, err) // want `Wrapped error must get the prefix of the wrapper package .*wrapper/retry: start the message with "retry: "`
+}
+
+func Backoff(f func() error) func() error {
+	return func() error {
+		if err := f(); err != nil {
+			return fmt.Errorf("backoff: %w", err) // want `Wrapped error must get the prefix of the wrapper package .*wrapper/retry: start the message with "retry: "`
+		}
+		return nil
+	}
+}
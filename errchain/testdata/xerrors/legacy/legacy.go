@@ -0,0 +1,30 @@
+package legacy // want package:`PrefixNamespace\(legacy\)`
+
+import (
+	"os"
+
+	"golang.org/x/xerrors"
+)
+
+const op = "legacy.Open"
+
+func Open(name string) (*os.File, error) { // want Open:"PrefixedErrorFunc"
+	if name == "" {
+		return nil, xerrors.New("legacy.Open: empty name")
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, xerrors.Errorf("%s: %w", op, err)
+	}
+	return f, nil
+}
+
+func Remove(name string) error {
+	if name == "" {
+		return xerrors.New("empty name") // want `Consider starting message with one of the following strings: "legacy: ", "legacy\.Remove: "`
+	}
+	if err := os.Remove(name); err != nil {
+		return xerrors.Errorf("remove %s: %v", name, err) // want `package name mismatch, expected "legacy"`
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package retry // want package:`PrefixNamespace\(retry\)`
+
+import "golang.org/x/xerrors"
+
+func Do(attempts int, f func() error) error { // want Do:"PrefixedErrorFunc"
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = f(); err == nil {
+			return nil
+		}
+	}
+	return xerrors.Errorf("retry: %d attempts: %w", attempts, err)
+}
+
+func backoff(attempt int, err error) error {
+	if attempt > 10 {
+		return xerrors.Errorf("gave up after %d attempts: %s", attempt, err) // want `Wrapped error must get the prefix of the wrapper package`
+	}
+	return xerrors.Errorf("attempt %d failed: %v", attempt, err) // want `Wrapped error must get the prefix of the wrapper package`
+}
+
+func note(attempt int, err error) error {
+	return xerrors.Errorf("attempt %d: %v was retried", attempt, err)
+}
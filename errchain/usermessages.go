@@ -0,0 +1,123 @@
+package errchain
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"github.com/iimos/go-check-err-chains/internal/fmtverb"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+var ruleUserMessage = registerRule(rule{
+	code:             "user-message",
+	doc:              "messages of user-facing errors set by -user-constructors don't leak location prefixes",
+	enabledByDefault: func(c *configuration) bool { return len(c.userConstructors) > 0 },
+	flags:            []string{"user-constructors"},
+	example: func(fn, _ string) (string, string) {
+		return `usererr.New("` + fn + `email is invalid")`, `usererr.New("email is invalid")`
+	},
+})
+
+// handleUserMessages checks the calls of the constructors of user-facing errors set by config.userConstructors
+// in a file. Their messages are shown to end users, so the rule is the inverse of the prefix rule: a message
+// must not start with a location prefix, e.g. usererr.New("pkg.Register: email is invalid"), and must not
+// format an error, whose chain of prefixes would be shown along with it. The prefix is reported if it has
+// a function component or names the package, so a message like "Error: bad email" is fine. The calls are
+// checked in every function, exported or not, and in package-level declarations.
+func handleUserMessages(pass *analysis.Pass, file *ast.File) {
+	if !config.enabled(ruleUserMessage) {
+		return
+	}
+	ast.Inspect(file, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		ctor, ok := userConstructorOf(pass, call)
+		if !ok || ctor.messageArg(call) == nil || !reportable(pass, call.Pos()) {
+			return true
+		}
+		format, ok := stableString(pass, ctor.messageArg(call))
+		if !ok {
+			return true
+		}
+		if prefix, ok := leakedPrefix(pass, format, ctor.isFormat()); ok {
+			diag := analysis.Diagnostic{
+				Pos:      call.Pos(),
+				Category: ruleUserMessage,
+				Message:  "User-facing message must not contain the internal location prefix " + strconv.Quote(prefix),
+			}
+			if lit := messageLiteral(call, ctor); lit != nil && strings.HasPrefix(lit.Value[1:], prefix) {
+				diag.SuggestedFixes = []analysis.SuggestedFix{{
+					Message: "Remove the prefix " + strconv.Quote(prefix),
+					TextEdits: []analysis.TextEdit{{
+						Pos: lit.Pos() + 1,
+						End: lit.Pos() + 1 + token.Pos(len(prefix)),
+					}},
+				}}
+			}
+			pass.Report(diag)
+			return true
+		}
+		if arg, ok := formattedError(pass, call, ctor, format); ok {
+			reportf(pass, call.Pos(), ruleUserMessage,
+				"User-facing message formats the error %s, its chain of internal prefixes would be shown to users",
+				types.ExprString(arg))
+		}
+		return true
+	})
+}
+
+// userConstructorOf returns the description of the user-facing error constructor a call calls.
+func userConstructorOf(pass *analysis.Pass, call *ast.CallExpr) (constructor, bool) {
+	callee, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok {
+		return constructor{}, false
+	}
+	c, ok := config.userConstructors.lookup(funcFullName(callee))
+	if !ok {
+		return constructor{}, false
+	}
+	c, err := c.resolve(callee)
+	return c, err == nil
+}
+
+// leakedPrefix returns the location prefix a user-facing message starts with along with the separator,
+// e.g. "store.DB.Get: ". Only the part of a format before the first verb is looked at.
+func leakedPrefix(pass *analysis.Pass, message string, isFormat bool) (string, bool) {
+	if isFormat {
+		message, _ = formatLiteral(message)
+	}
+	loc, err := parsePrefix(message)
+	if err != nil {
+		return "", false
+	}
+	pkg := loc.pkg[strings.LastIndex(loc.pkg, "/")+1:]
+	if !isPackageName(pass.Pkg, loc.pkg) && (loc.fn == "" || !token.IsIdentifier(pkg)) {
+		return "", false
+	}
+	sep := string(config.separator)
+	return message[:strings.Index(message, sep)+len(sep)], true
+}
+
+// formattedError returns the first argument of an error type formatted by a user-facing message.
+func formattedError(pass *analysis.Pass, call *ast.CallExpr, ctor constructor, format string) (ast.Expr, bool) {
+	args := ctor.formatArgs(call)
+	verbs, ok := fmtverb.Parse(format)
+	if !ok || call.Ellipsis.IsValid() {
+		return nil, false
+	}
+	for _, v := range verbs {
+		if v.Arg >= len(args) || v.Verb == 'T' {
+			continue
+		}
+		if typ := pass.TypesInfo.TypeOf(args[v.Arg]); typ != nil && verbAccepts('w', typ) {
+			return args[v.Arg], true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,91 @@
+package errchain
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"github.com/iimos/go-check-err-chains/internal/fmtverb"
+	"golang.org/x/tools/go/analysis"
+)
+
+var ruleFormatVerb = registerRule(rule{
+	code: "format-verb",
+	doc: "format verbs of fmt.Errorf calls match their arguments, e.g. %d formats an integer; " +
+		"disable it to leave the check to the printf analyzer of go vet",
+	enabledByDefault: always,
+	example: func(fn, _ string) (string, string) {
+		return `fmt.Errorf("` + fn + `%d: not found", name)`, `fmt.Errorf("` + fn + `%q: not found", name)`
+	},
+})
+
+// checkVerbs checks that the verbs of a message format match the arguments formatted by it. Otherwise the message
+// rendered for the prefix checks would be mangled by fmt, e.g. "%!d(string=...)", so the mismatch is reported instead.
+// Types not known to be wrong for a verb, like structs or types implementing fmt.Formatter, are accepted.
+func checkVerbs(pass *analysis.Pass, call *ast.CallExpr, args []ast.Expr, format string) *prefixError {
+	verbs, ok := fmtverb.Parse(format)
+	if !ok || call.Ellipsis.IsValid() {
+		return nil
+	}
+	if len(verbs) != len(args) {
+		return &prefixError{errType: errFormatArgs, got: fmt.Sprintf("%d verbs for %d arguments", len(verbs), len(args))}
+	}
+	for _, v := range verbs {
+		typ := pass.TypesInfo.TypeOf(args[v.Arg])
+		if typ != nil && !verbAccepts(v.Verb, typ) {
+			return &prefixError{errType: errFormatVerb, got: format[v.Start:v.End], expect: typ.String()}
+		}
+	}
+	return nil
+}
+
+// verbAccepts tells whether a verb may format a value of a type as the fmt package documents it.
+func verbAccepts(verb rune, typ types.Type) bool {
+	switch verb {
+	case 'v', 'T':
+		return true
+	case 'w':
+		return types.Implements(typ, types.Universe.Lookup("error").Type().Underlying().(*types.Interface))
+	}
+	if hasMethod(typ, "Format") {
+		return true
+	}
+	if (hasMethod(typ, "Error") || hasMethod(typ, "String")) && strings.ContainsRune("sqxX", verb) {
+		return true
+	}
+	basic, ok := typ.Underlying().(*types.Basic)
+	if !ok {
+		return true
+	}
+
+	info := basic.Info()
+	integer := info&types.IsInteger != 0
+	float := info&(types.IsFloat|types.IsComplex) != 0
+	switch verb {
+	case 'c', 'd', 'o', 'O', 'U':
+		return integer
+	case 'b':
+		return integer || float
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		return float
+	case 'x', 'X':
+		return integer || float || info&types.IsString != 0
+	case 's':
+		return info&types.IsString != 0
+	case 'q':
+		return integer || info&types.IsString != 0
+	case 't':
+		return info&types.IsBoolean != 0
+	case 'p':
+		return basic.Kind() == types.UnsafePointer
+	}
+	return false
+}
+
+// hasMethod tells whether the method set of a type or a pointer to it has a method.
+func hasMethod(typ types.Type, name string) bool {
+	obj, _, _ := types.LookupFieldOrMethod(typ, true, nil, name)
+	_, ok := obj.(*types.Func)
+	return ok
+}
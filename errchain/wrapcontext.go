@@ -0,0 +1,73 @@
+package errchain
+
+import (
+	"go/ast"
+	"strings"
+	"unicode"
+
+	"github.com/iimos/go-check-err-chains/internal/fmtverb"
+	"golang.org/x/tools/go/analysis"
+)
+
+var ruleWrapContext = registerRule(rule{
+	code:             "wrap-context",
+	doc:              `wrapping messages describe what failed besides the prefix, e.g. fmt.Errorf("pkg.Fn: load config: %w", err)`,
+	enabledByDefault: func(c *configuration) bool { return c.minContextWords > 0 },
+	flags:            []string{"min-context-words"},
+	example: func(fn, _ string) (string, string) {
+		return `fmt.Errorf("` + fn + `%w", err)`, `fmt.Errorf("` + fn + `load config: %w", err)`
+	},
+})
+
+// handleWrapContext reports messages of a function wrapping an error with fewer words than config.minContextWords
+// after the prefix, e.g. fmt.Errorf("pkg.Fn: %w", err): the chain tells where the error passed, but not what
+// the function was doing. Verbs aren't words, so fmt.Errorf("pkg.Fn: %s: %w", key, err) has none either.
+// Only messages with a valid prefix are reported, the others are reported by the prefix rule first.
+func handleWrapContext(pass *analysis.Pass, fn *funcInfo, rules componentRules) {
+	if !config.enabled(ruleWrapContext) {
+		return
+	}
+	ast.Inspect(fn.decl.Body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			check, ok := checkCall(pass, fn, rules, node)
+			if !ok || check.err != nil {
+				return true
+			}
+			format, ok := stableString(pass, check.ctor.messageArg(node))
+			if !ok || !check.ctor.wraps(node, format) {
+				return true
+			}
+			sep := string(config.separator)
+			i := strings.Index(format, sep)
+			if i < 0 {
+				return true
+			}
+			words, ok := contextWords(format[i+len(sep):], check.ctor.isFormat())
+			if !ok || words >= config.minContextWords {
+				return true
+			}
+			reportf(pass, node.Pos(), ruleWrapContext,
+				"Wrapping adds %d of %d required context words after the prefix, describe what failed, e.g. %q",
+				words, config.minContextWords, format[:i+len(sep)]+"load config"+sep+format[i+len(sep):])
+		}
+		return true
+	})
+}
+
+// contextWords counts the words of a message, the runs of letters outside the verbs of a format.
+// It returns false if the verbs of the format can't be parsed.
+func contextWords(text string, isFormat bool) (int, bool) {
+	if isFormat {
+		verbs, ok := fmtverb.Parse(text)
+		if !ok {
+			return 0, false
+		}
+		for i := len(verbs) - 1; i >= 0; i-- {
+			text = text[:verbs[i].Start] + " " + text[verbs[i].End:]
+		}
+	}
+	return len(strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) })), true
+}
@@ -0,0 +1,81 @@
+package errchain
+
+import (
+	"go/ast"
+	"strconv"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var ruleWrapper = registerRule(rule{
+	code:             "wrapper",
+	doc:              `errors wrapped with %w in the packages set by -wrapper-packages start with the package prefix, e.g. "retry: "`,
+	enabledByDefault: func(c *configuration) bool { return len(c.wrapperPackages) > 0 },
+	flags:            []string{"wrapper-packages"},
+	example: func(_, pkg string) (string, string) {
+		return `fmt.Errorf("after %d attempts: %w", n, err)`, `fmt.Errorf("` + pkg + `after %d attempts: %w", n, err)`
+	},
+})
+
+// handleWrappers checks the errors wrapped by a package set by config.wrapperPackages, e.g. a retry package
+// adding attempt counts to errors of its callees. Such a package adds its own level to the chain, so every
+// call wrapping an error, like fmt.Errorf with %w or a constructor with the wrapped argument, starts with
+// the package prefix, in unexported functions and function literals as well. Calls already reported
+// by the other rules are skipped.
+func handleWrappers(pass *analysis.Pass, file *ast.File) {
+	if !config.enabled(ruleWrapper) || !config.wrapperPackages.match(pass.Pkg.Path()) {
+		return
+	}
+	state := stateOf(pass.Pkg)
+	name := packageNames(pass.Pkg)[0]
+	sep := string(config.separator)
+	ast.Inspect(file, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		ctor, ok := constructorOf(pass, call)
+		if !ok || ctor.messageArg(call) == nil {
+			return true
+		}
+		format, ok := stableString(pass, ctor.messageArg(call))
+		if !ok || !ctor.wraps(call, format) || hasPackagePrefix(pass, format) || !reportable(pass, call.Pos()) {
+			return true
+		}
+		if state != nil {
+			if state.reported[call.Pos()] {
+				return true
+			}
+			state.reported[call.Pos()] = true
+		}
+
+		diag := analysis.Diagnostic{
+			Pos:      call.Pos(),
+			Category: ruleWrapper,
+			Message: "Wrapped error must get the prefix of the wrapper package " + pass.Pkg.Path() +
+				": start the message with " + strconv.Quote(name+sep),
+		}
+		if lit := messageLiteral(call, ctor); lit != nil {
+			value, _ := strconv.Unquote(lit.Value)
+			diag.SuggestedFixes = []analysis.SuggestedFix{{
+				Message: "Add prefix " + strconv.Quote(name+sep),
+				TextEdits: []analysis.TextEdit{{
+					Pos:     lit.Pos(),
+					End:     lit.End(),
+					NewText: []byte(strconv.Quote(name + sep + value)),
+				}},
+			}}
+		}
+		pass.Report(diag)
+		return true
+	})
+}
+
+// hasPackagePrefix tells whether a message starts with a prefix naming the package, whatever location follows it.
+func hasPackagePrefix(pass *analysis.Pass, format string) bool {
+	if startsWithVerb(format) {
+		return false
+	}
+	loc, err := parsePrefix(slashQualified(pass.Pkg, format))
+	return err != errNoPrefix && loc.pkg != "" && isPackageName(pass.Pkg, loc.pkg)
+}
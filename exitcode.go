@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// The exit codes of errchain, so CI scripts can tell the findings from a broken build.
+const (
+	exitOK     = 0 // no diagnostics
+	exitIssues = 1 // diagnostics found
+	exitError  = 2 // the packages can't be loaded or analyzed
+	exitConfig = 3 // bad flags or configuration files
+)
+
+// checkerEnv marks the processes running the checker itself, with the exit codes of singlechecker.Main:
+// the children of runChecks and of the driver. It has no envPrefix, so it sets no flag.
+const checkerEnv = "GO_CHECK_ERR_CHAINS_CHECKER"
+
+func init() {
+	// The flag is handled by main; it is registered only to appear in the usage.
+	flag.Bool("no-fail", false, "exit with 0 when diagnostics are found, e.g. to collect a report; "+
+		"load, analysis and configuration errors still fail")
+}
+
+// runChecks runs the checker in a child process writing to the same stdout and stderr and translates its exit code:
+// the checker exits with 3 on diagnostics, 1 on load and analysis errors and the flag package exits with 2
+// on bad flags.
+func runChecks(args []string) (exitcode int) {
+	exe, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "errchain: %v\n", err)
+		return exitError
+	}
+	cmd := exec.Command(exe, args...)
+	cmd.Env = append(childEnviron(), checkerEnv+"=1")
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return exitOK
+	case !errors.As(err, &exitErr):
+		fmt.Fprintf(os.Stderr, "errchain: %v\n", err)
+		return exitError
+	}
+	switch exitErr.ExitCode() {
+	case 3:
+		return exitIssues
+	case 2:
+		return exitConfig
+	}
+	return exitError
+}
+
+// checkExitCode returns the exit code of the driver for an error of a checker process:
+// the flag package exits with 2 on bad flags, e.g. a wrong -separator, otherwise the packages failed.
+func checkExitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 2 {
+		return exitConfig
+	}
+	return exitError
+}
+
+// isVetTool tells whether the checker is run by go vet -vettool, which expects the exit codes of unitchecker:
+// errchain -V=full, errchain -flags or errchain [flags] unit.cfg.
+func isVetTool(args []string) bool {
+	_, _, version := extractFlag(args, "V")
+	return version || hasFlag(args, "flags") || len(args) > 0 && strings.HasSuffix(args[len(args)-1], ".cfg")
+}
+
+// parseFlags parses the arguments of a subcommand with a flag set continuing on errors. It returns false with
+// the exit code if the subcommand must stop: exitOK after -h and exitConfig on bad flags, which the flag set reports.
+func parseFlags(flags *flag.FlagSet, args []string) (exitcode int, ok bool) {
+	err := flags.Parse(args)
+	switch {
+	case err == nil:
+		return exitOK, true
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK, false
+	}
+	return exitConfig, false
+}
+
+// noFail returns the exit code with -no-fail: the diagnostics don't fail the run, the errors do.
+func noFail(exitcode int) int {
+	if exitcode == exitIssues {
+		return exitOK
+	}
+	return exitcode
+}
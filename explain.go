@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/iimos/go-check-err-chains/errchain"
+)
+
+// exampleFunc is the function the examples of explain are given for unless -func is set.
+const exampleFunc = "pkg.Type.Method"
+
+// runExplain prints what a rule checks, a failing and a passing example for a function and how to configure
+// or disable the rule. The flags of the analyzer apply, e.g. -separator changes the prefixes of the examples.
+func runExplain(args []string) int {
+	return explain(os.Stdout, append(envArgs(os.Environ(), isAnalyzerFlag), args...))
+}
+
+func explain(w io.Writer, args []string) int {
+	flags := flag.NewFlagSet("explain", flag.ContinueOnError)
+	fn := flags.String("func", exampleFunc, "function to give the examples for, e.g. store.(*DB).Get")
+	errchain.Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		flags.Var(f.Value, f.Name, f.Usage)
+	})
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: errchain explain [-func pkg.Type.Method] [flags] rule\n\n")
+		fmt.Fprintf(os.Stderr, "Explains a rule, see -list-rules for their codes.\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	// the rule may precede or follow the flags
+	if exitcode, ok := parseFlags(flags, args); !ok {
+		return exitcode
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitConfig
+	}
+	code := flags.Arg(0)
+	if exitcode, ok := parseFlags(flags, flags.Args()[1:]); !ok {
+		return exitcode
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "errchain explain: unexpected arguments %s\n", strings.Join(flags.Args(), " "))
+		return exitConfig
+	}
+
+	// Example fails for unknown rules, so the rule is found below
+	bad, good, ok, err := errchain.Example(code, *fn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "errchain explain: %v\n", err)
+		return exitConfig
+	}
+
+	var info errchain.RuleInfo
+	for _, r := range errchain.Rules() {
+		if r.Code == code {
+			info = r
+		}
+	}
+	state := "disabled"
+	if info.Enabled {
+		state = "enabled"
+	}
+	fmt.Fprintf(w, "%s (%s): %s.\n", info.Code, state, info.Doc)
+	if ok {
+		fmt.Fprintf(w, "\nFails in %s:\n\n%s\n", *fn, indent(bad))
+		fmt.Fprintf(w, "\nPasses:\n\n%s\n", indent(good))
+	}
+	fmt.Fprintln(w)
+	if len(info.Flags) > 0 {
+		fmt.Fprintf(w, "Configured by -%s.\n", strings.Join(info.Flags, ", -"))
+	}
+	if info.Enabled {
+		fmt.Fprintf(w, "Disable it with -disable=%s or %sDISABLE=%s.\n", info.Code, envPrefix, info.Code)
+	} else {
+		fmt.Fprintf(w, "Enable it with -enable=%s or %sENABLE=%s.\n", info.Code, envPrefix, info.Code)
+	}
+	return exitOK
+}
+
+// indent indents the lines of a snippet with a tab.
+func indent(s string) string {
+	return "\t" + strings.ReplaceAll(s, "\n", "\n\t")
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	for _, tt := range []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"explain", "prefix"}, []string{
+			"prefix (enabled): ",
+			"Fails in " + exampleFunc + ":\n\n\terrors.New(\"not found\")\n",
+			"Passes:\n\n\terrors.New(\"pkg.Type.Method: not found\")\n",
+			"Configured by -separator, ",
+			"Disable it with -disable=prefix or ERRCHAIN_DISABLE=prefix.\n",
+		}},
+		// the rule may precede or follow the flags
+		{[]string{"explain", "prefix", "-func", "store.Get"}, []string{
+			"Fails in store.Get:\n",
+			"Passes:\n\n\terrors.New(\"store.Get: not found\")\n",
+		}},
+		{[]string{"explain", "-func=store.Get", "-separator= - ", "prefix"}, []string{
+			"\terrors.New(\"store.Get - not found\")\n",
+		}},
+		{[]string{"explain", "-disable=prefix", "prefix"}, []string{
+			"prefix (disabled): ",
+			"Enable it with -enable=prefix or ERRCHAIN_ENABLE=prefix.\n",
+		}},
+	} {
+		out, exitcode := runMain(t, tt.args...)
+		if exitcode != exitOK {
+			t.Errorf("errchain %q: exit code = %d, want %d, output:\n%s", tt.args, exitcode, exitOK, out)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("errchain %q: the output doesn't contain %q:\n%s", tt.args, want, out)
+			}
+		}
+	}
+}
+
+func TestExplainErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"explain", "no-such-rule"},
+		{"explain"},
+		{"explain", "prefix", "canonical"},
+		{"explain", "-no-such-flag", "prefix"},
+	} {
+		if out, exitcode := runMain(t, args...); exitcode != exitConfig || out != "" {
+			t.Errorf("errchain %q: exit code = %d, want %d, output:\n%s", args, exitcode, exitConfig, out)
+		}
+	}
+}
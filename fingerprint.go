@@ -0,0 +1,62 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	// The flag is handled by runDriver; it is registered only to appear in the usage.
+	flag.Bool("fingerprints", false, "add content-based fingerprints to the diagnostics, which don't change when "+
+		"the code around moves, e.g. to match diagnostics against a baseline of known ones")
+}
+
+// fingerprint sets the fingerprints of all the diagnostics of the set. A fingerprint is computed from
+// the package, the enclosing function, the rule and the message of the error constructor at the position,
+// so it survives edits shifting the code. Diagnostics with equal contents in a function are told apart
+// by their order.
+func fingerprint(set *diagnosticSet, src *sources) {
+	type site struct {
+		diag *jsonDiagnostic
+		key  string
+	}
+	var sites []site
+	for id, results := range set.diags {
+		for _, diags := range results {
+			for i := range diags {
+				d := &diags[i]
+				fn, call := src.context(d.Posn)
+				content := d.Message
+				if call != nil && len(call.Args) > 0 {
+					content = types.ExprString(call.Args[0])
+				}
+				key := strings.Join([]string{packagePath(id), fn, d.Category, content}, "\x00")
+				sites = append(sites, site{diag: d, key: key})
+			}
+		}
+	}
+
+	sort.Slice(sites, func(i, j int) bool {
+		fi, li, ci := splitPosn(sites[i].diag.Posn)
+		fj, lj, cj := splitPosn(sites[j].diag.Posn)
+		if fi != fj {
+			return fi < fj
+		}
+		if li != lj {
+			return li < lj
+		}
+		return ci < cj
+	})
+	seen := make(map[string]int)
+	for _, s := range sites {
+		n := seen[s.key]
+		seen[s.key]++
+		sum := sha256.Sum256([]byte(s.key + "\x00" + strconv.Itoa(n)))
+		s.diag.Fingerprint = hex.EncodeToString(sum[:8])
+	}
+}
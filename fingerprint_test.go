@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fingerprints returns the fingerprints of the errchain diagnostics at the positions of a file
+// in the example.com/store package, in the order of the positions.
+func fingerprints(t *testing.T, src string, posns ...string) []string {
+	t.Helper()
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "store.go")
+	if err := os.WriteFile(filename, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var diags []jsonDiagnostic
+	for _, posn := range posns {
+		diags = append(diags, jsonDiagnostic{Category: "prefix", Posn: filepath.Join(dir, posn), Message: "message"})
+	}
+	set := testSet(t, map[string][]jsonDiagnostic{"example.com/store": diags})
+	fingerprint(set, newSources())
+
+	byPosn := make(map[string]string)
+	for _, d := range set.sorted() {
+		byPosn[d.Posn] = d.Fingerprint
+	}
+	var fps []string
+	for _, posn := range posns {
+		fps = append(fps, byPosn[filepath.Join(dir, posn)])
+	}
+	return fps
+}
+
+func TestFingerprint(t *testing.T) {
+	const src = `package store
+
+import "errors"
+
+func Get(key string) error {
+	if key == "" {
+		return errors.New("empty key")
+	}
+	return errors.New("empty key")
+}
+
+func Put(key string) error {
+	return errors.New("empty key")
+}
+`
+	fps := fingerprints(t, src, "store.go:7:10", "store.go:9:9", "store.go:13:9")
+	for i, fp := range fps {
+		if len(fp) != 16 {
+			t.Fatalf("fingerprint %d = %q, want 16 hex digits", i, fp)
+		}
+	}
+	// equal messages are told apart by their order in a function and by the function
+	if fps[0] == fps[1] || fps[0] == fps[2] || fps[1] == fps[2] {
+		t.Errorf("fingerprints = %q, want distinct ones", fps)
+	}
+
+	// the code moved down by the added doc comments keeps the fingerprints
+	const moved = `package store
+
+import "errors"
+
+// Get returns the value of a key.
+func Get(key string) error {
+	if key == "" {
+		return errors.New("empty key")
+	}
+	return errors.New("empty key")
+}
+
+// Put sets the value of a key.
+func Put(key string) error {
+	return errors.New("empty key")
+}
+`
+	got := fingerprints(t, moved, "store.go:8:10", "store.go:10:9", "store.go:15:9")
+	for i := range got {
+		if got[i] != fps[i] {
+			t.Errorf("fingerprints of the moved code = %q, want %q", got, fps)
+			break
+		}
+	}
+}
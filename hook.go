@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func init() {
+	// The flag is handled by runDriver; it is registered only to appear in the usage.
+	flag.Bool("staged", false, "check the packages of the Go files staged for commit in the git index instead of "+
+		"the package patterns and report the diagnostics in these files only, e.g. in a pre-commit hook, see install-hook")
+}
+
+// runInstallHook writes a git pre-commit hook running the checker with -staged and the checker flags
+// following "--", so the rules are enforced on the staged files before the code reaches CI.
+func runInstallHook(args []string) int {
+	flags := flag.NewFlagSet("install-hook", flag.ContinueOnError)
+	force := flags.Bool("f", false, "overwrite an existing pre-commit hook")
+	command := flags.String("command", "errchain", "command running the checker in the hook, e.g. a path "+
+		"to the binary or \"go run github.com/iimos/go-check-err-chains@latest\"")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: errchain install-hook [-f] [-command cmd] [-- checker flags]\n\n")
+		fmt.Fprintf(os.Stderr, "Writes a pre-commit hook checking the Go files staged for commit, see -staged.\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	if exitcode, ok := parseFlags(flags, args); !ok {
+		return exitcode
+	}
+
+	hooks, err := gitOutput("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "errchain install-hook: %v\n", err)
+		return exitError
+	}
+	filename := filepath.Join(strings.TrimSpace(hooks), "pre-commit")
+	if _, err := os.Stat(filename); err == nil && !*force {
+		fmt.Fprintf(os.Stderr, "errchain install-hook: %s exists, use -f to overwrite it\n", filename)
+		return exitConfig
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "errchain install-hook: %v\n", err)
+		return exitError
+	}
+	if err := os.WriteFile(filename, []byte(hookScript(*command, flags.Args())), 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "errchain install-hook: %v\n", err)
+		return exitError
+	}
+	fmt.Printf("errchain install-hook: wrote %s\n", filename)
+	return exitOK
+}
+
+// hookScript returns a pre-commit hook running a command with -staged and the checker flags.
+// The command is written as is, so it may have arguments, and the flags are quoted for the shell.
+// Git for Windows runs the hook with its own shell as well.
+func hookScript(command string, checkerFlags []string) string {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	b.WriteString("# Written by errchain install-hook: checks the Go files staged for commit.\n")
+	b.WriteString("# Skip the check once with git commit --no-verify.\n")
+	b.WriteString("exec " + command + " -staged")
+	for _, arg := range checkerFlags {
+		b.WriteString(" " + shellQuote(arg))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// shellQuote quotes an argument for sh unless it consists of safe characters only.
+func shellQuote(arg string) string {
+	safe := arg != "" && strings.Trim(arg, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_=.,/:@") == ""
+	if safe {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// stagedFiles returns the absolute paths of the Go files added, copied, modified or renamed in the git index.
+func stagedFiles() ([]string, error) {
+	top, err := gitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("stagedFiles: %w", err)
+	}
+	out, err := gitOutput("diff", "--cached", "--name-only", "--diff-filter=ACMR", "-z", "--", "*.go")
+	if err != nil {
+		return nil, fmt.Errorf("stagedFiles: %w", err)
+	}
+	var files []string
+	for _, name := range strings.Split(out, "\x00") {
+		if name != "" {
+			files = append(files, filepath.Join(strings.TrimSpace(top), filepath.FromSlash(name)))
+		}
+	}
+	return files, nil
+}
+
+// stagedPatterns returns the package patterns of the directories of the files relative to the working directory,
+// e.g. "./store" for store/db.go.
+func stagedPatterns(files []string) ([]string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("stagedPatterns: %w", err)
+	}
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, file := range files {
+		dir, err := filepath.Rel(wd, filepath.Dir(file))
+		if err != nil {
+			return nil, fmt.Errorf("stagedPatterns: %w", err)
+		}
+		dir = "./" + filepath.ToSlash(dir)
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// reportFilesFlag returns the -report-files flag of the checker limiting the diagnostics to the files, so
+// the diagnostics of the other files of their packages aren't built at all. The diagnostics are filtered
+// by onlyFiles as well, so the flag is left out if a file name can't be a pattern, e.g. it has a comma or a "*".
+func reportFilesFlag(files []string) (string, bool) {
+	for _, file := range files {
+		if strings.ContainsAny(file, ",*?[") {
+			return "", false
+		}
+	}
+	return "-report-files=" + strings.Join(files, ","), true
+}
+
+// onlyFiles removes the diagnostics of the set outside the files given by their absolute paths.
+func (set *diagnosticSet) onlyFiles(files []string) {
+	keep := make(map[string]bool, len(files))
+	for _, file := range files {
+		keep[file] = true
+	}
+	for id, results := range set.diags {
+		for name, diags := range results {
+			kept := diags[:0]
+			for _, d := range diags {
+				file, _, _ := splitPosn(d.Posn)
+				if abs, err := filepath.Abs(file); err == nil && keep[abs] {
+					kept = append(kept, d)
+				}
+			}
+			if len(kept) == 0 {
+				delete(results, name)
+			} else {
+				results[name] = kept
+			}
+		}
+		if len(results) == 0 {
+			delete(set.diags, id)
+		}
+	}
+}
+
+// gitOutput runs a git command and returns its output.
+func gitOutput(args ...string) (string, error) {
+	var stdout bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHookScript(t *testing.T) {
+	got := hookScript("go run github.com/iimos/go-check-err-chains@latest", []string{"-separator= - ", "-disable=style"})
+	want := `#!/bin/sh
+# Written by errchain install-hook: checks the Go files staged for commit.
+# Skip the check once with git commit --no-verify.
+exec go run github.com/iimos/go-check-err-chains@latest -staged '-separator= - ' -disable=style
+`
+	if got != want {
+		t.Errorf("hookScript() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	args := []string{"-disable=style,wrap-verb", "", "-separator= - ", "it's", `"$HOME"`, "a*b", "-tags=a b;c"}
+	want := []string{"-disable=style,wrap-verb", "''", "'-separator= - '", `'it'\''s'`, `'"$HOME"'`, "'a*b'", "'-tags=a b;c'"}
+	var quoted []string
+	for _, arg := range args {
+		quoted = append(quoted, shellQuote(arg))
+	}
+	if !reflect.DeepEqual(quoted, want) {
+		t.Errorf("shellQuote(%q) = %q, want %q", args, quoted, want)
+	}
+
+	// the shell gets the arguments back as they were
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no sh:", err)
+	}
+	out, err := exec.Command(sh, "-c", `printf '%s\n' `+strings.Join(quoted, " ")).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n"); !reflect.DeepEqual(got, args) {
+		t.Errorf("sh got %q, want %q", got, args)
+	}
+}
+
+func TestStagedPatterns(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := []string{
+		filepath.Join(wd, "store", "db.go"),
+		filepath.Join(wd, "main.go"),
+		filepath.Join(wd, "store", "cache.go"),
+		filepath.Join(wd, "api", "v1", "user.go"),
+	}
+	got, err := stagedPatterns(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"./.", "./api/v1", "./store"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("stagedPatterns() = %q, want %q", got, want)
+	}
+}
+
+func TestReportFilesFlag(t *testing.T) {
+	if got, ok := reportFilesFlag([]string{"/src/a.go", "/src/b.go"}); !ok || got != "-report-files=/src/a.go,/src/b.go" {
+		t.Errorf("reportFilesFlag() = %q, %v", got, ok)
+	}
+	for _, file := range []string{"/src/a,b.go", "/src/[a].go", "/src/*.go"} {
+		if got, ok := reportFilesFlag([]string{"/src/a.go", file}); ok {
+			t.Errorf("reportFilesFlag(%q) = %q, want no flag, since the name isn't a pattern", file, got)
+		}
+	}
+}
+
+func TestOnlyFiles(t *testing.T) {
+	dir := t.TempDir()
+	staged := filepath.Join(dir, "staged.go")
+	set := testSet(t, map[string][]jsonDiagnostic{
+		"example.com/a": {{Posn: staged + ":1:1", Message: "kept"}, {Posn: filepath.Join(dir, "other.go") + ":1:1"}},
+		"example.com/b": {{Posn: filepath.Join(dir, "b.go") + ":1:1"}},
+	})
+	set.onlyFiles([]string{staged})
+	if got := set.sorted(); len(got) != 1 || got[0].Message != "kept" {
+		t.Errorf("onlyFiles kept %+v, want the diagnostic of %s", got, staged)
+	}
+	if _, ok := set.diags["example.com/b"]; ok {
+		t.Error("onlyFiles kept a package without diagnostics")
+	}
+}
@@ -0,0 +1,33 @@
+// Package envflags turns the ERRCHAIN_* environment variables into command line flags, so the errchain
+// and errlint commands read the same configuration from the environment.
+package envflags
+
+import (
+	"sort"
+	"strings"
+)
+
+// Prefix is the prefix of environment variables setting flags, e.g. ERRCHAIN_PKG_COMPONENT=optional
+// is the same as -pkg-component=optional.
+const Prefix = "ERRCHAIN_"
+
+// Args returns the command line flags set by the variables of the environment, sorted so they don't depend
+// on its order. The name a variable spells, e.g. "pkg-component" for ERRCHAIN_PKG_COMPONENT, is passed
+// to flagName, which returns the name of the flag it sets, e.g. "errchain.pkg-component" in a multichecker,
+// or false if there is no such flag: the variable is then ignored, since it may belong to some other tool.
+// The flags must precede the command line arguments so that flags passed explicitly take precedence.
+func Args(environ []string, flagName func(name string) (string, bool)) []string {
+	var args []string
+	for _, kv := range environ {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(key, Prefix) || key == Prefix {
+			continue
+		}
+		name := strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(key, Prefix), "_", "-"))
+		if name, ok := flagName(name); ok {
+			args = append(args, "-"+name+"="+value)
+		}
+	}
+	sort.Strings(args)
+	return args
+}
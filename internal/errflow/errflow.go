@@ -0,0 +1,49 @@
+// Package errflow finds where the error values of a function body come from for the analyzers
+// checking how errors are returned.
+package errflow
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// LastAssignedCall returns the call a variable is last assigned from in the body before its use,
+// or nil if the last assignment isn't a call.
+func LastAssignedCall(info *types.Info, body *ast.BlockStmt, use *ast.Ident) *ast.CallExpr {
+	v, ok := info.Uses[use].(*types.Var)
+	if !ok {
+		return nil
+	}
+	// The last assignment preceding the use is taken: err may be reused for errors of several calls.
+	var source *ast.CallExpr
+	var sourcePos token.Pos
+	ast.Inspect(body, func(node ast.Node) bool {
+		assign, ok := node.(*ast.AssignStmt)
+		if !ok || assign.Pos() >= use.Pos() || assign.Pos() < sourcePos {
+			return true
+		}
+		for i, lhs := range assign.Lhs {
+			ident, ok := lhs.(*ast.Ident)
+			if !ok || (info.Defs[ident] != v && info.Uses[ident] != v) {
+				continue
+			}
+			var rhs ast.Expr
+			switch {
+			case len(assign.Rhs) == len(assign.Lhs):
+				rhs = assign.Rhs[i]
+			case len(assign.Rhs) == 1 && assign.Tok != token.ADD_ASSIGN:
+				// x, err := f()
+				rhs = assign.Rhs[0]
+			}
+			source, sourcePos = nil, assign.Pos()
+			if call, ok := astutil.Unparen(rhs).(*ast.CallExpr); ok {
+				source = call
+			}
+		}
+		return true
+	})
+	return source
+}
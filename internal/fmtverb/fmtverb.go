@@ -0,0 +1,46 @@
+// Package fmtverb parses the verbs of fmt format strings for the analyzers checking fmt.Errorf calls.
+package fmtverb
+
+// A Verb is a formatting directive of a format string like "%w" or "%-8.2f".
+type Verb struct {
+	Start, End int  // byte offsets of the directive in the format string
+	Verb       rune // the verb character, e.g. 'w'
+	Arg        int  // index of the argument the verb formats, counting from the first argument after the format
+}
+
+// Parse returns the verbs of a format string. It returns false for formats it can't map to arguments reliably:
+// ones with explicit argument indexes like "%[1]d" or with widths and precisions set by arguments like "%*d".
+func Parse(format string) ([]Verb, bool) {
+	var verbs []Verb
+	arg := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		start := i
+		i++
+		for i < len(format) && isFlagOrSize(format[i]) {
+			i++
+		}
+		if i == len(format) {
+			break
+		}
+		switch format[i] {
+		case '%':
+			continue
+		case '[', '*':
+			return nil, false
+		}
+		verbs = append(verbs, Verb{Start: start, End: i + 1, Verb: rune(format[i]), Arg: arg})
+		arg++
+	}
+	return verbs, true
+}
+
+func isFlagOrSize(c byte) bool {
+	switch c {
+	case '+', '-', '#', ' ', '0', '.':
+		return true
+	}
+	return '1' <= c && c <= '9'
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/iimos/go-check-err-chains/errchain"
+)
+
+// A flatDiagnostic is a diagnostic of the -format json output: the checker's diagnostic with its package
+// and the data a tool would otherwise parse out of the message.
+type flatDiagnostic struct {
+	Package        string            `json:"package"`
+	Posn           string            `json:"posn"`
+	Rule           string            `json:"rule,omitempty"`
+	Message        string            `json:"message"`
+	Prefix         *flatPrefix       `json:"prefix,omitempty"`   // the prefix of the error message at the position
+	Expected       []string          `json:"expected,omitempty"` // the prefixes the diagnostic recommends
+	SuggestedFixes []json.RawMessage `json:"suggested_fixes,omitempty"`
+	Fingerprint    string            `json:"fingerprint,omitempty"`
+}
+
+type flatPrefix struct {
+	Pkg   string `json:"pkg"`
+	Recv  string `json:"recv,omitempty"`
+	Fn    string `json:"fn,omitempty"`
+	IsPtr bool   `json:"isPtr,omitempty"`
+}
+
+// writeJSON writes the diagnostics of the set as a JSON array with one object per diagnostic ordered
+// by position and returns the exit code, see exitIssues. If paths is set, the positions
+// are written relative to the module roots, see modulePaths; the sources are still read by the original ones.
+func writeJSON(w io.Writer, set *diagnosticSet, src *sources, paths *modulePaths) (exitcode int) {
+	for _, e := range set.errors {
+		fmt.Fprintln(os.Stderr, e)
+	}
+
+	diags := []flatDiagnostic{}
+	for id, results := range set.diags {
+		for _, ds := range results {
+			for _, d := range ds {
+				posn, fixes := d.Posn, d.SuggestedFixes
+				if paths != nil {
+					posn, fixes = paths.posn(posn), paths.fixes(fixes)
+				}
+				diags = append(diags, flatDiagnostic{
+					Package:        packagePath(id),
+					Posn:           posn,
+					Rule:           d.Category,
+					Message:        d.Message,
+					Prefix:         messagePrefix(src, d.Posn),
+					Expected:       errchain.ExpectedPrefixes(d.Message),
+					SuggestedFixes: fixes,
+					Fingerprint:    d.Fingerprint,
+				})
+			}
+		}
+	}
+	sort.Slice(diags, func(i, j int) bool {
+		fi, li, ci := splitPosn(diags[i].Posn)
+		fj, lj, cj := splitPosn(diags[j].Posn)
+		if fi != fj {
+			return fi < fj
+		}
+		if li != lj {
+			return li < lj
+		}
+		if ci != cj {
+			return ci < cj
+		}
+		return diags[i].Message < diags[j].Message
+	})
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(diags); err != nil {
+		fmt.Fprintf(os.Stderr, "errchain: %v\n", err)
+		return exitError
+	}
+	return set.exitCode(len(diags))
+}
+
+// messagePrefix returns the prefix of the literal message of the error constructor call at a position
+// or nil if there is no such call or its message has no well-formed prefix.
+func messagePrefix(src *sources, posn string) *flatPrefix {
+	_, call := src.context(posn)
+	if call == nil {
+		return nil
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return nil
+	}
+	message, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return nil
+	}
+	prefix, err := errchain.ParsePrefix(message)
+	if err != nil {
+		return nil
+	}
+	return &flatPrefix{Pkg: prefix.Pkg, Recv: prefix.Recv, Fn: prefix.Func, IsPtr: prefix.IsPtr}
+}
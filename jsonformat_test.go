@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "store.go")
+	src := `package store
+
+import "errors"
+
+type DB struct{}
+
+func (db *DB) Get(key string) error {
+	if key == "" {
+		return errors.New("store.(*DB).Get: empty key")
+	}
+	return errors.New("not found")
+}
+`
+	if err := os.WriteFile(filename, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	const recommendation = `Error message must point to the place where it had happened. ` +
+		`Consider starting message with one of the following strings: "store: ", "store.(*DB).Get: "`
+	set := testSet(t, map[string][]jsonDiagnostic{
+		"example.com/store [example.com/store.test]": {
+			{Category: "prefix", Posn: filename + ":11:9", Message: recommendation},
+			{Category: "style", Posn: filename + ":9:10", Message: "prefix style <pointer>"},
+		},
+	})
+
+	var buf bytes.Buffer
+	if got := writeJSON(&buf, set, newSources(), nil); got != exitIssues {
+		t.Errorf("writeJSON() = %d, want %d", got, exitIssues)
+	}
+	// HTML characters are written as they are
+	if !strings.Contains(buf.String(), `"prefix style <pointer>"`) {
+		t.Errorf("output escapes the messages:\n%s", buf.String())
+	}
+	var got []flatDiagnostic
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("decode output: %v\n%s", err, buf.String())
+	}
+	want := []flatDiagnostic{
+		{
+			Package: "example.com/store",
+			Posn:    filename + ":9:10",
+			Rule:    "style",
+			Message: "prefix style <pointer>",
+			Prefix:  &flatPrefix{Pkg: "store", Recv: "DB", Fn: "Get", IsPtr: true},
+		},
+		{
+			Package:  "example.com/store",
+			Posn:     filename + ":11:9",
+			Rule:     "prefix",
+			Message:  recommendation,
+			Expected: []string{"store: ", "store.(*DB).Get: "},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("writeJSON() wrote %+v, want %+v", got, want)
+	}
+}
+
+func TestWriteJSONEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if got := writeJSON(&buf, newDiagnosticSet(), newSources(), nil); got != exitOK {
+		t.Errorf("writeJSON() = %d, want %d", got, exitOK)
+	}
+	// an empty array rather than null, so the consumers can range over it
+	if got := strings.TrimSpace(buf.String()); got != "[]" {
+		t.Errorf("writeJSON() wrote %s, want []", got)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/iimos/go-check-err-chains/errchain"
+)
+
+// A lensEntry is the prefix coverage of a function in the -format lens output, e.g. for IDE extensions
+// rendering it as a code lens above the function.
+type lensEntry struct {
+	Package  string `json:"package"`
+	Posn     string `json:"posn"` // the position of the function name
+	Func     string `json:"func"`
+	Paths    int    `json:"paths"`
+	Prefixed int    `json:"prefixed"`
+	Covered  bool   `json:"covered"` // every error path of the function is prefixed
+}
+
+// writeLens writes the coverage summaries of the set as a JSON array with one object per function ordered
+// by position and returns the exit code, see exitIssues. The other diagnostics are left out of the output:
+// the functions with issues are told by their coverage, but they still fail the run.
+func writeLens(w io.Writer, set *diagnosticSet) (exitcode int) {
+	for _, e := range set.errors {
+		fmt.Fprintln(os.Stderr, e)
+	}
+
+	entries := []lensEntry{}
+	issues := 0
+	for id, results := range set.diags {
+		for _, ds := range results {
+			for _, d := range ds {
+				if d.Category != errchain.CoverageCategory {
+					issues++
+					continue
+				}
+				c, err := errchain.ParseCoverage(d.Message)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "errchain: %s: %v\n", d.Posn, err)
+					continue
+				}
+				entries = append(entries, lensEntry{
+					Package:  packagePath(id),
+					Posn:     d.Posn,
+					Func:     c.Func,
+					Paths:    c.Paths,
+					Prefixed: c.Prefixed,
+					Covered:  c.Prefixed == c.Paths,
+				})
+			}
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		fi, li, ci := splitPosn(entries[i].Posn)
+		fj, lj, cj := splitPosn(entries[j].Posn)
+		if fi != fj {
+			return fi < fj
+		}
+		if li != lj {
+			return li < lj
+		}
+		return ci < cj
+	})
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(entries); err != nil {
+		fmt.Fprintf(os.Stderr, "errchain: %v\n", err)
+		return exitError
+	}
+	return set.exitCode(issues)
+}
@@ -1,10 +1,91 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/iimos/go-check-err-chains/errchain"
 	"golang.org/x/tools/go/analysis/singlechecker"
 )
 
+// subcommands are run instead of the checker when the first argument is their name.
+var subcommands = map[string]func(args []string) (exitcode int){
+	"rename":       runRename,
+	"explain":      runExplain,
+	"deps":         runDeps,
+	"conventions":  runConventions,
+	"configcheck":  runConfigCheck,
+	"install-hook": runInstallHook,
+}
+
 func main() {
-	singlechecker.Main(errchain.Analyzer)
+	if len(os.Args) > 1 {
+		if cmd, ok := subcommands[os.Args[1]]; ok {
+			os.Exit(cmd(os.Args[2:]))
+		}
+	}
+
+	if hasFlag(os.Args[1:], "version") {
+		fmt.Println(versionString())
+		os.Exit(0)
+	}
+	os.Args = append(append(os.Args[:1:1], envArgs(os.Environ(), isCommandFlag)...), os.Args[1:]...)
+	if hasFlag(os.Args[1:], "list-rules") {
+		os.Exit(listRules(os.Stdout, withoutFlag(os.Args[1:], "list-rules")))
+	}
+
+	goflags, args, err := extractBuildFlags(os.Args[1:])
+	if err == nil {
+		err = appendGoflags(goflags)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "errchain: %v\n", err)
+		os.Exit(exitConfig)
+	}
+
+	format, args, _ := extractFlag(args, "format")
+	output, args, _ := extractFlag(args, "o")
+	switch format {
+	case "", "text", "json", "html", "lens":
+	default:
+		fmt.Fprintf(os.Stderr, "errchain: unknown -format %q, must be text, json, html or lens\n", format)
+		os.Exit(exitConfig)
+	}
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "errchain: %v\n", err)
+			os.Exit(exitError)
+		}
+		os.Stdout = f
+	}
+	os.Args = append(os.Args[:1], args...)
+
+	if os.Getenv(checkerEnv) != "" || isVetTool(os.Args[1:]) {
+		singlechecker.Main(errchain.Analyzer)
+	}
+	failOnIssues := !hasFlag(os.Args[1:], "no-fail")
+	os.Args = append(os.Args[:1], withoutFlag(os.Args[1:], "no-fail")...)
+	exit := func(exitcode int) {
+		if !failOnIssues {
+			exitcode = noFail(exitcode)
+		}
+		os.Exit(exitcode)
+	}
+
+	opts := driverOptions{format: format}
+	opts.matrix, args, _ = extractFlag(os.Args[1:], "matrix")
+	opts.metricsOut, args, _ = extractFlag(args, "metrics-out")
+	opts.counters, args, _ = extractFlag(args, "counters")
+	opts.overrides, args, _ = extractFlag(args, "overrides")
+	opts.fingerprints = hasFlag(args, "fingerprints")
+	args = withoutFlag(args, "fingerprints")
+	opts.staged = hasFlag(args, "staged")
+	args = withoutFlag(args, "staged")
+	opts.moduleRelative = hasFlag(args, "module-paths")
+	args = withoutFlag(args, "module-paths")
+	if opts.needed() {
+		exit(runDriver(opts, args))
+	}
+	exit(runChecks(os.Args[1:]))
 }
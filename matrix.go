@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func init() {
+	// The flag is handled by runDriver with collectMatrix before the checker starts; it is registered only
+	// to appear in the usage.
+	flag.String("matrix", "", "comma-separated list of GOOS/GOARCH pairs to analyze, e.g. linux/amd64,windows/amd64; "+
+		"diagnostics of all the build configurations are merged without duplicates")
+}
+
+// collectMatrix runs the checker once per GOOS/GOARCH pair so that files guarded by build constraints are analyzed
+// in every configuration, and adds the diagnostics of every run to the set.
+func collectMatrix(set *diagnosticSet, matrix string, sections []section, args []string) (exitcode int) {
+	profiles, args := extractProfileFlags(args)
+	for _, target := range strings.Split(matrix, ",") {
+		target = strings.TrimSpace(target)
+		if target == "" {
+			continue
+		}
+		goos, goarch, ok := strings.Cut(target, "/")
+		if !ok || goos == "" || goarch == "" {
+			fmt.Fprintf(os.Stderr, "errchain: invalid -matrix target %q, must be GOOS/GOARCH\n", target)
+			return exitConfig
+		}
+
+		targetArgs := append(profileArgs(profiles, target), args...)
+		tree, err := check([]string{"GOOS=" + goos, "GOARCH=" + goarch}, sections, targetArgs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "errchain: %s: %v\n", target, err)
+			return checkExitCode(err)
+		}
+		set.add(target, tree)
+	}
+	return exitOK
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDiagnosticSetMerge(t *testing.T) {
+	diags := func(ds ...jsonDiagnostic) json.RawMessage {
+		data, err := json.Marshal(ds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	shared := jsonDiagnostic{Category: "prefix", Posn: "store.go:10:9", Message: "Error message must point to the place"}
+	windows := jsonDiagnostic{Category: "prefix", Posn: "store_windows.go:9:9", Message: "Error message must point to the place"}
+	early := jsonDiagnostic{Category: "prefix", Posn: "store.go:9:9", Message: "Error message must point to the place"}
+
+	set := newDiagnosticSet()
+	set.add("linux/amd64", jsonTree{"example.com/store": {"errchain": diags(shared)}})
+	set.add("windows/amd64", jsonTree{"example.com/store": {"errchain": diags(windows, shared, early)}})
+	set.add("js/wasm", jsonTree{"example.com/store": {"errchain": json.RawMessage(`{"error":"no Go files"}`)}})
+
+	// the diagnostics of every target are kept once and ordered by line, not by the text of the position
+	if got, want := set.sorted(), []jsonDiagnostic{early, shared, windows}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sorted() = %+v, want %+v", got, want)
+	}
+	if want := []string{"js/wasm: example.com/store: errchain: no Go files"}; !reflect.DeepEqual(set.errors, want) {
+		t.Errorf("errors = %q, want %q", set.errors, want)
+	}
+	if got := set.exitCode(len(set.sorted())); got != exitError {
+		t.Errorf("exitCode() = %d, want %d, since an analysis failed", got, exitError)
+	}
+}
+
+func TestCollectMatrixInvalidTarget(t *testing.T) {
+	// an invalid target is reported before the checker runs for it
+	for _, matrix := range []string{"linux", "linux/", "/amd64", " windows "} {
+		if got := collectMatrix(newDiagnosticSet(), matrix, nil, nil); got != exitConfig {
+			t.Errorf("collectMatrix(%q) = %d, want %d", matrix, got, exitConfig)
+		}
+	}
+	if got := collectMatrix(newDiagnosticSet(), " , ", nil, nil); got != exitOK {
+		t.Errorf("collectMatrix of empty targets = %d, want %d", got, exitOK)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+func init() {
+	// The flag is handled by runDriver; it is registered only to appear in the usage.
+	flag.String("metrics-out", "", "write statistics of the run to a file in OpenMetrics text format, "+
+		"e.g. to push them to a Prometheus Pushgateway")
+}
+
+// otherKind is the kind of diagnostics without a category.
+const otherKind = "other"
+
+// An issueCount is the number of diagnostics of a kind in a package.
+type issueCount struct {
+	pkg, kind string
+	count     int
+}
+
+// writeMetrics writes the statistics of a run to a file in OpenMetrics text format. The kind of a diagnostic
+// is its category, which is the code of the rule that reported it.
+func writeMetrics(filename string, set *diagnosticSet, packages []string, duration time.Duration) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("writeMetrics: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	printMetrics(w, set, packages, duration)
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return fmt.Errorf("writeMetrics: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writeMetrics: %w", err)
+	}
+	return nil
+}
+
+func printMetrics(w io.Writer, set *diagnosticSet, packages []string, duration time.Duration) {
+	counts := make(map[[2]string]int)
+	withIssues := make(map[string]bool)
+	for id, results := range set.diags {
+		pkg := packagePath(id)
+		for _, diags := range results {
+			for _, d := range diags {
+				kind := d.Category
+				if kind == "" {
+					kind = otherKind
+				}
+				counts[[2]string{pkg, kind}]++
+				withIssues[pkg] = true
+			}
+		}
+	}
+	issues := make([]issueCount, 0, len(counts))
+	for key, count := range counts {
+		issues = append(issues, issueCount{pkg: key[0], kind: key[1], count: count})
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].pkg != issues[j].pkg {
+			return issues[i].pkg < issues[j].pkg
+		}
+		return issues[i].kind < issues[j].kind
+	})
+
+	fmt.Fprintln(w, "# TYPE errchain_issues gauge")
+	fmt.Fprintln(w, "# HELP errchain_issues Number of issues by package and kind.")
+	for _, c := range issues {
+		fmt.Fprintf(w, "errchain_issues{package=%s,kind=%s} %d\n", labelValue(c.pkg), labelValue(c.kind), c.count)
+	}
+	fmt.Fprintln(w, "# TYPE errchain_packages gauge")
+	fmt.Fprintln(w, "# HELP errchain_packages Number of analyzed packages.")
+	fmt.Fprintf(w, "errchain_packages %d\n", len(packages))
+	fmt.Fprintln(w, "# TYPE errchain_packages_with_issues gauge")
+	fmt.Fprintln(w, "# HELP errchain_packages_with_issues Number of packages with at least one issue.")
+	fmt.Fprintf(w, "errchain_packages_with_issues %d\n", len(withIssues))
+	fmt.Fprintln(w, "# TYPE errchain_analysis_errors gauge")
+	fmt.Fprintln(w, "# HELP errchain_analysis_errors Number of packages the analysis failed for.")
+	fmt.Fprintf(w, "errchain_analysis_errors %d\n", len(set.errors))
+	fmt.Fprintln(w, "# TYPE errchain_duration_seconds gauge")
+	fmt.Fprintln(w, "# UNIT errchain_duration_seconds seconds")
+	fmt.Fprintln(w, "# HELP errchain_duration_seconds Duration of the run.")
+	fmt.Fprintf(w, "errchain_duration_seconds %.3f\n", duration.Seconds())
+	fmt.Fprintln(w, "# EOF")
+}
+
+// labelValue quotes a label value escaping backslashes, double quotes and line feeds.
+func labelValue(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
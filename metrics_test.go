@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	set := testSet(t, map[string][]jsonDiagnostic{
+		"example.com/a": {
+			{Category: "prefix", Posn: "a.go:1:1", Message: "a"},
+			{Category: "prefix", Posn: "a.go:2:1", Message: "b"},
+			{Posn: "a.go:3:1", Message: "c"},
+		},
+		"example.com/a [example.com/a.test]": {{Category: "wrap-verb", Posn: "a_test.go:1:1", Message: "d"}},
+		`example.com/"quoted"`:               {{Category: "prefix", Posn: "q.go:1:1", Message: "e"}},
+	})
+	set.errors = append(set.errors, "example.com/broken: errchain: no Go files")
+	filename := filepath.Join(t.TempDir(), "metrics.txt")
+	packages := []string{"example.com/a", `example.com/"quoted"`, "example.com/clean"}
+	if err := writeMetrics(filename, set, packages, 1234*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# TYPE errchain_issues gauge
+# HELP errchain_issues Number of issues by package and kind.
+errchain_issues{package="example.com/\"quoted\"",kind="prefix"} 1
+errchain_issues{package="example.com/a",kind="other"} 1
+errchain_issues{package="example.com/a",kind="prefix"} 2
+errchain_issues{package="example.com/a",kind="wrap-verb"} 1
+# TYPE errchain_packages gauge
+# HELP errchain_packages Number of analyzed packages.
+errchain_packages 3
+# TYPE errchain_packages_with_issues gauge
+# HELP errchain_packages_with_issues Number of packages with at least one issue.
+errchain_packages_with_issues 2
+# TYPE errchain_analysis_errors gauge
+# HELP errchain_analysis_errors Number of packages the analysis failed for.
+errchain_analysis_errors 1
+# TYPE errchain_duration_seconds gauge
+# UNIT errchain_duration_seconds seconds
+# HELP errchain_duration_seconds Duration of the run.
+errchain_duration_seconds 1.234
+# EOF
+`
+	if string(got) != want {
+		t.Errorf("metrics:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestLabelValue(t *testing.T) {
+	for s, want := range map[string]string{
+		"example.com/a": `"example.com/a"`,
+		`a\b"c"`:        `"a\\b\"c\""`,
+		"line\nbreak":   `"line\nbreak"`,
+	} {
+		if got := labelValue(s); got != want {
+			t.Errorf("labelValue(%q) = %s, want %s", s, got, want)
+		}
+	}
+}
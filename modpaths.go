@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+)
+
+func init() {
+	// The flag is handled by runDriver; it is registered only to appear in the usage.
+	flag.Bool("module-paths", false, "write the positions of the json and lens formats and of -json relative to "+
+		"the root of the module, e.g. store/db.go:12:10, so the outputs and baselines built of them don't depend "+
+		"on where the module is checked out")
+}
+
+// modulePaths rewrites the file names of positions to slash-separated paths relative to the root of the module
+// of the file, the directory of the nearest go.mod. A file outside any module keeps its name.
+// The roots are cached by directory, since the diagnostics of a package share it.
+type modulePaths struct {
+	roots map[string]string // directory -> module root, "" if there is none
+}
+
+func newModulePaths() *modulePaths {
+	return &modulePaths{roots: make(map[string]string)}
+}
+
+// file returns the path of a file relative to the root of its module.
+func (m *modulePaths) file(filename string) string {
+	if !filepath.IsAbs(filename) {
+		return filename
+	}
+	root := m.root(filepath.Dir(filename))
+	if root == "" {
+		return filename
+	}
+	rel, err := filepath.Rel(root, filename)
+	if err != nil {
+		return filename
+	}
+	return filepath.ToSlash(rel)
+}
+
+// root returns the module root of a directory or "" if it isn't in a module.
+func (m *modulePaths) root(dir string) string {
+	root, ok := m.roots[dir]
+	if ok {
+		return root
+	}
+	if info, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil && !info.IsDir() {
+		root = dir
+	} else if parent := filepath.Dir(dir); parent != dir {
+		root = m.root(parent)
+	}
+	m.roots[dir] = root
+	return root
+}
+
+// posn rewrites the file name of a "file:line:col" position, keeping the line and the column as they are written.
+func (m *modulePaths) posn(posn string) string {
+	file, _, _ := splitPosn(posn)
+	return m.file(file) + posn[len(file):]
+}
+
+// fixes rewrites the file names of the edits of suggested fixes as the checker prints them with -json,
+// {"message": ..., "edits": [{"filename": ..., "start": ..., "end": ..., "new": ...}]}, keeping the other fields.
+// A fix it can't decode is kept as is.
+func (m *modulePaths) fixes(fixes []json.RawMessage) []json.RawMessage {
+	rewritten := make([]json.RawMessage, 0, len(fixes))
+	for _, raw := range fixes {
+		var fix map[string]json.RawMessage
+		var edits []map[string]json.RawMessage
+		if json.Unmarshal(raw, &fix) != nil || json.Unmarshal(fix["edits"], &edits) != nil {
+			rewritten = append(rewritten, raw)
+			continue
+		}
+		for _, edit := range edits {
+			var filename string
+			if json.Unmarshal(edit["filename"], &filename) == nil {
+				edit["filename"], _ = json.Marshal(m.file(filename))
+			}
+		}
+		fix["edits"], _ = json.Marshal(edits)
+		data, err := json.Marshal(fix)
+		if err != nil {
+			data = raw
+		}
+		rewritten = append(rewritten, data)
+	}
+	return rewritten
+}
+
+// rewrite rewrites the positions and the suggested fixes of all the diagnostics of the set.
+func (m *modulePaths) rewrite(set *diagnosticSet) {
+	for _, results := range set.diags {
+		for _, diags := range results {
+			for i := range diags {
+				diags[i].Posn = m.posn(diags[i].Posn)
+				if len(diags[i].SuggestedFixes) > 0 {
+					diags[i].SuggestedFixes = m.fixes(diags[i].SuggestedFixes)
+				}
+			}
+		}
+	}
+}
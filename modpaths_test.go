@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testModule creates a module with a nested module and a directory outside of both, returning the root
+// of the temporary directory they are in.
+func testModule(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range []string{"m/go.mod", "m/store/db.go", "m/nested/go.mod", "m/nested/x.go", "loose/a.go"} {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestModulePathsPosn(t *testing.T) {
+	dir := testModule(t)
+	m := newModulePaths()
+	for posn, want := range map[string]string{
+		filepath.Join(dir, "m", "store", "db.go") + ":12:10": "store/db.go:12:10",
+		filepath.Join(dir, "m", "store", "db.go") + ":12":    "store/db.go:12",
+		filepath.Join(dir, "m", "store", "db.go"):            "store/db.go",
+		// the nearest go.mod is the root
+		filepath.Join(dir, "m", "nested", "x.go") + ":1:1": "x.go:1:1",
+		// the files outside any module and relative names are kept
+		filepath.Join(dir, "loose", "a.go") + ":1:1": filepath.Join(dir, "loose", "a.go") + ":1:1",
+		"store/db.go:3:4": "store/db.go:3:4",
+	} {
+		if got := m.posn(posn); got != want {
+			t.Errorf("posn(%q) = %q, want %q", posn, got, want)
+		}
+	}
+}
+
+func TestModulePathsRewrite(t *testing.T) {
+	dir := testModule(t)
+	filename := filepath.Join(dir, "m", "store", "db.go")
+	fix := func(filename string) json.RawMessage {
+		data, err := json.Marshal(map[string]interface{}{
+			"message": "Add the prefix",
+			"edits":   []map[string]interface{}{{"filename": filename, "start": 10, "end": 10, "new": "store.Get: "}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	set := testSet(t, map[string][]jsonDiagnostic{
+		"example.com/m/store": {{
+			Posn:           filename + ":2:9",
+			Message:        "message",
+			SuggestedFixes: []json.RawMessage{fix(filename), json.RawMessage(`"not a fix"`)},
+		}},
+	})
+	newModulePaths().rewrite(set)
+
+	d := set.sorted()[0]
+	if d.Posn != "store/db.go:2:9" {
+		t.Errorf("posn = %q, want store/db.go:2:9", d.Posn)
+	}
+	var got, want interface{}
+	if err := json.Unmarshal(d.SuggestedFixes[0], &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(fix("store/db.go"), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fix = %s, want the edit of store/db.go keeping the other fields", d.SuggestedFixes[0])
+	}
+	if string(d.SuggestedFixes[1]) != `"not a fix"` {
+		t.Errorf("fix it can't decode = %s, want it kept", d.SuggestedFixes[1])
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+func init() {
+	// The flag is handled by runDriver; it is registered only to appear in the usage.
+	flag.String("overrides", "", "file of path-scoped flags: every line is an import path pattern like "+
+		"internal/experimental/** followed by flags applied to the matching packages, e.g. -pkg-component=optional")
+}
+
+// A section is a line of an overrides file: flags applied to the packages matching a pattern.
+type section struct {
+	pattern string
+	flags   []string
+	line    int // the line of the file, for messages
+}
+
+// readOverrides reads an overrides file. Every line is an import path pattern followed by flags separated
+// by spaces; empty lines and lines starting with # are skipped:
+//
+//	# relaxed rules for experiments
+//	internal/experimental/** -pkg-component=optional -propagation=false
+//	pkg/api/** -func-component=required -recv-component=required
+func readOverrides(filename string) ([]section, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("readOverrides: %w", err)
+	}
+	defer f.Close()
+
+	var sections []section
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if _, err := path.Match(fields[0], ""); err != nil {
+			return nil, fmt.Errorf("readOverrides: %s:%d: bad pattern %q: %w", filename, n, fields[0], err)
+		}
+		for _, flag := range fields[1:] {
+			if !strings.HasPrefix(flag, "-") || !strings.Contains(flag, "=") {
+				return nil, fmt.Errorf("readOverrides: %s:%d: %q must be -name=value", filename, n, flag)
+			}
+		}
+		sections = append(sections, section{pattern: fields[0], flags: fields[1:], line: n})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("readOverrides: %w", err)
+	}
+	return sections, nil
+}
+
+// matchSection tells whether an import path matches a pattern of a section. A pattern ending with "/**"
+// matches the packages of a directory tree found anywhere in the path, e.g. internal/experimental/**
+// matches example.com/m/internal/experimental/x/y. Other patterns are matched against the trailing elements
+// of the path as -qualified patterns are.
+func matchSection(pattern, importPath string) bool {
+	elems := strings.Split(importPath, "/")
+	if dir := strings.TrimSuffix(pattern, "/**"); dir != pattern {
+		n := strings.Count(dir, "/") + 1
+		for i := 0; i+n <= len(elems); i++ {
+			if ok, _ := path.Match(dir, strings.Join(elems[i:i+n], "/")); ok {
+				return true
+			}
+		}
+		return false
+	}
+	n := strings.Count(pattern, "/") + 1
+	if len(elems) > n {
+		elems = elems[len(elems)-n:]
+	}
+	ok, _ := path.Match(pattern, strings.Join(elems, "/"))
+	return ok
+}
+
+// check runs the checker once or, if there are sections, once per group of packages sharing the overrides.
+func check(env []string, sections []section, args []string) (jsonTree, error) {
+	if len(sections) == 0 {
+		return runChecker(env, args)
+	}
+	return runSections(env, sections, args)
+}
+
+// runSections runs the checker once per distinct set of overrides applying to the packages of the arguments,
+// with the flags of all the matching sections following the command line flags in the order of the file,
+// so later sections win. The outputs are merged, since every package is analyzed by one run only.
+func runSections(env []string, sections []section, args []string) (jsonTree, error) {
+	flags, pkgPatterns := splitArgs(args)
+	packages, err := listPackages(pkgPatterns)
+	if err != nil {
+		return nil, fmt.Errorf("runSections: %w", err)
+	}
+
+	var keys []string
+	groups := make(map[string][]string)
+	overrides := make(map[string][]string)
+	for _, pkg := range packages {
+		var applied []string
+		for _, s := range sections {
+			if matchSection(s.pattern, pkg) {
+				applied = append(applied, s.flags...)
+			}
+		}
+		key := strings.Join(applied, " ")
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+			overrides[key] = applied
+		}
+		groups[key] = append(groups[key], pkg)
+	}
+
+	tree := make(jsonTree)
+	for _, key := range keys {
+		runArgs := append(append(append([]string{}, flags...), overrides[key]...), groups[key]...)
+		group, err := runChecker(env, runArgs)
+		if err != nil {
+			return nil, fmt.Errorf("runSections: %w", err)
+		}
+		for id, results := range group {
+			tree[id] = results
+		}
+	}
+	return tree, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadOverrides(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "overrides")
+	src := `# relaxed rules for experiments
+internal/experimental/** -pkg-component=optional -propagation=false
+
+pkg/api/**   -func-component=required
+  # an indented comment
+cmd/*
+`
+	if err := os.WriteFile(filename, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readOverrides(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []section{
+		{pattern: "internal/experimental/**", flags: []string{"-pkg-component=optional", "-propagation=false"}, line: 2},
+		{pattern: "pkg/api/**", flags: []string{"-func-component=required"}, line: 4},
+		{pattern: "cmd/*", flags: []string{}, line: 6},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readOverrides() = %+v, want %+v", got, want)
+	}
+}
+
+func TestReadOverridesErrors(t *testing.T) {
+	for src, want := range map[string]string{
+		"pkg/[a -propagation=false\n":         `:1: bad pattern "pkg/[a"`,
+		"# flags\npkg/** propagation=false\n": `:2: "propagation=false" must be -name=value`,
+		"pkg/** -propagation\n":               `:1: "-propagation" must be -name=value`,
+	} {
+		filename := filepath.Join(t.TempDir(), "overrides")
+		if err := os.WriteFile(filename, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readOverrides(filename); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("readOverrides(%q) error = %v, want %s", src, err, want)
+		}
+	}
+	if _, err := readOverrides(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("readOverrides of a missing file succeeded")
+	}
+}
+
+func TestMatchSection(t *testing.T) {
+	for _, tt := range []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"internal/experimental/**", "example.com/m/internal/experimental", true},
+		{"internal/experimental/**", "example.com/m/internal/experimental/x/y", true},
+		{"internal/experimental/**", "example.com/m/internal/experimentalx", false},
+		{"internal/*/**", "example.com/m/internal/beta/x", true},
+		{"storage", "example.com/m/storage", true},
+		{"storage", "example.com/m/storage/util", false},
+		{"storage/*", "example.com/m/storage/util", true},
+		{"cmd/*", "cmd", false},
+		{"example.com/m", "example.com/m", true},
+	} {
+		if got := matchSection(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchSection(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+// Package err113 defines an Analyzer that checks that errors are compared with errors.Is instead of ==.
+// It is a minimal version of github.com/Djarvur/go-err113 without the rule against dynamic errors,
+// which contradicts prefixed messages like errors.New("pkg.Func: not found").
+package err113
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "err113",
+	Doc:      "Checks that errors are compared with errors.Is since they may be wrapped.",
+	Run:      run,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodeFilter := []ast.Node{(*ast.BinaryExpr)(nil)}
+
+	insp.Preorder(nodeFilter, func(node ast.Node) {
+		expr := node.(*ast.BinaryExpr)
+		if expr.Op != token.EQL && expr.Op != token.NEQ {
+			return
+		}
+		if isNil(pass, expr.X) || isNil(pass, expr.Y) {
+			return
+		}
+		if isError(pass.TypesInfo.TypeOf(expr.X)) && isError(pass.TypesInfo.TypeOf(expr.Y)) {
+			pass.Reportf(expr.Pos(), "do not compare errors directly %s, use errors.Is since they may be wrapped",
+				exprString(expr))
+		}
+	})
+	return nil, nil
+}
+
+func exprString(expr *ast.BinaryExpr) string {
+	x, okX := expr.X.(*ast.Ident)
+	y, okY := expr.Y.(*ast.Ident)
+	if !okX || !okY {
+		return "with " + expr.Op.String()
+	}
+	return x.Name + " " + expr.Op.String() + " " + y.Name
+}
+
+func isNil(pass *analysis.Pass, expr ast.Expr) bool {
+	tv, ok := pass.TypesInfo.Types[expr]
+	return ok && tv.IsNil()
+}
+
+func isError(t types.Type) bool {
+	return t != nil && types.Identical(t, types.Universe.Lookup("error").Type())
+}
@@ -0,0 +1,22 @@
+package compare
+
+import (
+	"errors"
+	"io"
+)
+
+var ErrNotFound = errors.New("compare: not found")
+
+func IsEOF(err error) bool {
+	if err == nil {
+		return false
+	}
+	if err != ErrNotFound { // want `do not compare errors directly err != ErrNotFound, use errors.Is since they may be wrapped`
+		return false
+	}
+	return err == io.EOF // want `do not compare errors directly with ==, use errors.Is since they may be wrapped`
+}
+
+func Is(err error) bool {
+	return errors.Is(err, io.EOF)
+}
@@ -0,0 +1,123 @@
+// Package multiwrap defines an Analyzer that checks fmt.Errorf calls with several %w verbs.
+// Before Go 1.20 fmt.Errorf wraps only one error: the extra %w verbs are bad verbs rendered
+// like %!w(*errors.errorString=&{closed}) and their errors can't be found by errors.Is and errors.As.
+// The Go version is taken from the go directive of the module's go.mod file.
+package multiwrap
+
+import (
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/iimos/go-check-err-chains/internal/fmtverb"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "multiwrap",
+	Doc:      "Checks that fmt.Errorf wraps several errors only if the module requires Go 1.20 or later.",
+	Run:      run,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+}
+
+// goVersion overrides the Go version of the go.mod file, e.g. for packages outside of modules.
+var goVersion string
+
+func init() {
+	Analyzer.Flags.StringVar(&goVersion, "go", "",
+		"Go version like 1.19 to check against instead of the go directive of the module")
+}
+
+// multiWrapMinor is the minor version of Go 1.20 which made fmt.Errorf wrap every %w operand.
+const multiWrapMinor = 20
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	if len(pass.Files) == 0 {
+		return nil, nil
+	}
+	version := goVersion
+	if version == "" {
+		version = moduleGoVersion(pass.Fset.Position(pass.Files[0].Package).Filename)
+	}
+	minor, ok := minorVersion(version)
+	if !ok || minor >= multiWrapMinor {
+		// several wrapped errors are fine, or the version is unknown
+		return nil, nil
+	}
+
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodeFilter := []ast.Node{(*ast.CallExpr)(nil)}
+
+	insp.Preorder(nodeFilter, func(node ast.Node) {
+		call := node.(*ast.CallExpr)
+		if len(call.Args) < 3 || !isErrorf(pass, call) {
+			return
+		}
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return
+		}
+		format, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return
+		}
+		verbs, _ := fmtverb.Parse(format)
+		wraps := 0
+		for _, verb := range verbs {
+			if verb.Verb == 'w' {
+				wraps++
+			}
+		}
+		if wraps > 1 {
+			pass.Reportf(call.Pos(), "fmt.Errorf wraps only one error before Go 1.20, but the module requires go %s: "+
+				"the extra %%w verbs are rendered as %%!w(...), use a single %%w or upgrade the go directive", version)
+		}
+	})
+	return nil, nil
+}
+
+// moduleGoVersion returns the version set by the go directive of the go.mod file of the module
+// a file belongs to or an empty string if there is no go.mod file or no go directive.
+func moduleGoVersion(filename string) string {
+	for dir := filepath.Dir(filename); ; {
+		if data, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "go" {
+					return fields[1]
+				}
+			}
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// minorVersion returns the minor version of a Go 1 version like "1.19", "1.21.3" or "go1.20".
+func minorVersion(version string) (int, bool) {
+	version = strings.TrimPrefix(version, "go")
+	if !strings.HasPrefix(version, "1.") {
+		return 0, false
+	}
+	minor := strings.TrimPrefix(version, "1.")
+	if i := strings.IndexAny(minor, ".rcbeta"); i >= 0 {
+		minor = minor[:i]
+	}
+	n, err := strconv.Atoi(minor)
+	return n, err == nil
+}
+
+// isErrorf tells whether a call is a call of fmt.Errorf.
+func isErrorf(pass *analysis.Pass, call *ast.CallExpr) bool {
+	fn := typeutil.StaticCallee(pass.TypesInfo, call)
+	return fn != nil && fn.Pkg() != nil && fn.Pkg().Path() == "fmt" && fn.Name() == "Errorf"
+}
@@ -0,0 +1,15 @@
+package current
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrClosed  = errors.New("current: closed")
+	ErrTimeout = errors.New("current: timeout")
+)
+
+func Close() error {
+	return fmt.Errorf("current.Close: %w, %w", ErrClosed, ErrTimeout)
+}
@@ -0,0 +1,3 @@
+module example.com/current
+
+go 1.21.0
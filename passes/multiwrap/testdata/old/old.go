@@ -0,0 +1,21 @@
+package old
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrClosed  = errors.New("old: closed")
+	ErrTimeout = errors.New("old: timeout")
+)
+
+func Close(err error) error {
+	switch {
+	case err == nil:
+		return fmt.Errorf("old.Close: %w, %w", ErrClosed, ErrTimeout) // want `fmt.Errorf wraps only one error before Go 1.20, but the module requires go 1.19: the extra %w verbs are rendered as %!w\(\.\.\.\), use a single %w or upgrade the go directive`
+	case errors.Is(err, ErrTimeout):
+		return fmt.Errorf("old.Close: %w: %w: %w", ErrClosed, ErrTimeout, err) // want `fmt.Errorf wraps only one error before Go 1.20`
+	}
+	return fmt.Errorf("old.Close: %w: %v", ErrClosed, err)
+}
@@ -0,0 +1,43 @@
+package wrap
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+func Open(name string) (*os.File, error) {
+	return os.Open(name) // want `error returned from external package is unwrapped: os.Open`
+}
+
+func Atoi(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err // want `error returned from external package is unwrapped: strconv.Atoi`
+	}
+	if _, err := strconv.Atoi(s); err != nil {
+		return 0, fmt.Errorf("wrap.Atoi: %w", err)
+	}
+	return n, nil
+}
+
+func Read(r io.Reader, buf []byte) error {
+	_, err := r.Read(buf)
+	return err // want `error returned from external package is unwrapped: \(io.Reader\).Read`
+}
+
+func local() error {
+	return errors.New("wrap.local: failed")
+}
+
+func Local() error {
+	f := func() error {
+		return os.Remove("x") // want `error returned from external package is unwrapped: os.Remove`
+	}
+	if err := f(); err != nil {
+		return err
+	}
+	return local()
+}
@@ -0,0 +1,137 @@
+// Package wrapcheck defines an Analyzer that checks that errors returned from other packages are wrapped.
+// It is a minimal version of github.com/tomarrell/wrapcheck: an error returned as is from another package
+// doesn't tell where it passed through.
+package wrapcheck
+
+import (
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"github.com/iimos/go-check-err-chains/internal/errflow"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// Analyzer checks that errors returned from other packages are wrapped, see New.
+var Analyzer = New()
+
+// defaultIgnoreSigs are prefixes of full names of functions whose errors may be returned as is:
+// error constructors and wrappers.
+const defaultIgnoreSigs = "errors.New,errors.Join,fmt.Errorf,go.uber.org/multierr."
+
+// A checker holds the settings of an analyzer, which its flags set.
+type checker struct {
+	ignoreSigs string
+}
+
+// New returns an analyzer with its own settings, so several of them configured differently may run
+// in one process.
+func New() *analysis.Analyzer {
+	c := &checker{ignoreSigs: defaultIgnoreSigs}
+	a := &analysis.Analyzer{
+		Name:     "wrapcheck",
+		Doc:      "Checks that errors returned from other packages are wrapped.",
+		Run:      c.run,
+		Requires: []*analysis.Analyzer{inspect.Analyzer},
+	}
+	a.Flags.StringVar(&c.ignoreSigs, "ignore-sigs", defaultIgnoreSigs,
+		"comma-separated prefixes of functions like pkg/path.Func whose errors may be returned as is")
+	return a
+}
+
+func (c *checker) run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodeFilter := []ast.Node{(*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)}
+
+	insp.Preorder(nodeFilter, func(node ast.Node) {
+		var body *ast.BlockStmt
+		switch node := node.(type) {
+		case *ast.FuncDecl:
+			body = node.Body
+		case *ast.FuncLit:
+			body = node.Body
+		}
+		if body != nil {
+			c.checkBody(pass, body)
+		}
+	})
+	return nil, nil
+}
+
+// checkBody checks the return statements of a function body, but not of the function literals inside it.
+func (c *checker) checkBody(pass *analysis.Pass, body *ast.BlockStmt) {
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			for _, result := range node.Results {
+				if call := c.unwrappedCall(pass, body, result); call != "" {
+					pass.Reportf(result.Pos(), "error returned from external package is unwrapped: %s", call)
+				}
+			}
+		}
+		return true
+	})
+}
+
+// unwrappedCall returns a name of a function of another package if a result is its error returned as is:
+// either a call or a variable last assigned from the call before the use.
+func (c *checker) unwrappedCall(pass *analysis.Pass, body *ast.BlockStmt, result ast.Expr) string {
+	switch result := astutil.Unparen(result).(type) {
+	case *ast.CallExpr:
+		if returnsError(pass.TypesInfo.TypeOf(result)) {
+			return c.externalCallee(pass, result)
+		}
+	case *ast.Ident:
+		if !isError(pass.TypesInfo.TypeOf(result)) {
+			return ""
+		}
+		if call := errflow.LastAssignedCall(pass.TypesInfo, body, result); call != nil {
+			return c.externalCallee(pass, call)
+		}
+	}
+	return ""
+}
+
+// externalCallee returns a full name of a function or an interface method of another package called
+// unless it is ignored.
+func (c *checker) externalCallee(pass *analysis.Pass, call *ast.CallExpr) string {
+	callee, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || callee.Pkg() == nil {
+		return ""
+	}
+	sig := callee.Type().(*types.Signature)
+	isInterface := sig.Recv() != nil && types.IsInterface(sig.Recv().Type())
+	if callee.Pkg() == pass.Pkg && !isInterface {
+		return ""
+	}
+	name := callee.FullName()
+	for _, prefix := range strings.Split(c.ignoreSigs, ",") {
+		if prefix = strings.TrimSpace(prefix); prefix != "" && strings.HasPrefix(name, prefix) {
+			return ""
+		}
+	}
+	return name
+}
+
+// returnsError tells whether a type of a call result is an error or a tuple containing an error.
+func returnsError(t types.Type) bool {
+	if tuple, ok := t.(*types.Tuple); ok {
+		for i := 0; i < tuple.Len(); i++ {
+			if isError(tuple.At(i).Type()) {
+				return true
+			}
+		}
+		return false
+	}
+	return t != nil && isError(t)
+}
+
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
@@ -0,0 +1,26 @@
+package tail
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrNotFound = errors.New("tail: not found")
+
+func Read(path string, err error) error {
+	switch path {
+	case "":
+		return fmt.Errorf("read %w failed for %s", err, path) // want `wrapped error is in the middle of the message, put it at the end: "context: %w"`
+	case "-":
+		return fmt.Errorf("read stdin: %w (%d retries)", err, 3) // want `wrapped error is in the middle of the message`
+	case ".":
+		return fmt.Errorf("read %s (%w)", path, err) // want `wrapped error is in the middle of the message`
+	case "/":
+		return fmt.Errorf("%w: %s", ErrNotFound, path)
+	case "~":
+		return fmt.Errorf("read %s: %w", path, err)
+	case "*":
+		return fmt.Errorf("read %[1]s %[2]w again %[1]s", path, err)
+	}
+	return fmt.Errorf("read %s: %w, %w", path, err, ErrNotFound)
+}
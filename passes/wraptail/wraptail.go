@@ -0,0 +1,116 @@
+// Package wraptail defines an Analyzer that checks that a wrapped error is put at the end of the message:
+// fmt.Errorf("read config %s: %w", path, err) rather than fmt.Errorf("read %w failed for %s", err, path).
+// The conventional trailing form keeps error chains readable as a sequence of "context: cause" parts.
+// Messages starting with the wrapped error, like fmt.Errorf("%w: id %d", ErrNotFound, id), are accepted.
+package wraptail
+
+import (
+	"bytes"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"strconv"
+	"strings"
+
+	"github.com/iimos/go-check-err-chains/internal/fmtverb"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "wraptail",
+	Doc:      "Checks that the %w verb of fmt.Errorf is at the end of the message.",
+	Run:      run,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodeFilter := []ast.Node{(*ast.CallExpr)(nil)}
+
+	insp.Preorder(nodeFilter, func(node ast.Node) {
+		call := node.(*ast.CallExpr)
+		if len(call.Args) < 2 || call.Ellipsis.IsValid() || !isErrorf(pass, call) {
+			return
+		}
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return
+		}
+		format, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return
+		}
+		verbs, ok := fmtverb.Parse(format)
+		if !ok || len(verbs) != len(call.Args)-1 {
+			return
+		}
+
+		var wrap *fmtverb.Verb
+		for i := range verbs {
+			if verbs[i].Verb == 'w' {
+				if wrap != nil {
+					// several wrapped errors have no single place at the end
+					return
+				}
+				wrap = &verbs[i]
+			}
+		}
+		if wrap == nil || wrap.Start == 0 || wrap.End == len(format) {
+			return
+		}
+
+		diag := analysis.Diagnostic{
+			Pos:     call.Pos(),
+			Message: "wrapped error is in the middle of the message, put it at the end: \"context: %w\"",
+		}
+		if fix, ok := trailingWrapFix(pass, call, lit, format, *wrap); ok {
+			diag.SuggestedFixes = []analysis.SuggestedFix{fix}
+		}
+		pass.Report(diag)
+	})
+	return nil, nil
+}
+
+// trailingWrapFix moves the %w verb to the end of the format and its argument to the end of the arguments:
+// fmt.Errorf("read %w failed for %s", err, path) becomes fmt.Errorf("read failed for %s: %w", path, err).
+// A verb enclosed in brackets or quotes isn't moved since the rest of the message would be broken.
+func trailingWrapFix(pass *analysis.Pass, call *ast.CallExpr, lit *ast.BasicLit, format string,
+	wrap fmtverb.Verb) (analysis.SuggestedFix, bool) {
+	before, after := format[:wrap.Start], format[wrap.End:]
+	if strings.ContainsAny(before[len(before)-1:], "([{'\"") {
+		return analysis.SuggestedFix{}, false
+	}
+	if strings.HasPrefix(after, " ") {
+		// "read stdin: %w (3 retries)" loses the separator preceding the verb as well
+		before = strings.TrimRight(before, " :;,-")
+	}
+	rest := strings.TrimRight(before+after, " :;,-")
+
+	edits := []analysis.TextEdit{{
+		Pos:     lit.Pos(),
+		End:     lit.End(),
+		NewText: []byte(strconv.Quote(rest + ": %w")),
+	}}
+	argIndex := wrap.Arg + 1
+	if last := len(call.Args) - 1; argIndex != last {
+		arg := call.Args[argIndex]
+		var buf bytes.Buffer
+		if err := printer.Fprint(&buf, pass.Fset, arg); err != nil {
+			return analysis.SuggestedFix{}, false
+		}
+		edits = append(edits,
+			analysis.TextEdit{Pos: call.Args[argIndex-1].End(), End: arg.End()},
+			analysis.TextEdit{Pos: call.Args[last].End(), End: call.Args[last].End(), NewText: []byte(", " + buf.String())},
+		)
+	}
+	return analysis.SuggestedFix{Message: "Move the wrapped error to the end", TextEdits: edits}, true
+}
+
+// isErrorf tells whether a call is a call of fmt.Errorf.
+func isErrorf(pass *analysis.Pass, call *ast.CallExpr) bool {
+	fn := typeutil.StaticCallee(pass.TypesInfo, call)
+	return fn != nil && fn.Pkg() != nil && fn.Pkg().Path() == "fmt" && fn.Name() == "Errorf"
+}
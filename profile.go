@@ -0,0 +1,38 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// profileFlags are the checker flags writing profiles. The checker handles them itself, but when the driver
+// runs several checker processes every process needs its own file.
+var profileFlags = []string{"cpuprofile", "memprofile", "trace"}
+
+// extractProfileFlags removes the profiling flags from the command line arguments.
+func extractProfileFlags(args []string) (profiles map[string]string, rest []string) {
+	profiles = make(map[string]string)
+	rest = args
+	for _, name := range profileFlags {
+		var value string
+		var ok bool
+		if value, rest, ok = extractFlag(rest, name); ok && value != "" {
+			profiles[name] = value
+		}
+	}
+	return profiles, rest
+}
+
+// profileArgs returns the profiling flags for a child process with the label inserted into the file names,
+// e.g. cpu.prof becomes cpu.linux_amd64.prof.
+func profileArgs(profiles map[string]string, label string) []string {
+	label = strings.NewReplacer("/", "_", "\\", "_").Replace(label)
+	args := make([]string, 0, len(profiles))
+	for name, file := range profiles {
+		ext := filepath.Ext(file)
+		args = append(args, "-"+name+"="+strings.TrimSuffix(file, ext)+"."+label+ext)
+	}
+	sort.Strings(args)
+	return args
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractProfileFlags(t *testing.T) {
+	for _, tt := range []struct {
+		args     []string
+		profiles map[string]string
+		rest     []string
+	}{
+		{[]string{"./..."}, map[string]string{}, []string{"./..."}},
+		{[]string{"-cpuprofile=cpu.prof", "-json", "./..."}, map[string]string{"cpuprofile": "cpu.prof"}, []string{"-json", "./..."}},
+		{[]string{"--memprofile", "mem.prof", "-trace=trace.out", "./..."},
+			map[string]string{"memprofile": "mem.prof", "trace": "trace.out"}, []string{"./..."}},
+		// an empty file name disables the profile
+		{[]string{"-cpuprofile=", "./..."}, map[string]string{}, []string{"./..."}},
+		{[]string{"./...", "--", "-trace=trace.out"}, map[string]string{}, []string{"./...", "--", "-trace=trace.out"}},
+	} {
+		profiles, rest := extractProfileFlags(tt.args)
+		if !reflect.DeepEqual(profiles, tt.profiles) || !reflect.DeepEqual(rest, tt.rest) {
+			t.Errorf("extractProfileFlags(%q) = %q, %q, want %q, %q", tt.args, profiles, rest, tt.profiles, tt.rest)
+		}
+	}
+}
+
+func TestProfileArgs(t *testing.T) {
+	for _, tt := range []struct {
+		profiles map[string]string
+		label    string
+		want     []string
+	}{
+		{map[string]string{}, "linux/amd64", []string{}},
+		{map[string]string{"cpuprofile": "cpu.prof"}, "linux/amd64", []string{"-cpuprofile=cpu.linux_amd64.prof"}},
+		// the flags are sorted
+		{map[string]string{"trace": "out/trace", "memprofile": "/tmp/mem.prof"}, `windows\arm64`,
+			[]string{"-memprofile=/tmp/mem.windows_arm64.prof", "-trace=out/trace.windows_arm64"}},
+	} {
+		if got := profileArgs(tt.profiles, tt.label); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("profileArgs(%q, %q) = %q, want %q", tt.profiles, tt.label, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// runRename rewrites error prefixes after a function, method or type has been renamed:
+// string literals starting with the old location and op constants equal to it get the new location.
+func runRename(args []string) int {
+	flags := flag.NewFlagSet("rename", flag.ContinueOnError)
+	from := flags.String("from", "", "old location, e.g. pkg.Old or pkg.Type.Method")
+	to := flags.String("to", "", "new location, e.g. pkg.New")
+	sep := flags.String("separator", ":", "separator following the location in error messages, e.g. \" - \"")
+	dryRun := flags.Bool("n", false, "print the changes without writing the files")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: errchain rename -from pkg.Old -to pkg.New [-separator sep] [-n] [package]...\n\n")
+		fmt.Fprintf(os.Stderr, "Rewrites error prefixes and op constants referring to the old location.\n\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	if exitcode, ok := parseFlags(flags, args); !ok {
+		return exitcode
+	}
+
+	if *sep == "" {
+		fmt.Fprintln(os.Stderr, "errchain rename: -separator must not be empty")
+		flags.Usage()
+		return exitConfig
+	}
+	if !isLocation(*from) || !isLocation(*to) {
+		fmt.Fprintln(os.Stderr, "errchain rename: -from and -to must be locations like pkg.Func or pkg.Type.Method")
+		flags.Usage()
+		return exitConfig
+	}
+
+	patterns := flags.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	files, err := goFiles(patterns)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "errchain rename: %v\n", err)
+		return exitError
+	}
+
+	r := renamer{from: *from, to: *to, separator: *sep}
+	for _, file := range files {
+		if err := r.renameFile(file, *dryRun); err != nil {
+			fmt.Fprintf(os.Stderr, "errchain rename: %v\n", err)
+			return exitError
+		}
+	}
+	return exitOK
+}
+
+// isLocation tells whether s looks like a dot-separated location, e.g. pkg.Type.Method.
+func isLocation(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, part := range strings.Split(s, ".") {
+		if !token.IsIdentifier(part) {
+			return false
+		}
+	}
+	return true
+}
+
+type renamer struct {
+	from      string
+	to        string
+	separator string
+}
+
+// renameFile rewrites the error messages and op constants of a file referring to the old location. Only the files
+// of the package of the location, including its external tests, are rewritten and only the literals error messages
+// start with: the messages of the constructors errorConstructors lists, the strings they are concatenated with
+// and the values of constants and variables equal to the location. The file keeps its mode.
+func (r renamer) renameFile(filename string, dryRun bool) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.SkipObjectResolution)
+	if err != nil {
+		return err
+	}
+	if pkg := r.from[:strings.Index(r.from, ".")]; strings.TrimSuffix(file.Name.Name, "_test") != pkg {
+		return nil
+	}
+
+	type edit struct {
+		start, end int
+		text       string
+	}
+	var edits []edit
+	for _, lit := range messageLiterals(file) {
+		old, renamed, ok := r.rename(lit.value.Value[1:], lit.whole)
+		if !ok {
+			continue
+		}
+		start := fset.Position(lit.value.Pos()).Offset + 1
+		edits = append(edits, edit{start: start, end: start + len(old), text: renamed})
+		fmt.Printf("%s: %s -> %s\n", fset.Position(lit.value.Pos()), old, renamed)
+	}
+	if len(edits) == 0 || dryRun {
+		return nil
+	}
+
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	for _, e := range edits {
+		src = append(src[:e.start:e.start], append([]byte(e.text), src[e.end:]...)...)
+	}
+	return os.WriteFile(filename, src, info.Mode().Perm())
+}
+
+// A messageLiteral is a string literal an error message may start with.
+type messageLiteral struct {
+	value *ast.BasicLit
+	whole bool // the literal must be the location as a whole, as the value of an op constant is
+}
+
+// messageLiterals returns the literals of a file error messages may start with: the leading literals
+// of the messages of error constructor calls, e.g. errors.New("pkg.Old: msg") or errors.New("pkg.Old: " + msg),
+// and the values of constants and variables, e.g. const op = "pkg.Old", which must be the location as a whole.
+// Other strings, e.g. log messages or map keys, are left as they are even if they start with a location.
+func messageLiterals(file *ast.File) []messageLiteral {
+	ctors := fileConstructors(file)
+	var lits []messageLiteral
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.CallExpr:
+			if len(node.Args) > 0 && ctors[calleeName(node.Fun)] {
+				if lit := leadingLiteral(node.Args[0]); lit != nil {
+					lits = append(lits, messageLiteral{value: lit})
+				}
+			}
+		case *ast.ValueSpec:
+			for _, value := range node.Values {
+				if lit, ok := value.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					lits = append(lits, messageLiteral{value: lit, whole: true})
+				}
+			}
+		}
+		return true
+	})
+	return lits
+}
+
+// leadingLiteral returns the string literal a message starts with, e.g. "pkg.Old: " of "pkg.Old: " + msg,
+// or nil if it starts with something else.
+func leadingLiteral(msg ast.Expr) *ast.BasicLit {
+	for {
+		switch expr := msg.(type) {
+		case *ast.ParenExpr:
+			msg = expr.X
+		case *ast.BinaryExpr:
+			if expr.Op != token.ADD {
+				return nil
+			}
+			msg = expr.X
+		case *ast.BasicLit:
+			if expr.Kind != token.STRING || len(expr.Value) < 2 {
+				return nil
+			}
+			return expr
+		default:
+			return nil
+		}
+	}
+}
+
+// rename checks whether a literal body (a literal without the opening quote) starts with the old location
+// followed by a separator, a dot or the closing quote, and returns the old and the new text. If whole is set,
+// the body must be the location followed by the closing quote. The pointer form of a type, "pkg.(*Type).Method",
+// is renamed as well.
+func (r renamer) rename(body string, whole bool) (old, renamed string, ok bool) {
+	candidates := [][2]string{{r.from, r.to}}
+	if fromPtr, ok := pointerForm(r.from); ok {
+		toPtr, ok := pointerForm(r.to)
+		if !ok {
+			toPtr = r.to
+		}
+		candidates = append(candidates, [2]string{fromPtr, toPtr})
+	}
+
+	for _, c := range candidates {
+		if !strings.HasPrefix(body, c[0]) {
+			continue
+		}
+		rest := body[len(c[0]):]
+		// len(rest) == 1 means that only the closing quote is left: const op = "pkg.Old"
+		if len(rest) == 1 || !whole && (strings.HasPrefix(rest, r.separator) || strings.HasPrefix(rest, ".")) {
+			return c[0], c[1], true
+		}
+	}
+	return "", "", false
+}
+
+// pointerForm returns a location with the type in the pointer form: pkg.Type.Method becomes pkg.(*Type).Method.
+func pointerForm(loc string) (string, bool) {
+	parts := strings.Split(loc, ".")
+	if len(parts) < 2 {
+		return "", false
+	}
+	parts[1] = "(*" + parts[1] + ")"
+	return strings.Join(parts, "."), true
+}
+
+// goFiles returns Go files matching the patterns, which are either directories, files or directories
+// followed by "/..." to include all subdirectories. As the go command does, directories named testdata or vendor
+// and directories starting with "." or "_" are skipped during the recursive walk.
+func goFiles(patterns []string) ([]string, error) {
+	var files []string
+	for _, pattern := range patterns {
+		if dir := strings.TrimSuffix(pattern, "/..."); dir != pattern {
+			err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				if info.IsDir() {
+					name := info.Name()
+					if path != dir && (name == "testdata" || name == "vendor" ||
+						strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if strings.HasSuffix(path, ".go") {
+					files = append(files, path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		info, err := os.Stat(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, pattern)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(pattern, "*.go"))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRename(t *testing.T) {
+	for _, tt := range []struct {
+		file      string
+		from, to  string
+		separator string
+	}{
+		{"testdata/rename/store.go", "store.Store.Load", "store.Store.Get", ":"},
+		{"testdata/rename/separator/store.go", "store.Load", "store.Get", " - "},
+		{"testdata/rename/other/cache.go", "store.Store.Load", "store.Store.Get", ":"},
+	} {
+		t.Run(tt.file, func(t *testing.T) {
+			src, err := os.ReadFile(tt.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := os.ReadFile(tt.file + ".golden")
+			if err != nil {
+				t.Fatal(err)
+			}
+			filename := filepath.Join(t.TempDir(), filepath.Base(tt.file))
+			if err := os.WriteFile(filename, src, 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			r := renamer{from: tt.from, to: tt.to, separator: tt.separator}
+			if err := r.renameFile(filename, false); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("renamed file:\n%s\nwant:\n%s", got, want)
+			}
+			info, err := os.Stat(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != 0o600 {
+				t.Errorf("mode = %v, want the original -rw-------", info.Mode().Perm())
+			}
+		})
+	}
+}
+
+func TestRenameDryRun(t *testing.T) {
+	src, err := os.ReadFile("testdata/rename/store.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), "store.go")
+	if err := os.WriteFile(filename, src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := renamer{from: "store.Store.Load", to: "store.Store.Get", separator: ":"}
+	if err := r.renameFile(filename, true); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(src) {
+		t.Errorf("-n changed the file:\n%s", got)
+	}
+}
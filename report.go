@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iimos/go-check-err-chains/errchain"
+)
+
+func init() {
+	// The flags are handled by main before the checker starts; they are registered only to appear in the usage.
+	flag.String("format", "text", "output format: text, json, html (a standalone report for browsers) or lens "+
+		"(the prefix coverage of every function as JSON for code lenses of IDEs)")
+	flag.String("o", "", "write the output to a file instead of stdout")
+}
+
+// checkerValueFlags are the flags of the checker taking a value, which are registered only when it starts.
+var checkerValueFlags = []string{"c", "debug", "cpuprofile", "memprofile", "trace", "tags"}
+
+// checkerBoolFlags are the boolean flags of the checker.
+var checkerBoolFlags = []string{"json", "fix", "test", "flags", "source", "v", "all"}
+
+// snippetLines is the number of lines shown around a diagnostic in the HTML report.
+const snippetLines = 2
+
+// writeReport writes an HTML report of the diagnostics of the set and returns the exit code, see exitIssues.
+func writeReport(w io.Writer, set *diagnosticSet, packages []string, duration time.Duration) (exitcode int) {
+	for _, e := range set.errors {
+		fmt.Fprintln(os.Stderr, e)
+	}
+
+	report := newReport(set, packages)
+	report.Duration = duration.Round(time.Millisecond)
+	buf := bufio.NewWriter(w)
+	err := reportTemplate.Execute(buf, report)
+	if err == nil {
+		err = buf.Flush()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "errchain: write report: %v\n", err)
+		return exitError
+	}
+	return set.exitCode(report.Issues)
+}
+
+// patterns returns the package patterns of the command line arguments, "." if there are none.
+func patterns(args []string) []string {
+	_, pkgPatterns := splitArgs(args)
+	return pkgPatterns
+}
+
+// splitArgs splits the command line arguments into the flags and the package patterns, "." if there are none.
+func splitArgs(args []string) (flagArgs, pkgPatterns []string) {
+	flags := flag.NewFlagSet("errchain", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	errchain.Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		flags.Var(f.Value, f.Name, f.Usage)
+	})
+	flag.VisitAll(func(f *flag.Flag) {
+		flags.Var(f.Value, f.Name, f.Usage)
+	})
+	addCheckerFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return args, []string{"."}
+	}
+	if flags.NArg() == 0 {
+		return args, []string{"."}
+	}
+	return args[:len(args)-flags.NArg()], flags.Args()
+}
+
+// addCheckerFlags adds the flags of the checker to a flag set, so that they are accepted but ignored.
+func addCheckerFlags(flags *flag.FlagSet) {
+	for _, name := range checkerValueFlags {
+		if flags.Lookup(name) == nil {
+			flags.String(name, "", "")
+		}
+	}
+	for _, name := range checkerBoolFlags {
+		flags.Bool(name, false, "")
+	}
+}
+
+// listPackages returns the import paths of the packages matching the patterns as the go command lists them.
+func listPackages(patterns []string) ([]string, error) {
+	var stdout bytes.Buffer
+	cmd := exec.Command("go", append([]string{"list", "-e", "-f", "{{.ImportPath}}"}, patterns...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("listPackages: %w", err)
+	}
+	return strings.Fields(stdout.String()), nil
+}
+
+// A report is the data of the HTML report.
+type report struct {
+	Generated time.Time
+	Duration  time.Duration
+	Packages  []*packageReport
+	Issues    int
+	Clean     int // packages without diagnostics
+}
+
+type packageReport struct {
+	Path   string
+	Issues []issue
+	Share  int // percentage of the issues of the report, for the chart
+}
+
+type issue struct {
+	Posn    string
+	Message string
+	Snippet []snippetLine
+}
+
+type snippetLine struct {
+	Number int
+	Text   string
+	Marked bool
+}
+
+// Compliance returns the percentage of the packages without diagnostics.
+func (r *report) Compliance() int {
+	if len(r.Packages) == 0 {
+		return 100
+	}
+	return r.Clean * 100 / len(r.Packages)
+}
+
+// newReport groups the diagnostics of the set by package.
+func newReport(set *diagnosticSet, packages []string) *report {
+	r := &report{Generated: time.Now()}
+	byPath := make(map[string]*packageReport)
+	for _, path := range packages {
+		byPath[path] = &packageReport{Path: path}
+	}
+	for id, results := range set.diags {
+		path := packagePath(id)
+		pkg := byPath[path]
+		if pkg == nil {
+			pkg = &packageReport{Path: path}
+			byPath[path] = pkg
+		}
+		for _, diags := range results {
+			for _, d := range diags {
+				pkg.Issues = append(pkg.Issues, issue{Posn: d.Posn, Message: d.Message, Snippet: snippet(d.Posn)})
+			}
+		}
+	}
+
+	for _, pkg := range byPath {
+		sort.Slice(pkg.Issues, func(i, j int) bool {
+			fi, li, ci := splitPosn(pkg.Issues[i].Posn)
+			fj, lj, cj := splitPosn(pkg.Issues[j].Posn)
+			if fi != fj {
+				return fi < fj
+			}
+			if li != lj {
+				return li < lj
+			}
+			return ci < cj
+		})
+		r.Issues += len(pkg.Issues)
+		if len(pkg.Issues) == 0 {
+			r.Clean++
+		}
+		r.Packages = append(r.Packages, pkg)
+	}
+	for _, pkg := range r.Packages {
+		if r.Issues > 0 {
+			pkg.Share = len(pkg.Issues) * 100 / r.Issues
+		}
+	}
+	// packages with the most issues go first
+	sort.Slice(r.Packages, func(i, j int) bool {
+		if len(r.Packages[i].Issues) != len(r.Packages[j].Issues) {
+			return len(r.Packages[i].Issues) > len(r.Packages[j].Issues)
+		}
+		return r.Packages[i].Path < r.Packages[j].Path
+	})
+	return r
+}
+
+// packagePath returns the import path of a package ID: a test variant like "pkg [pkg.test]" is the package itself.
+func packagePath(id string) string {
+	if i := strings.Index(id, " ["); i >= 0 {
+		return id[:i]
+	}
+	return id
+}
+
+// snippet returns the lines of the source file around a "file:line:col" position or nil if the file can't be read.
+func snippet(posn string) []snippetLine {
+	filename, line, _ := splitPosn(posn)
+	f, err := os.Open(filename)
+	if err != nil || line == 0 {
+		return nil
+	}
+	defer f.Close()
+
+	var lines []snippetLine
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan() && n <= line+snippetLines; n++ {
+		if n >= line-snippetLines {
+			lines = append(lines, snippetLine{Number: n, Text: scanner.Text(), Marked: n == line})
+		}
+	}
+	return lines
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>errchain report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+td, th { padding: 0.2em 0.8em; text-align: left; vertical-align: top; }
+.bar { background: #eee; width: 20em; height: 1em; }
+.bar div { background: #c0392b; height: 100%; }
+.bar.ok div { background: #27ae60; }
+pre { background: #f6f6f6; padding: 0.5em; margin: 0.3em 0 1em; overflow-x: auto; }
+pre .marked { background: #fde2e2; }
+.posn { font-family: monospace; color: #555; }
+</style>
+</head>
+<body>
+<h1>errchain report</h1>
+<p>Generated {{.Generated.Format "2006-01-02 15:04:05"}} in {{.Duration}}:
+{{len .Packages}} packages, {{.Issues}} issues.</p>
+
+<h2>Compliance</h2>
+<table>
+<tr><td>Packages without issues</td><td>{{.Clean}} of {{len .Packages}} ({{.Compliance}}%)</td>
+<td><div class="bar ok"><div style="width: {{.Compliance}}%"></div></div></td></tr>
+</table>
+
+<h2>Issues by package</h2>
+<table>
+<tr><th>Package</th><th>Issues</th><th></th></tr>
+{{- range $i, $pkg := .Packages}}
+<tr><td>{{if .Issues}}<a href="#pkg{{$i}}">{{.Path}}</a>{{else}}{{.Path}}{{end}}</td><td>{{len .Issues}}</td>
+<td><div class="bar"><div style="width: {{.Share}}%"></div></div></td></tr>
+{{- end}}
+</table>
+
+{{- range $i, $pkg := .Packages}}{{if .Issues}}
+<h3 id="pkg{{$i}}">{{.Path}}</h3>
+<table>
+{{- range .Issues}}
+<tr><td class="posn">{{.Posn}}</td><td>{{.Message}}</td></tr>
+{{- if .Snippet}}
+<tr><td colspan="2"><pre>{{range .Snippet}}<span{{if .Marked}} class="marked"{{end}}>{{printf "%4d" .Number}}  {{.Text}}</span>
+{{end}}</pre></td></tr>
+{{- end}}
+{{- end}}
+</table>
+{{- end}}{{end}}
+</body>
+</html>
+`))
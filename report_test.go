@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSet returns a set of the checker output: package ID -> diagnostics of the errchain analyzer.
+func testSet(t *testing.T, diags map[string][]jsonDiagnostic) *diagnosticSet {
+	t.Helper()
+	tree := make(jsonTree)
+	for id, ds := range diags {
+		data, err := json.Marshal(ds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tree[id] = map[string]json.RawMessage{"errchain": data}
+	}
+	set := newDiagnosticSet()
+	set.add("", tree)
+	return set
+}
+
+func TestNewReport(t *testing.T) {
+	set := testSet(t, map[string][]jsonDiagnostic{
+		"example.com/a": {{Posn: "a.go:10:2", Message: "second"}, {Posn: "a.go:9:2", Message: "first"}},
+		// the test variant of a package is reported as the package
+		"example.com/a [example.com/a.test]": {{Posn: "a_test.go:3:2", Message: "test"}},
+		"example.com/b":                      {{Posn: "b.go:1:1", Message: "b"}},
+	})
+	r := newReport(set, []string{"example.com/a", "example.com/b", "example.com/c", "example.com/d"})
+
+	var paths []string
+	for _, pkg := range r.Packages {
+		paths = append(paths, pkg.Path)
+	}
+	if got, want := strings.Join(paths, " "), "example.com/a example.com/b example.com/c example.com/d"; got != want {
+		t.Errorf("packages = %s, want %s", got, want)
+	}
+	if r.Issues != 4 || r.Clean != 2 || r.Compliance() != 50 {
+		t.Errorf("issues, clean, compliance = %d, %d, %d%%, want 4, 2, 50%%", r.Issues, r.Clean, r.Compliance())
+	}
+	a := r.Packages[0]
+	var messages []string
+	for _, issue := range a.Issues {
+		messages = append(messages, issue.Message)
+	}
+	if got, want := strings.Join(messages, " "), "first second test"; got != want {
+		t.Errorf("issues of example.com/a = %s, want %s ordered by position", got, want)
+	}
+	if a.Share != 75 || r.Packages[1].Share != 25 {
+		t.Errorf("shares = %d%%, %d%%, want 75%%, 25%%", a.Share, r.Packages[1].Share)
+	}
+
+	if empty := newReport(newDiagnosticSet(), nil); empty.Compliance() != 100 {
+		t.Errorf("compliance of no packages = %d%%, want 100%%", empty.Compliance())
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	set := testSet(t, map[string][]jsonDiagnostic{
+		"example.com/store": {{Posn: "testdata/driver/store/store.go:9:9", Message: `message <"not found">`}},
+	})
+	var buf bytes.Buffer
+	if got := writeReport(&buf, set, []string{"example.com/store", "example.com/clean"}, time.Second); got != exitIssues {
+		t.Errorf("writeReport() = %d, want %d", got, exitIssues)
+	}
+	html := buf.String()
+	for _, want := range []string{
+		"2 packages, 1 issues.",
+		"1 of 2 (50%)",
+		`<a href="#pkg0">example.com/store</a>`,
+		`<h3 id="pkg0">example.com/store</h3>`,
+		// the messages are escaped
+		"message &lt;&#34;not found&#34;&gt;",
+		// the snippet shows the lines around the position with the line of the diagnostic marked
+		`<span>   8  	}</span>`,
+		`<span class="marked">   9  	return errors.New(&#34;not found&#34;)</span>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report doesn't contain %q:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, `id="pkg1"`) {
+		t.Errorf("report has a section of the clean package:\n%s", html)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// sources parses the source files diagnostics point to, once per file.
+type sources struct {
+	fset  *token.FileSet
+	files map[string]*ast.File // nil for files which can't be parsed
+}
+
+func newSources() *sources {
+	return &sources{fset: token.NewFileSet(), files: make(map[string]*ast.File)}
+}
+
+// context returns the name of the function enclosing a "file:line:col" position, like "Store.Get",
+// and the innermost call with arguments at the position, e.g. an error constructor call, if any.
+func (src *sources) context(posn string) (fn string, call *ast.CallExpr) {
+	filename, line, col := splitPosn(posn)
+	file := src.parse(filename)
+	if file == nil || line == 0 {
+		return "", nil
+	}
+	tf := src.fset.File(file.Pos())
+	if line > tf.LineCount() {
+		return "", nil
+	}
+	pos := tf.LineStart(line) + token.Pos(col-1)
+
+	path, _ := astutil.PathEnclosingInterval(file, pos, pos)
+	for _, node := range path {
+		switch node := node.(type) {
+		case *ast.CallExpr:
+			if call == nil && len(node.Args) > 0 {
+				call = node
+			}
+		case *ast.FuncDecl:
+			fn = node.Name.Name
+			if node.Recv != nil && len(node.Recv.List) > 0 {
+				fn = types.ExprString(node.Recv.List[0].Type) + "." + fn
+			}
+		}
+	}
+	return fn, call
+}
+
+func (src *sources) parse(filename string) *ast.File {
+	file, ok := src.files[filename]
+	if !ok {
+		file, _ = parser.ParseFile(src.fset, filename, nil, parser.SkipObjectResolution)
+		src.files[filename] = file
+	}
+	return file
+}
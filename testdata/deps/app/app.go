@@ -0,0 +1,8 @@
+package app
+
+import (
+	"example.com/lib/cache"
+	"example.com/lib/store"
+)
+
+var _, _ = cache.Get, store.Get
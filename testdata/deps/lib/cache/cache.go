@@ -0,0 +1,7 @@
+package cache
+
+import "errors"
+
+func Get(key string) error {
+	return errors.New("cache.Get: not found")
+}
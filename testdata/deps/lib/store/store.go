@@ -0,0 +1,10 @@
+package store
+
+import "errors"
+
+func Get(key string) error {
+	if key == "" {
+		return errors.New("store.Get: empty key")
+	}
+	return errors.New("not found")
+}
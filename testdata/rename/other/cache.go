@@ -0,0 +1,6 @@
+package cache
+
+import "errors"
+
+// errMiss repeats the prefix of the store package, which isn't renamed here.
+var errMiss = errors.New("store.Store.Load: cache miss")
@@ -0,0 +1,10 @@
+package store
+
+import "fmt"
+
+func Load(key string) error {
+	if key == "" {
+		return fmt.Errorf("store.Load - empty key")
+	}
+	return fmt.Errorf("store.Load: %s not found", key)
+}
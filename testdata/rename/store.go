@@ -0,0 +1,30 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"log"
+)
+
+type Store struct{}
+
+func (s *Store) Load(key string) error {
+	const op = "store.Store.Load"
+	log.Printf("store.Store.Load: loading %s", key)
+	if key == "" {
+		return errors.New("store.Store.Load: empty key")
+	}
+	if key == "nested" {
+		return errors.New("store.Store.Load.inner: " + key)
+	}
+	if key == "ptr" {
+		return fmt.Errorf("store.(*Store).Load: bad key %q", key)
+	}
+	return fmt.Errorf("%s: not found", op)
+}
+
+const loader = "store.Store.Loader"
+
+var hits = map[string]int{"store.Store.Load: hits": 0}
+
+var errClosed = errors.New("store.Store.Loader: closed")
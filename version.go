@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/iimos/go-check-err-chains/errchain"
+)
+
+// versionString describes the build: the module version and the VCS revision recorded by the go command,
+// e.g. "errchain v1.3.0 (commit 1a2b3c4d5e6f, go1.21.5)". No build flags are needed to embed them.
+func versionString() string {
+	return buildVersion(debug.ReadBuildInfo())
+}
+
+// buildVersion describes a build by its info as versionString does; ok is false if there is no info.
+func buildVersion(info *debug.BuildInfo, ok bool) string {
+	version, commit, dirty := "(devel)", "", false
+	if !ok {
+		return "errchain " + version
+	}
+	if v := info.Main.Version; v != "" {
+		version = v
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			commit = setting.Value
+			if len(commit) > 12 {
+				commit = commit[:12]
+			}
+		case "vcs.modified":
+			// recent go commands mark the pseudo-version itself, e.g. v0.0.0-20240101-1a2b3c4d5e6f+dirty
+			dirty = setting.Value == "true" && !strings.HasSuffix(version, "+dirty")
+		}
+	}
+
+	s := "errchain " + version + " ("
+	if commit != "" {
+		s += "commit " + commit
+		if dirty {
+			s += "-dirty"
+		}
+		s += ", "
+	}
+	return s + info.GoVersion + ")"
+}
+
+// listRules prints the rules of the analyzer with their states under the flags given in the arguments.
+func listRules(w io.Writer, args []string) int {
+	flags := flag.NewFlagSet("errchain", flag.ContinueOnError)
+	flags.SetOutput(os.Stderr)
+	errchain.Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		flags.Var(f.Value, f.Name, f.Usage)
+	})
+	if exitcode, ok := parseFlags(flags, args); !ok {
+		return exitcode
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, r := range errchain.Rules() {
+		state := "off"
+		if r.Enabled {
+			state = "on"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Code, state, r.Doc)
+	}
+	if err := tw.Flush(); err != nil {
+		return exitError
+	}
+	return exitOK
+}
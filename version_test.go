@@ -0,0 +1,81 @@
+package main
+
+import (
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/iimos/go-check-err-chains/errchain"
+)
+
+func TestBuildVersion(t *testing.T) {
+	revision := debug.BuildSetting{Key: "vcs.revision", Value: "1a2b3c4d5e6f7a8b9c0d"}
+	modified := debug.BuildSetting{Key: "vcs.modified", Value: "true"}
+	for _, tt := range []struct {
+		info *debug.BuildInfo
+		ok   bool
+		want string
+	}{
+		// no build info, e.g. a binary built without module support
+		{nil, false, "errchain (devel)"},
+		{&debug.BuildInfo{GoVersion: "go1.21.5"}, true, "errchain (devel) (go1.21.5)"},
+		{&debug.BuildInfo{GoVersion: "go1.21.5", Main: debug.Module{Version: "v1.3.0"}}, true,
+			"errchain v1.3.0 (go1.21.5)"},
+		{&debug.BuildInfo{GoVersion: "go1.21.5", Main: debug.Module{Version: "v1.3.0"},
+			Settings: []debug.BuildSetting{revision}}, true, "errchain v1.3.0 (commit 1a2b3c4d5e6f, go1.21.5)"},
+		{&debug.BuildInfo{GoVersion: "go1.21.5", Settings: []debug.BuildSetting{revision, modified}}, true,
+			"errchain (devel) (commit 1a2b3c4d5e6f-dirty, go1.21.5)"},
+		// the pseudo-version is marked already
+		{&debug.BuildInfo{GoVersion: "go1.22.0", Main: debug.Module{Version: "v0.0.0-20240101-1a2b3c4d5e6f+dirty"},
+			Settings: []debug.BuildSetting{revision, modified}}, true,
+			"errchain v0.0.0-20240101-1a2b3c4d5e6f+dirty (commit 1a2b3c4d5e6f, go1.22.0)"},
+	} {
+		if got := buildVersion(tt.info, tt.ok); got != tt.want {
+			t.Errorf("buildVersion(%+v, %v) = %q, want %q", tt.info, tt.ok, got, tt.want)
+		}
+	}
+}
+
+func TestListRules(t *testing.T) {
+	rules := errchain.Rules()
+	want := make(map[string]string)
+	for _, r := range rules {
+		want[r.Code] = "off"
+		if r.Enabled {
+			want[r.Code] = "on"
+		}
+	}
+	checkListRules(t, want, "-list-rules")
+
+	// a rule off by default and one on by default
+	want["long-prefix"], want["prefix"] = "on", "off"
+	checkListRules(t, want, "-list-rules", "-enable=long-prefix", "-disable=prefix")
+	if out, exitcode := runMain(t, "-list-rules", "-enable=no-such-rule"); exitcode != exitConfig {
+		t.Errorf("-list-rules with an unknown rule: exit code = %d, want %d, output:\n%s", exitcode, exitConfig, out)
+	}
+}
+
+// checkListRules checks that the command with the arguments lists every rule in the state it's given by want.
+func checkListRules(t *testing.T, want map[string]string, args ...string) {
+	t.Helper()
+	out, exitcode := runMain(t, args...)
+	if exitcode != exitOK {
+		t.Fatalf("errchain %q: exit code = %d, want %d, output:\n%s", args, exitcode, exitOK, out)
+	}
+	got := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		if fields := strings.Fields(line); len(fields) > 2 {
+			got[fields[0]] = fields[1]
+		} else {
+			t.Errorf("errchain %q: unexpected line %q", args, line)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("errchain %q: got %d rules, want %d:\n%s", args, len(got), len(want), out)
+	}
+	for code, state := range want {
+		if got[code] != state {
+			t.Errorf("errchain %q: rule %s is %q, want %q", args, code, got[code], state)
+		}
+	}
+}